@@ -34,6 +34,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&CheckNodeHealth{},
 		&CheckNodeHealthList{},
+		&ClusterHealthStatus{},
+		&ClusterHealthStatusList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
@@ -114,3 +114,83 @@ type CheckNodeHealthList struct {
 	metav1.ListMeta `json:"metadata,omitempty"`
 	Items           []CheckNodeHealth `json:"items"`
 }
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,shortName=chs
+// +kubebuilder:printcolumn:name="Healthy",type=string,JSONPath=`.status.conditions[?(@.type=="Healthy")].status`
+// +kubebuilder:printcolumn:name="Reason",type=string,JSONPath=`.status.conditions[?(@.type=="Healthy")].reason`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
+
+// ClusterHealthStatus summarizes the results of the continuous checkers run by cluster-health-monitor.
+// The resource is created and kept up to date by cluster-health-monitor and has no spec.
+type ClusterHealthStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status ClusterHealthStatusStatus `json:"status,omitempty"`
+}
+
+// ClusterHealthStatusStatus defines the observed state of ClusterHealthStatus
+type ClusterHealthStatusStatus struct {
+	// Conditions represent the latest available observations of the cluster's health.
+	// The Healthy condition aggregates the status of all checkers.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Results contains the latest result of each checker, sorted by name
+	// +optional
+	Results []CheckerResult `json:"results,omitempty"`
+}
+
+// CheckerResult represents the latest result of a continuous checker
+type CheckerResult struct {
+	// Name is the name of the checker in the cluster-health-monitor configuration
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Name string `json:"name"`
+
+	// Type is the type of the checker, e.g. "DNS" or "PodStartup"
+	// +required
+	// +kubebuilder:validation:MaxLength=253
+	Type string `json:"type"`
+
+	// Status is the effective status of the checker after applying its failure and success thresholds
+	// +required
+	// +kubebuilder:validation:Enum=Healthy;Unhealthy;Unknown
+	Status CheckStatus `json:"status"`
+
+	// Message provides additional details about the most recent run of the checker
+	// +optional
+	// +kubebuilder:validation:MaxLength=32768
+	Message string `json:"message,omitempty"`
+
+	// ErrorCode is the specific error code of the most recent run if it was not Healthy
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[A-Z][a-zA-Z0-9]*$`
+	// +kubebuilder:validation:MaxLength=253
+	ErrorCode string `json:"errorCode,omitempty"`
+
+	// LastRunTime is the timestamp when the most recent run of the checker started
+	// +optional
+	LastRunTime *metav1.Time `json:"lastRunTime,omitempty"`
+}
+
+// ClusterHealthConditionType represents the type of condition
+type ClusterHealthConditionType string
+
+const (
+	// ClusterHealthConditionHealthy is the condition type used to report the overall health status of the cluster
+	// The condition's Status field will be True/False/Unknown to indicate the actual health state
+	ClusterHealthConditionHealthy ClusterHealthConditionType = "Healthy"
+)
+
+// +kubebuilder:object:root=true
+
+// ClusterHealthStatusList contains a list of ClusterHealthStatus resources
+type ClusterHealthStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterHealthStatus `json:"items"`
+}
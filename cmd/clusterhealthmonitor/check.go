@@ -0,0 +1,260 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"sync"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Azure/cluster-health-monitor/pkg/checker"
+	"github.com/Azure/cluster-health-monitor/pkg/config"
+	"github.com/Azure/cluster-health-monitor/pkg/metrics"
+	"github.com/Azure/cluster-health-monitor/pkg/status"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// checkCommand is the name of the subcommand that runs the checkers once and prints their results.
+	checkCommand = "check"
+
+	// Exit codes of the check subcommand.
+	checkExitHealthy   = 0
+	checkExitUnhealthy = 1
+	checkExitError     = 2
+
+	// checkHungGracePeriod is how long the check subcommand waits for a checker to return after its timeout before reporting it as hung.
+	checkHungGracePeriod = 10 * time.Second
+
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// checkResult is a result printed by the check subcommand.
+type checkResult struct {
+	// Name is the name of the checker.
+	Name string `json:"name"`
+	// Type is the type of the checker.
+	Type string `json:"type"`
+	status.Result
+}
+
+// runCheck implements the check subcommand. It runs each selected checker of the configuration once against the cluster of a kubeconfig,
+// prints the results and returns the exit code: 0 if all results are healthy or skipped, 1 if any result is unhealthy or unknown, and 2
+// if the checkers could not be run at all.
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet(checkCommand, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s [flags]\n\nRuns the configured checkers once and prints their results.\n\nFlags:\n", os.Args[0], checkCommand)
+		fs.PrintDefaults()
+	}
+	kubeconfig := fs.String("kubeconfig", "", "Path to the kubeconfig file. Defaults to $KUBECONFIG or ~/.kube/config")
+	configPath := fs.String("config", defaultConfigPath, "Path to the configuration file")
+	only := fs.String("only", "", "Comma-separated names of the checkers to run. Defaults to all checkers")
+	output := fs.String("output", outputTable, "Output format, one of: table, json")
+	// The logging flags, e.g. -v, are accepted by the subcommand as well.
+	klog.InitFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return checkExitHealthy
+		}
+		return checkExitError
+	}
+	if *output != outputTable && *output != outputJSON {
+		fmt.Fprintf(os.Stderr, "unsupported output format %q\n", *output)
+		return checkExitError
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	results, err := check(ctx, *kubeconfig, *configPath, *only)
+	if err != nil {
+		klog.ErrorS(err, "Failed to run checkers")
+		return checkExitError
+	}
+	if err := writeCheckResults(os.Stdout, results, *output); err != nil {
+		klog.ErrorS(err, "Failed to write results")
+		return checkExitError
+	}
+	return checkExitCode(results)
+}
+
+// check builds the selected checkers and runs them once in parallel. It returns their results in the order of the configuration.
+func check(ctx context.Context, kubeconfig, configPath, only string) ([]checkResult, error) {
+	cfg, err := config.ParseFromFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	chkCfgs, err := selectCheckers(cfg, only)
+	if err != nil {
+		return nil, err
+	}
+
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	k8sConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &clientcmd.ConfigOverrides{}).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	kubeClient, err := kubernetes.NewForConfig(k8sConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kubernetes client: %w", err)
+	}
+
+	registerCheckers()
+	collector := &resultCollector{results: make(map[string][]status.Result)}
+	checker.OnResult(collector.record)
+	results := make([][]checkResult, len(chkCfgs))
+	var wg sync.WaitGroup
+	for i, chkCfg := range chkCfgs {
+		chk, err := checker.Build(&chkCfg, kubeClient, k8sConfig)
+		if errors.Is(err, checker.ErrSkipChecker) {
+			results[i] = []checkResult{{
+				Name:   chkCfg.Name,
+				Type:   string(chkCfg.Type),
+				Result: status.Result{Status: string(checker.StatusSkipped), Message: err.Error(), Time: time.Now()},
+			}}
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to build checker %q: %w", chkCfg.Name, err)
+		}
+		wg.Go(func() {
+			results[i] = runOnce(ctx, chk, chkCfg.Timeout, collector)
+		})
+	}
+	wg.Wait()
+	return slices.Concat(results...), nil
+}
+
+// selectCheckers returns the checker configs named in only, a comma-separated list of names, or all checker configs if only is empty.
+func selectCheckers(cfg *config.Config, only string) ([]config.CheckerConfig, error) {
+	if only == "" {
+		return cfg.Checkers, nil
+	}
+	var selected []config.CheckerConfig
+	for name := range strings.SplitSeq(only, ",") {
+		name = strings.TrimSpace(name)
+		idx := slices.IndexFunc(cfg.Checkers, func(c config.CheckerConfig) bool { return c.Name == name })
+		if idx < 0 {
+			return nil, fmt.Errorf("checker %q not found in config", name)
+		}
+		selected = append(selected, cfg.Checkers[idx])
+	}
+	return selected, nil
+}
+
+// resultCollector collects all results recorded by the checkers, keyed by checker name.
+type resultCollector struct {
+	mu      sync.Mutex
+	results map[string][]status.Result
+}
+
+// record is registered with checker.OnResult.
+func (c *resultCollector) record(checkerName, _ string, result status.Result) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.results[checkerName] = append(c.results[checkerName], result)
+}
+
+// get returns the results recorded by the checker with the given name.
+func (c *resultCollector) get(checkerName string) []status.Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.results[checkerName])
+}
+
+// runOnce runs the checker once and returns the results it recorded, as collected by collector. A checker that does not return within its
+// timeout and a grace period is reported as unknown.
+func runOnce(ctx context.Context, chk checker.Checker, timeout time.Duration, collector *resultCollector) []checkResult {
+	runCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		chk.Run(runCtx)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(timeout + checkHungGracePeriod):
+		return []checkResult{{
+			Name: chk.Name(),
+			Type: string(chk.Type()),
+			Result: status.Result{
+				Status:  metrics.UnknownStatus,
+				Code:    metrics.UnknownCode,
+				Message: fmt.Sprintf("checker did not return within %s", timeout+checkHungGracePeriod),
+				Time:    time.Now(),
+			},
+		}}
+	}
+
+	// The checkers record their results rather than return them. The status store only keeps the most recent results of each checker, so
+	// the results are collected as they are recorded instead.
+	var results []checkResult
+	for _, result := range collector.get(chk.Name()) {
+		results = append(results, checkResult{Name: chk.Name(), Type: string(chk.Type()), Result: result})
+	}
+	if len(results) == 0 {
+		results = append(results, checkResult{
+			Name:   chk.Name(),
+			Type:   string(chk.Type()),
+			Result: status.Result{Status: metrics.UnknownStatus, Code: metrics.UnknownCode, Message: "checker recorded no result", Time: time.Now()},
+		})
+	}
+	return results
+}
+
+// writeCheckResults writes the results to w in the given output format.
+func writeCheckResults(w io.Writer, results []checkResult, output string) error {
+	if output == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tTARGET\tSTATUS\tCODE\tMESSAGE")
+	for _, r := range results {
+		// The target is the pod, nameserver or placement group of per-pod, per-nameserver and per-placement-group results.
+		target := r.Pod
+		if target == "" {
+			target = r.Nameserver
+		}
+		if target == "" {
+			target = r.Group
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Name, r.Type, orDash(target), r.Status, orDash(r.Code), orDash(r.Message))
+	}
+	return tw.Flush()
+}
+
+// checkExitCode returns the exit code of the check subcommand for the results.
+func checkExitCode(results []checkResult) int {
+	for _, r := range results {
+		if r.Status == metrics.UnhealthyStatus || r.Status == metrics.UnknownStatus {
+			return checkExitUnhealthy
+		}
+	}
+	return checkExitHealthy
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Azure/cluster-health-monitor/pkg/checker"
+	"github.com/Azure/cluster-health-monitor/pkg/config"
+	"github.com/Azure/cluster-health-monitor/pkg/status"
+	. "github.com/onsi/gomega"
+)
+
+// fakeChecker records a result for each of its nameservers and an overall result, which is unhealthy if any nameserver failed.
+type fakeChecker struct {
+	name        string
+	nameservers []string
+	// failed are the nameservers with an unhealthy result.
+	failed map[string]bool
+	// err is recorded as the overall result if set.
+	err error
+}
+
+func (f *fakeChecker) Name() string             { return f.name }
+func (f *fakeChecker) Type() config.CheckerType { return config.CheckTypeDNS }
+func (f *fakeChecker) Run(ctx context.Context) {
+	overall := checker.Healthy()
+	for _, nameserver := range f.nameservers {
+		if f.failed[nameserver] {
+			checker.RecordNameserverResult(f, nameserver, checker.Unhealthy("NameserverTimeout", "query timed out"), nil)
+			overall = checker.Unhealthy("NameserverTimeout", "nameservers failed")
+			continue
+		}
+		checker.RecordNameserverResult(f, nameserver, checker.Healthy(), nil)
+	}
+	if f.err != nil {
+		checker.RecordResult(f, nil, f.err)
+		return
+	}
+	checker.RecordResult(f, overall, nil)
+}
+
+func TestRunOnce(t *testing.T) {
+	collector := &resultCollector{results: make(map[string][]status.Result)}
+	checker.OnResult(collector.record)
+
+	manyNameservers := make([]string, 15)
+	for i := range manyNameservers {
+		manyNameservers[i] = fmt.Sprintf("10.0.0.%d:53", i+1)
+	}
+
+	testCases := []struct {
+		name             string
+		chk              *fakeChecker
+		expectedResults  int
+		expectedExitCode int
+	}{
+		{
+			name:             "healthy",
+			chk:              &fakeChecker{name: "check-healthy", nameservers: []string{"10.0.0.1:53", "10.0.0.2:53"}},
+			expectedResults:  3,
+			expectedExitCode: checkExitHealthy,
+		},
+		{
+			name: "unhealthy nameserver",
+			chk: &fakeChecker{
+				name:        "check-unhealthy",
+				nameservers: []string{"10.0.0.1:53", "10.0.0.2:53"},
+				failed:      map[string]bool{"10.0.0.2:53": true},
+			},
+			expectedResults:  3,
+			expectedExitCode: checkExitUnhealthy,
+		},
+		{
+			name:             "run error",
+			chk:              &fakeChecker{name: "check-error", err: errors.New("failed to list endpoints")},
+			expectedResults:  1,
+			expectedExitCode: checkExitUnhealthy,
+		},
+		{
+			name:             "more results than the status store keeps",
+			chk:              &fakeChecker{name: "check-many", nameservers: manyNameservers},
+			expectedResults:  16,
+			expectedExitCode: checkExitHealthy,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			results := runOnce(context.Background(), tc.chk, time.Second, collector)
+			g.Expect(results).To(HaveLen(tc.expectedResults))
+			for i, nameserver := range tc.chk.nameservers {
+				g.Expect(results[i].Name).To(Equal(tc.chk.name))
+				g.Expect(results[i].Nameserver).To(Equal(nameserver))
+			}
+			g.Expect(results[len(results)-1].Nameserver).To(BeEmpty())
+			g.Expect(checkExitCode(results)).To(Equal(tc.expectedExitCode))
+		})
+	}
+}
+
+func TestRunCheck_Error(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "unsupported output format",
+			args: []string{"--output", "yaml"},
+		},
+		{
+			name: "missing config file",
+			args: []string{"--config", "/nonexistent/config.yaml"},
+		},
+		{
+			name: "unknown flag",
+			args: []string{"--unknown"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(runCheck(tc.args)).To(Equal(checkExitError))
+		})
+	}
+}
+
+func TestRunCheck_LoggingFlags(t *testing.T) {
+	g := NewWithT(t)
+	// The help flag stops the subcommand before it runs any checker, once the logging flags before it have been parsed.
+	g.Expect(runCheck([]string{"-v=0", "--logtostderr=true", "--help"})).To(Equal(checkExitHealthy))
+}
@@ -5,25 +5,40 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"os"
 	"os/signal"
+	"sync"
 	"syscall"
+	"time"
 
+	chmv1alpha1 "github.com/Azure/cluster-health-monitor/apis/chm/v1alpha1"
 	"github.com/Azure/cluster-health-monitor/pkg/checker"
 	"github.com/Azure/cluster-health-monitor/pkg/checker/apiserver"
 	"github.com/Azure/cluster-health-monitor/pkg/checker/azurepolicy"
 	"github.com/Azure/cluster-health-monitor/pkg/checker/dnscheck"
 	"github.com/Azure/cluster-health-monitor/pkg/checker/metricsserver"
+	"github.com/Azure/cluster-health-monitor/pkg/checker/nodeprovisioning"
 	"github.com/Azure/cluster-health-monitor/pkg/checker/podstartup"
 	"github.com/Azure/cluster-health-monitor/pkg/config"
+	"github.com/Azure/cluster-health-monitor/pkg/events"
 	"github.com/Azure/cluster-health-monitor/pkg/metrics"
 	"github.com/Azure/cluster-health-monitor/pkg/scheduler"
+	"github.com/Azure/cluster-health-monitor/pkg/status"
+	"github.com/robfig/cron/v3"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	defaultConfigPath = "/etc/cluster-health-monitor/config.yaml"
+	defaultConfigPath           = "/etc/cluster-health-monitor/config.yaml"
+	defaultConfigReloadInterval = 10 * time.Second
+	defaultEventNamespace       = "kube-system"
+	defaultEventDeployment      = "cluster-health-monitor"
+	defaultClusterHealthStatus  = "cluster"
+	defaultStatusPublishPeriod  = 30 * time.Second
 )
 
 func init() {
@@ -37,7 +52,22 @@ func logErrorAndExit(err error, message string) {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == checkCommand {
+		code := runCheck(os.Args[2:])
+		klog.Flush()
+		os.Exit(code)
+	}
+
 	configPath := flag.String("config", defaultConfigPath, "Path to the configuration file")
+	configReloadInterval := flag.Duration("config-reload-interval", defaultConfigReloadInterval,
+		"How often to check the configuration file for changes. Set to 0 to disable configuration reloading")
+	eventNamespace := flag.String("event-namespace", defaultEventNamespace, "Namespace of the Deployment that checker status events are attached to")
+	eventDeployment := flag.String("event-deployment", defaultEventDeployment,
+		"Name of the Deployment that checker status events are attached to. Set to empty to disable events")
+	clusterHealthStatus := flag.String("cluster-health-status", defaultClusterHealthStatus,
+		"Name of the ClusterHealthStatus resource that checker results are published to. Set to empty to disable publishing")
+	statusPublishPeriod := flag.Duration("cluster-health-status-interval", defaultStatusPublishPeriod,
+		"How often to publish checker results to the ClusterHealthStatus resource")
 	flag.Parse()
 	defer klog.Flush()
 
@@ -60,7 +90,8 @@ func main() {
 	}()
 
 	// Parse the configuration file.
-	cfg, err := config.ParseFromFile(*configPath)
+	cfgWatcher := config.NewWatcher(*configPath, *configReloadInterval)
+	cfg, err := cfgWatcher.Load()
 	if err != nil {
 		logErrorAndExit(err, "Failed to parse config")
 	}
@@ -78,8 +109,31 @@ func main() {
 		logErrorAndExit(err, "Failed to create Kubernetes client")
 	}
 
+	// Publish checker status transitions as Kubernetes events.
+	if *eventDeployment != "" {
+		recorder, err := events.StartDeploymentRecorder(ctx, kubeClient, *eventNamespace, *eventDeployment)
+		if err != nil {
+			// Events are best effort, so failing to set them up does not stop the checkers from running.
+			klog.ErrorS(err, "Failed to start event recorder, checker status events are disabled")
+		} else {
+			checker.OnTransition(recorder.RecordTransition)
+			klog.InfoS("Started event recorder", "namespace", *eventNamespace, "deployment", *eventDeployment)
+		}
+	}
+
+	// Publish checker results to the ClusterHealthStatus resource.
+	if *clusterHealthStatus != "" {
+		chmClient, err := newCHMClient(k8sConfig)
+		if err != nil {
+			logErrorAndExit(err, "Failed to create client for cluster health monitor resources")
+		}
+		publisher := status.NewPublisher(chmClient, status.DefaultStore, *clusterHealthStatus, *statusPublishPeriod)
+		go publisher.Run(ctx)
+		klog.InfoS("Started cluster health status publisher", "name", *clusterHealthStatus, "interval", *statusPublishPeriod)
+	}
+
 	// Build the checker schedule from the configuration.
-	cs, err := buildCheckerSchedule(cfg, kubeClient)
+	cs, err := buildCheckerSchedule(cfg, kubeClient, k8sConfig)
 	if err != nil {
 		logErrorAndExit(err, "Failed to build checker schedule")
 	}
@@ -94,28 +148,151 @@ func main() {
 	}()
 	klog.InfoS("Scheduler started")
 
+	// Watch the configuration file and apply changes to the running scheduler.
+	if *configReloadInterval > 0 {
+		go func() {
+			err := cfgWatcher.Watch(ctx, func(newCfg *config.Config, err error) error {
+				if err != nil {
+					metrics.ConfigReloadCounter.WithLabelValues(metrics.ConfigReloadFailure).Inc()
+					klog.ErrorS(err, "Rejected configuration change, keeping last good configuration", "path", *configPath)
+					return nil
+				}
+				// cfg tracks the checkers that are actually running, so that a reload that was only partially applied is completed
+				// when the watcher reports the same configuration again.
+				running, err := reloadConfig(sched, cfg, newCfg, kubeClient, k8sConfig)
+				cfg = running
+				if err != nil {
+					metrics.ConfigReloadCounter.WithLabelValues(metrics.ConfigReloadFailure).Inc()
+					klog.ErrorS(err, "Failed to apply configuration change, retrying", "path", *configPath)
+					return err
+				}
+				metrics.ConfigReloadCounter.WithLabelValues(metrics.ConfigReloadSuccess).Inc()
+				klog.InfoS("Reloaded configuration file", "path", *configPath, "numCheckers", len(cfg.Checkers))
+				return nil
+			})
+			if err != nil && !errors.Is(err, context.Canceled) {
+				klog.ErrorS(err, "Stopped watching configuration file", "path", *configPath)
+			}
+		}()
+	}
+
 	<-ctx.Done()
 	klog.InfoS("Stopped Cluster Health Monitor due to context cancel")
 }
 
-func buildCheckerSchedule(cfg *config.Config, kubeClient kubernetes.Interface) ([]scheduler.CheckerSchedule, error) {
+func buildCheckerSchedule(cfg *config.Config, kubeClient kubernetes.Interface, k8sConfig *rest.Config) ([]scheduler.CheckerSchedule, error) {
 	var schedules []scheduler.CheckerSchedule
 	for _, chkCfg := range cfg.Checkers {
-		chk, err := checker.Build(&chkCfg, kubeClient)
-		if errors.Is(err, checker.ErrSkipChecker) {
-			klog.ErrorS(err, "Skipped checker", "name", chkCfg.Name)
-			continue
+		chkSch, err := buildSchedule(chkCfg, kubeClient, k8sConfig)
+		if err != nil {
+			return nil, err
+		}
+		if chkSch != nil {
+			schedules = append(schedules, *chkSch)
 		}
+	}
+	return schedules, nil
+}
+
+// buildSchedule builds the checker schedule for a single checker config. It returns nil if the checker is skipped.
+func buildSchedule(chkCfg config.CheckerConfig, kubeClient kubernetes.Interface, k8sConfig *rest.Config) (*scheduler.CheckerSchedule, error) {
+	chk, err := checker.Build(&chkCfg, kubeClient, k8sConfig)
+	if errors.Is(err, checker.ErrSkipChecker) {
+		klog.ErrorS(err, "Skipped checker", "name", chkCfg.Name)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to build checker %q: %w", chkCfg.Name, err)
+	}
+	chkSch := &scheduler.CheckerSchedule{
+		Interval:         chkCfg.Interval,
+		InitialDelay:     chkCfg.InitialDelay,
+		JitterPercent:    chkCfg.JitterPercent,
+		Timeout:          chkCfg.Timeout,
+		FailureThreshold: chkCfg.FailureThreshold,
+		SuccessThreshold: chkCfg.SuccessThreshold,
+		Checker:          chk,
+	}
+	if chkCfg.Schedule != "" {
+		// The schedule has already been validated when the configuration was parsed.
+		chkSch.CronSchedule, err = cron.ParseStandard(chkCfg.Schedule)
 		if err != nil {
-			return nil, fmt.Errorf("failed to build checker %q: %w", chkCfg.Name, err)
+			return nil, fmt.Errorf("failed to parse schedule of checker %q: %w", chkCfg.Name, err)
 		}
-		schedules = append(schedules, scheduler.CheckerSchedule{
-			Interval: chkCfg.Interval,
-			Timeout:  chkCfg.Timeout,
-			Checker:  chk,
+	}
+	return chkSch, nil
+}
+
+// reloadConfig applies a changed configuration to the running scheduler. Checkers are matched by name: removed checkers are stopped,
+// added checkers are started and changed checkers are rebuilt, while unchanged checkers keep running undisturbed. All new checkers are
+// built before the scheduler is touched, so a configuration containing a checker that cannot be built is rejected as a whole. It returns
+// the configuration of the checkers that are scheduled afterwards, which leaves out the checkers that could not be started. It blocks
+// until the in-flight runs of the removed and changed checkers have returned, which can take up to their timeouts, so the checkers are
+// removed concurrently.
+func reloadConfig(sched *scheduler.Scheduler, oldCfg, newCfg *config.Config, kubeClient kubernetes.Interface, k8sConfig *rest.Config) (*config.Config, error) {
+	diff := config.DiffCheckers(oldCfg, newCfg)
+	if diff.IsEmpty() {
+		return newCfg, nil
+	}
+
+	toStart := append(append([]config.CheckerConfig{}, diff.Added...), diff.Changed...)
+	built := make(map[string]*scheduler.CheckerSchedule)
+	for _, chkCfg := range toStart {
+		chkSch, err := buildSchedule(chkCfg, kubeClient, k8sConfig)
+		if err != nil {
+			return oldCfg, err
+		}
+		built[chkCfg.Name] = chkSch
+	}
+
+	var wg sync.WaitGroup
+	for _, chkCfg := range diff.Removed {
+		wg.Go(func() {
+			sched.RemoveChecker(chkCfg.Name)
+			klog.InfoS("Removed checker", "name", chkCfg.Name)
 		})
 	}
-	return schedules, nil
+	for _, chkCfg := range diff.Changed {
+		wg.Go(func() {
+			sched.RemoveChecker(chkCfg.Name)
+		})
+	}
+	wg.Wait()
+	var errs []error
+	notStarted := make(map[string]bool)
+	for _, chkCfg := range toStart {
+		chkSch := built[chkCfg.Name]
+		if chkSch == nil {
+			continue
+		}
+		if err := sched.AddChecker(*chkSch); err != nil {
+			errs = append(errs, err)
+			notStarted[chkCfg.Name] = true
+			continue
+		}
+		klog.InfoS("Scheduled checker", "name", chkCfg.Name, "type", chkCfg.Type)
+	}
+	if len(notStarted) == 0 {
+		return newCfg, nil
+	}
+
+	running := *newCfg
+	running.Checkers = nil
+	for _, chkCfg := range newCfg.Checkers {
+		if !notStarted[chkCfg.Name] {
+			running.Checkers = append(running.Checkers, chkCfg)
+		}
+	}
+	return &running, errors.Join(errs...)
+}
+
+// newCHMClient creates a controller-runtime client for the cluster health monitor custom resources.
+func newCHMClient(k8sConfig *rest.Config) (runtimeclient.Client, error) {
+	scheme := runtime.NewScheme()
+	if err := chmv1alpha1.AddToScheme(scheme); err != nil {
+		return nil, err
+	}
+	return runtimeclient.New(k8sConfig, runtimeclient.Options{Scheme: scheme})
 }
 
 func registerCheckers() {
@@ -124,4 +301,5 @@ func registerCheckers() {
 	apiserver.Register()
 	metricsserver.Register()
 	azurepolicy.Register()
+	nodeprovisioning.Register()
 }
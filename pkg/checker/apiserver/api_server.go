@@ -13,12 +13,20 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 
 	"github.com/Azure/cluster-health-monitor/pkg/checker"
 	"github.com/Azure/cluster-health-monitor/pkg/config"
 )
 
+// Phases of an APIServerChecker run recorded in the phase duration histogram.
+const (
+	phaseCreate = "create"
+	phaseGet    = "get"
+	phaseDelete = "delete"
+)
+
 // APIServerChecker implements the Checker interface for API server checks.
 type APIServerChecker struct {
 	name       string
@@ -32,7 +40,7 @@ func Register() {
 }
 
 // buildAPIServerChecker creates a new APIServerChecker instance.
-func buildAPIServerChecker(config *config.CheckerConfig, kubeClient kubernetes.Interface) (checker.Checker, error) {
+func buildAPIServerChecker(config *config.CheckerConfig, kubeClient kubernetes.Interface, restConfig *rest.Config) (checker.Checker, error) {
 	chk := &APIServerChecker{
 		name:       config.Name,
 		config:     config.APIServerConfig,
@@ -56,8 +64,10 @@ func (c APIServerChecker) Type() config.CheckerType {
 }
 
 func (c APIServerChecker) Run(ctx context.Context) {
+	start := time.Now()
 	result, err := c.check(ctx)
 	checker.RecordResult(c, result, err)
+	checker.RecordDuration(c, result, err, time.Since(start))
 }
 
 // Executes the api server check. It creates an empty ConfigMap, gets it, and then deletes it. If all operations succeed, the check is
@@ -87,7 +97,9 @@ func (c APIServerChecker) check(ctx context.Context) (*checker.Result, error) {
 	// Intentionally not dry-run so create persists to backing storage and validates the API server storage path.
 	createCtx, createCancel := context.WithTimeout(ctx, c.config.MutateTimeout)
 	defer createCancel()
+	createStart := time.Now()
 	createdConfigMap, err := c.kubeClient.CoreV1().ConfigMaps(c.config.Namespace).Create(createCtx, c.generateConfigMap(), metav1.CreateOptions{})
+	checker.RecordPhaseDuration(c, phaseCreate, time.Since(createStart))
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			return checker.Unhealthy(ErrCodeAPIServerCreateTimeout, "timed out while creating ConfigMap"), nil
@@ -106,7 +118,9 @@ func (c APIServerChecker) check(ctx context.Context) (*checker.Result, error) {
 	// Get ConfigMap.
 	getCtx, getCancel := context.WithTimeout(ctx, c.config.ReadTimeout)
 	defer getCancel()
+	getStart := time.Now()
 	_, err = c.kubeClient.CoreV1().ConfigMaps(c.config.Namespace).Get(getCtx, createdConfigMap.Name, metav1.GetOptions{})
+	checker.RecordPhaseDuration(c, phaseGet, time.Since(getStart))
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			return checker.Unhealthy(ErrCodeAPIServerGetTimeout, "timed out while getting ConfigMap"), nil
@@ -117,7 +131,9 @@ func (c APIServerChecker) check(ctx context.Context) (*checker.Result, error) {
 	// Delete ConfigMap.
 	deleteCtx, deleteCancel := context.WithTimeout(ctx, c.config.MutateTimeout)
 	defer deleteCancel()
+	deleteStart := time.Now()
 	err = c.kubeClient.CoreV1().ConfigMaps(c.config.Namespace).Delete(deleteCtx, createdConfigMap.Name, metav1.DeleteOptions{})
+	checker.RecordPhaseDuration(c, phaseDelete, time.Since(deleteStart))
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			return checker.Unhealthy(ErrCodeAPIServerDeleteTimeout, "timed out while deleting ConfigMap"), nil
@@ -85,12 +85,7 @@ func Register() {
 }
 
 // buildAzurePolicyChecker creates a new AzurePolicyChecker instance.
-func buildAzurePolicyChecker(config *config.CheckerConfig, kubeClient kubernetes.Interface) (checker.Checker, error) {
-	restConfig, err := rest.InClusterConfig()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get in-cluster config: %w", err)
-	}
-
+func buildAzurePolicyChecker(config *config.CheckerConfig, kubeClient kubernetes.Interface, restConfig *rest.Config) (checker.Checker, error) {
 	return &AzurePolicyChecker{
 		name:          config.Name,
 		timeout:       config.Timeout,
@@ -108,8 +103,10 @@ func (c *AzurePolicyChecker) Type() config.CheckerType {
 }
 
 func (c *AzurePolicyChecker) Run(ctx context.Context) {
+	start := time.Now()
 	result, err := c.check(ctx)
 	checker.RecordResult(c, result, err)
+	checker.RecordDuration(c, result, err, time.Since(start))
 }
 
 // check executes the Azure Policy check by doing a dry run creation of a test pod that violates default AKS Deployment Safeguards policies.
@@ -167,7 +164,7 @@ func (c *AzurePolicyChecker) createTestPod() *corev1.Pod {
 			Containers: []corev1.Container{
 				{
 					Name:  "synthetic",
-					Image: "mcr.microsoft.com/azurelinux/base/nginx:1.25.4-4-azl3.0.20250702",
+					Image: config.DefaultSyntheticPodImage,
 					// Intentionally not setting readiness or liveness probes to trigger potential policy violations
 				},
 			},
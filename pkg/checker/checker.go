@@ -3,10 +3,16 @@ package checker
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/Azure/cluster-health-monitor/pkg/config"
 	"github.com/Azure/cluster-health-monitor/pkg/metrics"
+	"github.com/Azure/cluster-health-monitor/pkg/status"
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 )
 
@@ -21,84 +27,325 @@ type Checker interface {
 	Run(ctx context.Context)
 }
 
-type Builder func(cfg *config.CheckerConfig, kubeClient kubernetes.Interface) (Checker, error)
+// Builder builds a checker from its config. restConfig is the configuration of kubeClient, for checkers that need to create other
+// clients.
+type Builder func(cfg *config.CheckerConfig, kubeClient kubernetes.Interface, restConfig *rest.Config) (Checker, error)
 
 var checkerRegistry = make(map[config.CheckerType]Builder)
 
+var (
+	// retiredMu guards retired. Results are recorded while holding its read lock, so that once Retire returns, no result of the retired
+	// checker is being recorded anymore.
+	retiredMu sync.RWMutex
+	// retired holds the names of the checkers that were removed while a run of theirs may still be in flight, e.g. a hung run. Checkers
+	// are keyed by name because not all of them are comparable.
+	retired = make(map[string]struct{})
+
+	resultHandlersMu sync.Mutex
+	// resultHandlers are called on every recorded result.
+	resultHandlers []func(checkerName, checkerType string, result status.Result)
+)
+
 func RegisterChecker(t config.CheckerType, builder Builder) {
 	checkerRegistry[t] = builder
 	klog.InfoS("Registered checker", "type", t)
 }
 
 // Build creates checkers from a list of checker configs
-func Build(cfg *config.CheckerConfig, kubeClient kubernetes.Interface) (Checker, error) {
+func Build(cfg *config.CheckerConfig, kubeClient kubernetes.Interface, restConfig *rest.Config) (Checker, error) {
 	if kubeClient == nil {
 		return nil, fmt.Errorf("kubernetes client cannot be nil")
 	}
+	if restConfig == nil {
+		return nil, fmt.Errorf("kubernetes rest config cannot be nil")
+	}
 
 	builder, ok := checkerRegistry[cfg.Type]
 	if !ok {
 		return nil, fmt.Errorf("unrecognized checker type: %q", cfg.Type)
 	}
-	return builder(cfg, kubeClient)
+	return builder(cfg, kubeClient, restConfig)
+}
+
+// OnResult registers a function that is called with every result recorded by a checker, as stored in the status store. Unlike the status
+// store, which only keeps the most recent results, it sees all of them. Handlers are called synchronously from the goroutine recording the
+// result, so they must not block.
+func OnResult(handler func(checkerName, checkerType string, result status.Result)) {
+	resultHandlersMu.Lock()
+	defer resultHandlersMu.Unlock()
+	resultHandlers = append(resultHandlers, handler)
 }
 
-// RecordResult increments the result counter for a specific checker run.
+// RecordResult increments the result counter for a specific checker run, updates the last result and effective status gauges and stores
+// the result in the status store.
 // If err is not nil, it records a run error (unknown status).
 // If result is not nil, it records the status from the result.
 func RecordResult(checker Checker, result *Result, err error) {
+	recordResult(checker, targetLabels{}, result, err)
+}
+
+// RecordCoreDNSPodResult records the result of a specific core DNS pod check like RecordResult.
+func RecordCoreDNSPodResult(checker Checker, podNamespace, podName string, result *Result, err error) {
+	recordResult(checker, targetLabels{podNamespace: podNamespace, podName: podName}, result, err)
+}
+
+// RecordNameserverResult records the result of a specific DNS nameserver check like RecordResult.
+func RecordNameserverResult(checker Checker, nameserver string, result *Result, err error) {
+	recordResult(checker, targetLabels{nameserver: nameserver}, result, err)
+}
+
+// RecordPlacementGroupResult records the result of a specific placement group check like RecordResult.
+func RecordPlacementGroupResult(checker Checker, group string, result *Result, err error) {
+	recordResult(checker, targetLabels{group: group}, result, err)
+}
+
+// targetLabels identifies what a result is about: the checker as a whole if all fields are empty, or else a single pod, nameserver or
+// placement group checked by the checker.
+type targetLabels struct {
+	podNamespace string
+	podName      string
+	nameserver   string
+	group        string
+}
+
+// pod returns the namespace/name of the pod, or an empty string if the target is not a pod.
+func (t targetLabels) pod() string {
+	if t.podName == "" {
+		return ""
+	}
+	return t.podNamespace + "/" + t.podName
+}
+
+// metrics returns the result counter, last status gauge and effective status gauge of the kind of target, and the label values of the
+// target, which follow the checker type and name labels.
+func (t targetLabels) metrics() (*prometheus.CounterVec, *prometheus.GaugeVec, *prometheus.GaugeVec, []string) {
+	switch {
+	case t.podName != "":
+		return metrics.PodHealthResultCounter, metrics.PodHealthLastStatusGauge, metrics.PodHealthEffectiveStatusGauge,
+			[]string{t.podNamespace, t.podName}
+	case t.nameserver != "":
+		return metrics.NameserverHealthResultCounter, metrics.NameserverHealthLastStatusGauge, metrics.NameserverHealthEffectiveStatusGauge,
+			[]string{t.nameserver}
+	case t.group != "":
+		return metrics.PlacementGroupHealthResultCounter, metrics.PlacementGroupHealthLastStatusGauge,
+			metrics.PlacementGroupHealthEffectiveStatusGauge, []string{t.group}
+	default:
+		return metrics.CheckerResultCounter, metrics.CheckerLastStatusGauge, metrics.CheckerEffectiveStatusGauge, nil
+	}
+}
+
+// logValues returns the key-value pairs identifying the target in logs.
+func (t targetLabels) logValues() []any {
+	switch {
+	case t.podName != "":
+		return []any{"podNamespace", t.podNamespace, "podName", t.podName}
+	case t.nameserver != "":
+		return []any{"nameserver", t.nameserver}
+	case t.group != "":
+		return []any{"group", t.group}
+	default:
+		return nil
+	}
+}
+
+// recordResult increments the result counter of the target, updates its last result and effective status gauges and stores the result in
+// the status store. A run error is recorded as unknown status. Results of retired checkers are dropped.
+func recordResult(checker Checker, target targetLabels, result *Result, err error) {
 	checkerType := string(checker.Type())
 	checkerName := checker.Name()
+	retiredMu.RLock()
+	defer retiredMu.RUnlock()
+	if _, ok := retired[checkerName]; ok {
+		klog.V(3).InfoS("Dropped result of removed checker", append([]any{"name", checkerName, "type", checkerType}, target.logValues()...)...)
+		return
+	}
+	storeResult := statusResult(result, err)
+	if pod := target.pod(); pod != "" {
+		storeResult.Pod = pod
+	}
+	storeResult.Nameserver = target.nameserver
+	storeResult.Group = target.group
+	status.DefaultStore.RecordResult(checkerName, checkerType, storeResult)
+	resultHandlersMu.Lock()
+	handlers := resultHandlers
+	resultHandlersMu.Unlock()
+	for _, handler := range handlers {
+		handler(checkerName, checkerType, storeResult)
+	}
+	metrics.CheckerLastRunTimestampGauge.WithLabelValues(checkerType, checkerName).SetToCurrentTime()
+
+	resultCounter, lastStatusGauge, _, targetLabelValues := target.metrics()
+	labelValues := append([]string{checkerType, checkerName}, targetLabelValues...)
+	logValues := append([]any{"name", checkerName, "type", checkerType}, target.logValues()...)
 	// If there's an error, record as unknown.
 	if err != nil {
-		metrics.CheckerResultCounter.WithLabelValues(checkerType, checkerName, metrics.UnknownStatus, metrics.UnknownCode).Inc()
-		klog.V(3).InfoS("Recorded checker result", "name", checkerName, "type", checkerType, "status", metrics.UnknownStatus)
-		klog.ErrorS(err, "Failed checker run", "name", checkerName, "type", checkerType)
+		resultCounter.WithLabelValues(append(labelValues, metrics.UnknownStatus, metrics.UnknownCode)...).Inc()
+		setLastStatus(lastStatusGauge, metrics.UnknownStatus, labelValues...)
+		recordTargetEffectiveStatus(checkerType, checkerName, target, metrics.UnknownStatus, metrics.UnknownCode, err.Error())
+		klog.V(3).InfoS("Recorded checker result", append(logValues, "status", metrics.UnknownStatus)...)
+		klog.ErrorS(err, "Failed checker run", logValues...)
 		return
 	}
 
 	// Record based on result status.
-	var status string
-	var errorCode string
-	switch result.Status {
-	case StatusHealthy:
-		status = metrics.HealthyStatus
-		errorCode = metrics.HealthyCode
-	case StatusUnhealthy:
-		status = metrics.UnhealthyStatus
-		errorCode = result.Detail.Code
+	status, errorCode := resultLabels(result)
+
+	resultCounter.WithLabelValues(append(labelValues, status, errorCode)...).Inc()
+	setLastStatus(lastStatusGauge, status, labelValues...)
+	recordTargetEffectiveStatus(checkerType, checkerName, target, status, errorCode, result.Detail.Message)
+	klog.V(3).InfoS("Recorded checker result", append(logValues, "status", status, "errorCode", errorCode, "message", result.Detail.Message)...)
+}
+
+// RecordRunStart stores the start time of a checker run in the status store, unless the checker was retired.
+func RecordRunStart(checker Checker, t time.Time) {
+	retiredMu.RLock()
+	defer retiredMu.RUnlock()
+	if _, ok := retired[checker.Name()]; !ok {
+		status.DefaultStore.RecordRunStart(checker.Name(), string(checker.Type()), t)
+	}
+}
+
+// RecordRunEnd stores the end time of a checker run in the status store, unless the checker was retired.
+func RecordRunEnd(checker Checker, t time.Time) {
+	retiredMu.RLock()
+	defer retiredMu.RUnlock()
+	if _, ok := retired[checker.Name()]; !ok {
+		status.DefaultStore.RecordRunEnd(checker.Name(), string(checker.Type()), t)
 	}
+}
 
-	metrics.CheckerResultCounter.WithLabelValues(checkerType, checkerName, status, errorCode).Inc()
-	klog.V(3).InfoS("Recorded checker result", "name", checkerName, "type", checkerType, "status", status, "errorCode", errorCode, "message", result.Detail.Message)
+// Retire drops all results and run times recorded by the checker from now on. It is called when a checker is removed, so that a run
+// that returns afterwards, e.g. a hung run, neither recreates the deleted status and gauges of the checker nor overlaps a new checker of
+// the same name. Release must be called once no run of the checker is in flight anymore, and no checker of the same name may be started
+// until then.
+func Retire(checker Checker) {
+	retiredMu.Lock()
+	defer retiredMu.Unlock()
+	retired[checker.Name()] = struct{}{}
 }
 
-// RecordCoreDNSPodResult increments the result counter for a specific core DNS pod check.
-// If err is not nil, it records a run error (unknown status).
-// If result is not nil, it records the status from the result.
-func RecordCoreDNSPodResult(checker Checker, podNamespace, podName string, result *Result, err error) {
-	checkerType := string(checker.Type())
-	checkerName := checker.Name()
-	// If there's an error, record as unknown.
-	if err != nil {
-		metrics.PodHealthResultCounter.WithLabelValues(checkerType, checkerName, podNamespace, podName, metrics.UnknownStatus, metrics.UnknownCode).Inc()
-		klog.V(3).InfoS("Recorded checker result", "name", checkerName, "type", checkerType, "podNamespace", podNamespace, "podName", podName, "status", metrics.UnknownStatus)
-		klog.ErrorS(err, "Failed checker run", "name", checkerName, "type", checkerType, "podNamespace", podNamespace, "podName", podName)
+// IsRetired returns whether a checker with the given name was retired and not released yet.
+func IsRetired(name string) bool {
+	retiredMu.RLock()
+	defer retiredMu.RUnlock()
+	_, ok := retired[name]
+	return ok
+}
+
+// Release forgets a retired checker once no run of it is in flight anymore. Releasing a checker that was not retired has no effect.
+func Release(checker Checker) {
+	retiredMu.Lock()
+	defer retiredMu.Unlock()
+	delete(retired, checker.Name())
+}
+
+// RecordRunError increments the run error counter of a checker with the given reason and logs the error. It is used for errors that keep
+// a run from producing some or all of its results, e.g. a failure to discover the targets of a per-target checker, which would otherwise
+// only silence the per-target series.
+func RecordRunError(checker Checker, reason string, err error) {
+	metrics.CheckerRunErrorCounter.WithLabelValues(string(checker.Type()), checker.Name(), reason).Inc()
+	klog.ErrorS(err, "Checker run error", "name", checker.Name(), "type", checker.Type(), "reason", reason)
+}
+
+// RecordDuration observes the duration of a checker run, labeled by the status the run ended with. A run error is recorded as unknown
+// status.
+func RecordDuration(checker Checker, result *Result, err error, duration time.Duration) {
+	status := metrics.UnknownStatus
+	if err == nil {
+		status, _ = resultLabels(result)
+	}
+	metrics.CheckerDurationHistogram.WithLabelValues(string(checker.Type()), checker.Name(), status).Observe(duration.Seconds())
+}
+
+// RecordPhaseDuration observes the duration of a single phase of a checker run, e.g. one API call or one DNS query.
+func RecordPhaseDuration(checker Checker, phase string, duration time.Duration) {
+	metrics.CheckerPhaseDurationHistogram.WithLabelValues(string(checker.Type()), checker.Name(), phase).Observe(duration.Seconds())
+}
+
+// RecordPlacementGroupPhaseDuration observes the duration of a single phase of a checker run in a placement group.
+func RecordPlacementGroupPhaseDuration(checker Checker, group, phase string, duration time.Duration) {
+	metrics.PlacementGroupPhaseDurationHistogram.WithLabelValues(string(checker.Type()), checker.Name(), group, phase).Observe(duration.Seconds())
+}
+
+// RecordStorageOperationDuration observes the duration of a storage operation, e.g. provisioning or attach, on the given volume of the given
+// storage class.
+func RecordStorageOperationDuration(checker Checker, storageClass, volume, operation string, duration time.Duration) {
+	metrics.StorageOperationDurationHistogram.WithLabelValues(string(checker.Type()), checker.Name(), storageClass, volume, operation).
+		Observe(duration.Seconds())
+}
+
+// RecordVolumeIODuration observes the duration of a volume I/O operation, i.e. write or read, on a volume of the given storage class.
+func RecordVolumeIODuration(checker Checker, storageClass, operation string, duration time.Duration) {
+	metrics.VolumeIODurationHistogram.WithLabelValues(string(checker.Type()), checker.Name(), storageClass, operation).Observe(duration.Seconds())
+}
+
+// DeleteLastResult removes the last result and effective status gauges of the named checker and resets its effective status, so that a
+// checker removed from the configuration does not keep reporting its final state.
+func DeleteLastResult(checkerName string) {
+	labels := prometheus.Labels{"checker_name": checkerName}
+	metrics.CheckerLastStatusGauge.DeletePartialMatch(labels)
+	metrics.PodHealthLastStatusGauge.DeletePartialMatch(labels)
+	metrics.NameserverHealthLastStatusGauge.DeletePartialMatch(labels)
+	metrics.PlacementGroupHealthLastStatusGauge.DeletePartialMatch(labels)
+	metrics.CheckerLastRunTimestampGauge.DeletePartialMatch(labels)
+	metrics.CheckerEffectiveStatusGauge.DeletePartialMatch(labels)
+	metrics.PodHealthEffectiveStatusGauge.DeletePartialMatch(labels)
+	metrics.NameserverHealthEffectiveStatusGauge.DeletePartialMatch(labels)
+	metrics.PlacementGroupHealthEffectiveStatusGauge.DeletePartialMatch(labels)
+	deleteEffectiveStatus(checkerName)
+}
+
+// setLastStatus sets the series of the given status to 1 and the series of all other statuses to 0. The status label must be the last
+// label of the gauge. Statuses without a series, e.g. skipped results, leave the gauge unchanged.
+func setLastStatus(gauge *prometheus.GaugeVec, status string, labelValues ...string) {
+	if !isGaugeStatus(status) {
 		return
 	}
+	for _, s := range metrics.Statuses {
+		value := 0.0
+		if s == status {
+			value = 1
+		}
+		gauge.WithLabelValues(append(labelValues, s)...).Set(value)
+	}
+}
 
-	// Record based on result status.
-	var status string
-	var errorCode string
+// isGaugeStatus returns true if the status has a series in the status gauges.
+func isGaugeStatus(status string) bool {
+	return slices.Contains(metrics.Statuses, status)
+}
+
+// resultLabels returns the status and error code metric labels of a result.
+func resultLabels(result *Result) (string, string) {
 	switch result.Status {
 	case StatusHealthy:
-		status = metrics.HealthyStatus
-		errorCode = metrics.HealthyCode
+		return metrics.HealthyStatus, metrics.HealthyCode
 	case StatusUnhealthy:
-		status = metrics.UnhealthyStatus
-		errorCode = result.Detail.Code
+		return metrics.UnhealthyStatus, result.Detail.Code
+	case StatusUnknown:
+		if result.Detail.Code != "" {
+			return metrics.UnknownStatus, result.Detail.Code
+		}
+		return metrics.UnknownStatus, metrics.UnknownCode
 	}
+	return "", ""
+}
 
-	metrics.PodHealthResultCounter.WithLabelValues(checkerType, checkerName, podNamespace, podName, status, errorCode).Inc()
-	klog.V(3).InfoS("Recorded checker result", "name", checkerName, "type", checkerType, "podNamespace", podNamespace, "podName", podName, "status", status, "errorCode", errorCode, "message", result.Detail.Message)
+// statusResult converts a checker result or run error to a status store result.
+func statusResult(result *Result, err error) status.Result {
+	if err != nil {
+		return status.Result{
+			Status:  metrics.UnknownStatus,
+			Code:    metrics.UnknownCode,
+			Message: err.Error(),
+			Time:    time.Now(),
+		}
+	}
+	return status.Result{
+		Status:  string(result.Status),
+		Code:    result.Detail.Code,
+		Message: result.Detail.Message,
+		Pod:     result.Detail.Pod,
+		Time:    time.Now(),
+	}
 }
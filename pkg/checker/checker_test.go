@@ -4,11 +4,17 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/Azure/cluster-health-monitor/pkg/config"
+	"github.com/Azure/cluster-health-monitor/pkg/metrics"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
 	"k8s.io/client-go/kubernetes"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
 )
 
 type fakeChecker struct{ name string }
@@ -17,7 +23,7 @@ func (f *fakeChecker) Name() string             { return f.name }
 func (f *fakeChecker) Run(ctx context.Context)  {}
 func (f *fakeChecker) Type() config.CheckerType { return config.CheckerType("fake") }
 
-func fakeBuilder(cfg *config.CheckerConfig, kubeClient kubernetes.Interface) (Checker, error) {
+func fakeBuilder(cfg *config.CheckerConfig, kubeClient kubernetes.Interface, restConfig *rest.Config) (Checker, error) {
 	if cfg.Name == "fail" {
 		return nil, errors.New("forced error")
 	}
@@ -88,8 +94,131 @@ func TestBuildChecker(t *testing.T) {
 			t.Parallel()
 			g := NewWithT(t)
 
-			chk, err := Build(tc.config, tc.kubeClient)
+			chk, err := Build(tc.config, tc.kubeClient, &rest.Config{})
 			tc.validateChecker(g, chk, err)
 		})
 	}
 }
+
+func TestRecordDuration(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	chk := &fakeChecker{name: "duration"}
+	durationSum := func(status string) float64 {
+		return histogramSum(g, metrics.CheckerDurationHistogram.WithLabelValues("fake", "duration", status))
+	}
+	phaseSum := func() float64 {
+		return histogramSum(g, metrics.CheckerPhaseDurationHistogram.WithLabelValues("fake", "duration", "phase"))
+	}
+	initialSums := map[string]float64{}
+	for _, status := range metrics.Statuses {
+		initialSums[status] = durationSum(status)
+	}
+	initialPhaseSum := phaseSum()
+
+	RecordDuration(chk, Healthy(), nil, time.Second)
+	RecordDuration(chk, Unhealthy("SomeError", "failed"), nil, 2*time.Second)
+	RecordDuration(chk, nil, errors.New("run error"), 3*time.Second)
+	RecordPhaseDuration(chk, "phase", 4*time.Second)
+
+	for status, expectedSum := range map[string]float64{
+		metrics.HealthyStatus:   1,
+		metrics.UnhealthyStatus: 2,
+		metrics.UnknownStatus:   3,
+	} {
+		g.Expect(durationSum(status)-initialSums[status]).To(Equal(expectedSum), "status %s", status)
+	}
+	g.Expect(phaseSum() - initialPhaseSum).To(Equal(4.0))
+}
+
+func histogramSum(g *WithT, observer prometheus.Observer) float64 {
+	m := &dto.Metric{}
+	g.Expect(observer.(prometheus.Metric).Write(m)).To(Succeed())
+	return m.GetHistogram().GetSampleSum()
+}
+
+func TestRecordResult_LastStatus(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	chk := &fakeChecker{name: "laststatus"}
+
+	lastStatus := func(status string) float64 {
+		return testutil.ToFloat64(metrics.CheckerLastStatusGauge.WithLabelValues("fake", "laststatus", status))
+	}
+	lastPodStatus := func(status string) float64 {
+		return testutil.ToFloat64(metrics.PodHealthLastStatusGauge.WithLabelValues("fake", "laststatus", "ns", "pod", status))
+	}
+	lastNameserverStatus := func(status string) float64 {
+		return testutil.ToFloat64(metrics.NameserverHealthLastStatusGauge.WithLabelValues("fake", "laststatus", "10.0.0.53:53", status))
+	}
+
+	before := float64(time.Now().Unix())
+	RecordResult(chk, Unhealthy("SomeError", "failed"), nil)
+	g.Expect(lastStatus(metrics.UnhealthyStatus)).To(Equal(1.0))
+	g.Expect(lastStatus(metrics.HealthyStatus)).To(Equal(0.0))
+	g.Expect(lastStatus(metrics.UnknownStatus)).To(Equal(0.0))
+	g.Expect(testutil.ToFloat64(metrics.CheckerLastRunTimestampGauge.WithLabelValues("fake", "laststatus"))).To(BeNumerically(">=", before))
+
+	RecordResult(chk, Healthy(), nil)
+	g.Expect(lastStatus(metrics.HealthyStatus)).To(Equal(1.0))
+	g.Expect(lastStatus(metrics.UnhealthyStatus)).To(Equal(0.0))
+
+	// Skipped results do not change the last status.
+	RecordResult(chk, Skipped("skipped"), nil)
+	g.Expect(lastStatus(metrics.HealthyStatus)).To(Equal(1.0))
+
+	RecordCoreDNSPodResult(chk, "ns", "pod", nil, errors.New("run error"))
+	g.Expect(lastPodStatus(metrics.UnknownStatus)).To(Equal(1.0))
+	g.Expect(lastPodStatus(metrics.HealthyStatus)).To(Equal(0.0))
+
+	nameserverResults := func() float64 {
+		return testutil.ToFloat64(metrics.NameserverHealthResultCounter.WithLabelValues("fake", "laststatus", "10.0.0.53:53",
+			metrics.UnhealthyStatus, "NameserverTimeout"))
+	}
+	nameserverResultsBefore := nameserverResults()
+	RecordNameserverResult(chk, "10.0.0.53:53", Unhealthy("NameserverTimeout", "timed out"), nil)
+	g.Expect(lastNameserverStatus(metrics.UnhealthyStatus)).To(Equal(1.0))
+	g.Expect(lastNameserverStatus(metrics.HealthyStatus)).To(Equal(0.0))
+	g.Expect(nameserverResults() - nameserverResultsBefore).To(Equal(1.0))
+
+	lastGroupStatus := func(status string) float64 {
+		return testutil.ToFloat64(metrics.PlacementGroupHealthLastStatusGauge.WithLabelValues("fake", "laststatus", "nodepool1", status))
+	}
+	groupResults := func() float64 {
+		return testutil.ToFloat64(metrics.PlacementGroupHealthResultCounter.WithLabelValues("fake", "laststatus", "nodepool1",
+			metrics.UnhealthyStatus, "PodStartupDurationExceeded"))
+	}
+	groupResultsBefore := groupResults()
+	RecordPlacementGroupResult(chk, "nodepool1", Unhealthy("PodStartupDurationExceeded", "slow"), nil)
+	g.Expect(lastGroupStatus(metrics.UnhealthyStatus)).To(Equal(1.0))
+	g.Expect(lastGroupStatus(metrics.HealthyStatus)).To(Equal(0.0))
+	g.Expect(groupResults() - groupResultsBefore).To(Equal(1.0))
+	RecordPlacementGroupResult(chk, "nodepool1", nil, errors.New("run error"))
+	g.Expect(lastGroupStatus(metrics.UnknownStatus)).To(Equal(1.0))
+	g.Expect(lastGroupStatus(metrics.UnhealthyStatus)).To(Equal(0.0))
+
+	// DeleteLabelValues returns false because the series were already deleted.
+	DeleteLastResult("laststatus")
+	g.Expect(metrics.CheckerLastStatusGauge.DeleteLabelValues("fake", "laststatus", metrics.HealthyStatus)).To(BeFalse())
+	g.Expect(metrics.PodHealthLastStatusGauge.DeleteLabelValues("fake", "laststatus", "ns", "pod", metrics.UnknownStatus)).To(BeFalse())
+	g.Expect(metrics.NameserverHealthLastStatusGauge.DeleteLabelValues("fake", "laststatus", "10.0.0.53:53", metrics.UnhealthyStatus)).To(BeFalse())
+	g.Expect(metrics.PlacementGroupHealthLastStatusGauge.DeleteLabelValues("fake", "laststatus", "nodepool1", metrics.UnknownStatus)).To(BeFalse())
+	g.Expect(metrics.CheckerLastRunTimestampGauge.DeleteLabelValues("fake", "laststatus")).To(BeFalse())
+}
+
+func TestRecordRunError(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	chk := &fakeChecker{name: "runerror"}
+
+	runErrors := func(reason string) float64 {
+		return testutil.ToFloat64(metrics.CheckerRunErrorCounter.WithLabelValues("fake", "runerror", reason))
+	}
+	discoveryBefore, otherBefore := runErrors("DiscoveryFailed"), runErrors("OtherReason")
+
+	RecordRunError(chk, "DiscoveryFailed", errors.New("forbidden"))
+	RecordRunError(chk, "DiscoveryFailed", errors.New("forbidden"))
+	RecordRunError(chk, "OtherReason", errors.New("other"))
+	g.Expect(runErrors("DiscoveryFailed") - discoveryBefore).To(Equal(2.0))
+	g.Expect(runErrors("OtherReason") - otherBefore).To(Equal(1.0))
+}
@@ -5,12 +5,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/miekg/dns"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 
 	"github.com/Azure/cluster-health-monitor/pkg/checker"
@@ -22,10 +27,26 @@ func Register() {
 }
 
 const (
-	coreDNSNamespace   = "kube-system"
-	coreDNSServiceName = "kube-dns"
-	resolvConfPath     = "/etc/resolv.conf"
-	localDNSIP         = "169.254.10.11"
+	defaultCoreDNSNamespace   = "kube-system"
+	defaultCoreDNSServiceName = "kube-dns"
+	resolvConfPath            = "/etc/resolv.conf"
+	localDNSIP                = "169.254.10.11"
+	dnsPort                   = "53"
+)
+
+// Phases of a DNSChecker run recorded in the phase duration histogram. Each query is recorded separately.
+const (
+	phaseServiceQuery  = "service_query"
+	phasePodQuery      = "pod_query"
+	phaseLocalDNSQuery = "localdns_query"
+	phaseCustomQuery   = "nameserver_query"
+	// phaseResolvConfQuery is a single query of an expanded name with the ResolvConf target and phaseResolvConfResolution the whole
+	// resolution through the search list.
+	phaseResolvConfQuery      = "resolvconf_query"
+	phaseResolvConfResolution = "resolvconf_resolution"
+
+	// phaseTCPSuffix is appended to the phase of the TCP queries of the UDPAndTCP protocol.
+	phaseTCPSuffix = "_tcp"
 )
 
 // DNSChecker implements the Checker interface for DNS checks.
@@ -34,11 +55,17 @@ type DNSChecker struct {
 	config     *config.DNSConfig
 	kubeClient kubernetes.Interface
 	resolver   resolver
+	// tcpResolver sends the TCP queries of the UDPAndTCP protocol. It is nil for other protocols.
+	tcpResolver resolver
+	// nameservers are the host:port addresses of the nameservers queried by the Custom target.
+	nameservers []string
+	// resolvConfPath is the path of the resolv.conf file used by the ResolvConf target.
+	resolvConfPath string
 }
 
 // BuildDNSChecker creates a new DNSChecker instance.
 // If the DNSType is LocalDNS, it checks if LocalDNS IP is enabled before creating the checker.
-func BuildDNSChecker(checkerConfig *config.CheckerConfig, kubeClient kubernetes.Interface) (checker.Checker, error) {
+func BuildDNSChecker(checkerConfig *config.CheckerConfig, kubeClient kubernetes.Interface, restConfig *rest.Config) (checker.Checker, error) {
 	// If this is a LocalDNS checker, check if LocalDNS IP is enabled.
 	switch checkerConfig.DNSConfig.Target {
 	case config.DNSCheckTargetLocalDNS:
@@ -53,11 +80,34 @@ func BuildDNSChecker(checkerConfig *config.CheckerConfig, kubeClient kubernetes.
 		}
 	}
 
+	var nameservers []string
+	for _, nameserver := range checkerConfig.DNSConfig.Nameservers {
+		address, err := config.NameserverAddress(nameserver)
+		if err != nil {
+			return nil, err
+		}
+		nameservers = append(nameservers, address)
+	}
+
+	ednsBufferSize := uint16(checkerConfig.DNSConfig.EDNS0BufferSize)
 	chk := &DNSChecker{
-		name:       checkerConfig.Name,
-		config:     checkerConfig.DNSConfig,
-		kubeClient: kubeClient,
-		resolver:   &defaultResolver{},
+		name:           checkerConfig.Name,
+		config:         checkerConfig.DNSConfig,
+		kubeClient:     kubeClient,
+		nameservers:    nameservers,
+		resolvConfPath: resolvConfPath,
+	}
+	switch checkerConfig.DNSConfig.Protocol {
+	case "", config.DNSProtocolUDP:
+		// A truncated response is only a failure if large UDP responses are checked with EDNS0.
+		chk.resolver = &defaultResolver{network: "udp", ednsBufferSize: ednsBufferSize, tcpFallback: ednsBufferSize == 0}
+	case config.DNSProtocolUDPOnly:
+		chk.resolver = &defaultResolver{network: "udp", ednsBufferSize: ednsBufferSize}
+	case config.DNSProtocolTCP:
+		chk.resolver = &defaultResolver{network: "tcp", ednsBufferSize: ednsBufferSize}
+	case config.DNSProtocolUDPAndTCP:
+		chk.resolver = &defaultResolver{network: "udp", ednsBufferSize: ednsBufferSize}
+		chk.tcpResolver = &defaultResolver{network: "tcp", ednsBufferSize: ednsBufferSize}
 	}
 	klog.InfoS("Built DNSChecker",
 		"name", chk.name,
@@ -75,17 +125,30 @@ func (c DNSChecker) Type() config.CheckerType {
 }
 
 func (c DNSChecker) Run(ctx context.Context) {
+	start := time.Now()
 	switch c.config.Target {
 	case config.DNSCheckTargetCoreDNS:
 		result, err := c.checkCoreDNS(ctx)
 		checker.RecordResult(c, result, err)
+		checker.RecordDuration(c, result, err, time.Since(start))
 		return
 	case config.DNSCheckTargetLocalDNS:
 		result, err := c.checkLocalDNS(ctx)
 		checker.RecordResult(c, result, err)
+		checker.RecordDuration(c, result, err, time.Since(start))
 		return
 	case config.DNSCheckTargetCoreDNSPerPod:
+		// The overall result of per-pod mode is derived from the results of the pods, so only the duration of each pod query is
+		// recorded.
 		c.checkCoreDNSPerPod(ctx)
+	case config.DNSCheckTargetResolvConf:
+		result, err := c.checkResolvConf(ctx)
+		checker.RecordResult(c, result, err)
+		checker.RecordDuration(c, result, err, time.Since(start))
+		return
+	case config.DNSCheckTargetCustom:
+		// Like per-pod mode, each nameserver has its own result and the overall result is derived from them.
+		c.checkCustom(ctx)
 	}
 }
 
@@ -93,22 +156,19 @@ func (c DNSChecker) Run(ctx context.Context) {
 // If all queries succeed, the check is considered healthy.
 func (c DNSChecker) checkCoreDNS(ctx context.Context) (*checker.Result, error) {
 	// Check CoreDNS service.
-	svcIP, err := getCoreDNSSvcIP(ctx, c.kubeClient)
+	svcIP, err := getCoreDNSSvcIP(ctx, c.kubeClient, c.serviceNamespace(), c.serviceName())
 	if errors.Is(err, errServiceNotReady) {
 		return checker.Unhealthy(ErrCodeServiceNotReady, "CoreDNS service is not ready"), nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	if _, err := c.resolver.lookupHost(ctx, svcIP, c.config.Domain, c.config.QueryTimeout); err != nil {
-		if errors.Is(err, context.DeadlineExceeded) {
-			return checker.Unhealthy(ErrCodeServiceTimeout, "CoreDNS service query timed out"), nil
-		}
-		return checker.Unhealthy(ErrCodeServiceError, fmt.Sprintf("CoreDNS service query error: %s", err)), nil
+	if _, err := c.query(ctx, phaseServiceQuery, net.JoinHostPort(svcIP, dnsPort)); err != nil {
+		return coreDNSService.unhealthyResult(err), nil
 	}
 
 	// Check CoreDNS pods.
-	dnsEndpoints, err := getCoreDNSEndpoints(ctx, c.kubeClient)
+	dnsEndpoints, err := getCoreDNSEndpoints(ctx, c.kubeClient, c.serviceNamespace(), c.serviceName())
 	if errors.Is(err, errPodsNotReady) {
 		return checker.Unhealthy(ErrCodePodsNotReady, "CoreDNS Pods are not ready"), nil
 	}
@@ -118,11 +178,8 @@ func (c DNSChecker) checkCoreDNS(ctx context.Context) (*checker.Result, error) {
 
 	for _, dnsEndpoint := range dnsEndpoints {
 		for _, ip := range dnsEndpoint.Addresses {
-			if _, err := c.resolver.lookupHost(ctx, ip, c.config.Domain, c.config.QueryTimeout); err != nil {
-				if errors.Is(err, context.DeadlineExceeded) {
-					return checker.Unhealthy(ErrCodePodTimeout, "CoreDNS pod query timed out"), nil
-				}
-				return checker.Unhealthy(ErrCodePodError, fmt.Sprintf("CoreDNS pod query error: %s", err)), nil
+			if _, err := c.query(ctx, phasePodQuery, net.JoinHostPort(ip, dnsPort)); err != nil {
+				return coreDNSPod.unhealthyResult(err), nil
 			}
 		}
 	}
@@ -133,64 +190,509 @@ func (c DNSChecker) checkCoreDNS(ctx context.Context) (*checker.Result, error) {
 // checkLocalDNS queries the LocalDNS server.
 // If the query succeeds, the check is considered healthy.
 func (c DNSChecker) checkLocalDNS(ctx context.Context) (*checker.Result, error) {
-	if _, err := c.resolver.lookupHost(ctx, localDNSIP, c.config.Domain, c.config.QueryTimeout); err != nil {
-		if errors.Is(err, context.DeadlineExceeded) {
-			return checker.Unhealthy(ErrCodeLocalDNSTimeout, "LocalDNS query timed out"), nil
-		}
-		return checker.Unhealthy(ErrCodeLocalDNSError, fmt.Sprintf("LocalDNS query error: %s", err)), nil
+	if _, err := c.query(ctx, phaseLocalDNSQuery, net.JoinHostPort(localDNSIP, dnsPort)); err != nil {
+		return localDNS.unhealthyResult(err), nil
 	}
 
 	return checker.Healthy(), nil
 }
 
-// checkCoreDNSPods queries CoreDNS pods and records a result for each of the pods.
+// checkCoreDNSPods queries CoreDNS pods and records a result for each of the pods. It also records an overall result, which is
+// unhealthy or unknown if the pods cannot be discovered, unhealthy if any pod failed and, if answer consistency is checked, tells whether
+// all pods that answered returned the same answers. It is unknown if an endpoint does not name its pod or no pod was queried.
 func (c DNSChecker) checkCoreDNSPerPod(ctx context.Context) {
-	endpoints, err := getCoreDNSEndpoints(ctx, c.kubeClient)
+	endpoints, err := getCoreDNSEndpoints(ctx, c.kubeClient, c.serviceNamespace(), c.serviceName())
+	if errors.Is(err, errPodsNotReady) {
+		checker.RecordResult(c, checker.Unhealthy(ErrCodePodsNotReady, "CoreDNS Pods are not ready"), nil)
+		return
+	}
 	if err != nil {
-		// TODO: record error with a separate metric
-		klog.Errorf("Failed to get CoreDNS endpoints: %s", err)
+		checker.RecordRunError(c, RunErrorEndpointDiscovery, err)
+		checker.RecordResult(c, nil, err)
 		return
 	}
 
+	var answered []podAnswers
+	var failures []targetFailure
+	var errs []error
+	queried := 0
 	for _, endpoint := range endpoints {
 		if endpoint.TargetRef == nil || len(endpoint.TargetRef.Name) == 0 {
-			// TODO: record error with a separate metric
+			// The pod of the endpoint cannot be queried, so the overall result cannot be healthy.
+			err := errors.New("CoreDNS pod name missing in endpoint's targetRef")
 			if endpoint.NodeName != nil {
-				klog.Errorf("CoreDNS pod name missing in endpoint's targetRef on node %s", *endpoint.NodeName)
-			} else {
-				klog.Error("CoreDNS pod name missing in endpoint's targetRef")
+				err = fmt.Errorf("CoreDNS pod name missing in endpoint's targetRef on node %s", *endpoint.NodeName)
 			}
+			checker.RecordRunError(c, RunErrorMissingTargetRef, err)
+			errs = append(errs, err)
 			continue
 		}
+		queried++
 
 		// Query CoreDNS endpoint.
 		podname := endpoint.TargetRef.Name
 		podNamespace := endpoint.TargetRef.Namespace
-		err := c.queryEndpoint(ctx, endpoint)
+		answers, err := c.queryEndpoint(ctx, endpoint)
 		if err != nil {
-			if errors.Is(err, context.DeadlineExceeded) {
-				checker.RecordCoreDNSPodResult(c, podNamespace, podname, checker.Unhealthy(ErrCodePodTimeout, "CoreDNS pod query timed out"), nil)
+			if isQueryFailure(err) {
+				result := coreDNSPod.unhealthyResult(err)
+				checker.RecordCoreDNSPodResult(c, podNamespace, podname, result, nil)
+				failures = append(failures, targetFailure{
+					code:    result.Detail.Code,
+					message: fmt.Sprintf("%s/%s: %s", podNamespace, podname, result.Detail.Message),
+				})
 			} else {
 				checker.RecordCoreDNSPodResult(c, podNamespace, podname, nil, err)
+				errs = append(errs, fmt.Errorf("%s/%s: %w", podNamespace, podname, err))
 			}
+		} else if c.config.CheckAnswerConsistency {
+			// The result of the pod depends on the answers of the other pods.
+			answered = append(answered, podAnswers{namespace: podNamespace, name: podname, answers: normalizeAnswers(answers)})
 		} else {
 			checker.RecordCoreDNSPodResult(c, podNamespace, podname, checker.Healthy(), nil)
 		}
 	}
+
+	if len(answered) > 0 {
+		failures = append(failures, c.recordAnswerConsistency(answered)...)
+	}
+	if queried == 0 && len(errs) == 0 {
+		errs = append(errs, errors.New("no CoreDNS pod was queried"))
+	}
+	result, err := overallResult("CoreDNS pods", ErrCodePodsFailed, failures, errs)
+	checker.RecordResult(c, result, err)
+}
+
+// recordAnswerConsistency records the per-pod results of the pods that answered, with the pods whose answers differ from the majority
+// of the pods recorded as unhealthy, and returns the failures of the divergent pods.
+func (c DNSChecker) recordAnswerConsistency(answered []podAnswers) []targetFailure {
+	divergent := divergentPods(answered)
+	var failures []targetFailure
+	for _, pod := range answered {
+		if !slices.Contains(divergent, pod.key()) {
+			checker.RecordCoreDNSPodResult(c, pod.namespace, pod.name, checker.Healthy(), nil)
+			continue
+		}
+		message := fmt.Sprintf("CoreDNS pod answers %v differ from the answers of the other pods", pod.answers)
+		checker.RecordCoreDNSPodResult(c, pod.namespace, pod.name, checker.Unhealthy(ErrCodeInconsistentAnswers, message), nil)
+		failures = append(failures, targetFailure{
+			code:    ErrCodeInconsistentAnswers,
+			message: fmt.Sprintf("%s answered %v", pod.key(), pod.answers),
+		})
+	}
+	return failures
+}
+
+// targetFailure is the failure of one of the CoreDNS pods or nameservers for which a DNSChecker records separate results.
+type targetFailure struct {
+	code string
+	// message describes the failure in the overall result and names the pod or nameserver.
+	message string
+}
+
+// overallResult returns the overall result of the CoreDNS pods or nameservers described by description. It is unhealthy if any of them
+// failed, with the error code of the failures if they all share it and mixedCode otherwise. If none failed, the result is unknown if any
+// of them could not be queried and healthy otherwise.
+func overallResult(description, mixedCode string, failures []targetFailure, errs []error) (*checker.Result, error) {
+	if len(failures) == 0 {
+		if len(errs) > 0 {
+			return nil, errors.Join(errs...)
+		}
+		return checker.Healthy(), nil
+	}
+
+	code := failures[0].code
+	messages := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		if failure.code != code {
+			code = mixedCode
+		}
+		messages = append(messages, failure.message)
+	}
+	return checker.Unhealthy(code, fmt.Sprintf("%s failed: %s", description, strings.Join(messages, "; "))), nil
+}
+
+// podAnswers holds the normalized answers of a CoreDNS pod.
+type podAnswers struct {
+	namespace string
+	name      string
+	answers   []string
+}
+
+func (p podAnswers) key() string {
+	return p.namespace + "/" + p.name
+}
+
+// divergentPods returns the namespace/name of the pods whose answers differ from the answers returned by most of the pods. If no answer
+// set was returned by more pods than every other answer set, all pods are divergent unless they all returned the same answers.
+func divergentPods(answered []podAnswers) []string {
+	counts := make(map[string]int)
+	for _, pod := range answered {
+		counts[strings.Join(pod.answers, ",")]++
+	}
+	if len(counts) == 1 {
+		return nil
+	}
+
+	majority, majorityCount, tie := "", 0, false
+	for answers, count := range counts {
+		switch {
+		case count > majorityCount:
+			majority, majorityCount, tie = answers, count, false
+		case count == majorityCount:
+			tie = true
+		}
+	}
+
+	var divergent []string
+	for _, pod := range answered {
+		if tie || strings.Join(pod.answers, ",") != majority {
+			divergent = append(divergent, pod.key())
+		}
+	}
+	return divergent
 }
 
-func (c DNSChecker) queryEndpoint(ctx context.Context, endpoint discoveryv1.Endpoint) error {
+// normalizeAnswers returns the answers sorted and without duplicates, with domain names in lower case and fully qualified, so that
+// answer sets can be compared regardless of order and case.
+func normalizeAnswers(answers []string) []string {
+	normalized := make([]string, 0, len(answers))
+	for _, answer := range answers {
+		if ip := net.ParseIP(answer); ip != nil {
+			normalized = append(normalized, ip.String())
+		} else {
+			normalized = append(normalized, strings.ToLower(dns.Fqdn(answer)))
+		}
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+// queryEndpoint queries each address of the endpoint and returns the answers of all addresses.
+func (c DNSChecker) queryEndpoint(ctx context.Context, endpoint discoveryv1.Endpoint) ([]string, error) {
+	var answers []string
 	for _, ip := range endpoint.Addresses {
-		if _, err := c.resolver.lookupHost(ctx, ip, c.config.Domain, c.config.QueryTimeout); err != nil {
-			return err
+		addressAnswers, err := c.query(ctx, phasePodQuery, net.JoinHostPort(ip, dnsPort))
+		if err != nil {
+			return nil, err
+		}
+		answers = append(answers, addressAnswers...)
+	}
+	return answers, nil
+}
+
+// checkCustom queries each of the configured nameservers and records a result for each of them. It also records an overall result, which
+// is unhealthy if any nameserver failed.
+func (c DNSChecker) checkCustom(ctx context.Context) {
+	var failures []targetFailure
+	var errs []error
+	for _, nameserver := range c.nameservers {
+		_, err := c.query(ctx, phaseCustomQuery, nameserver)
+		if err != nil {
+			if isQueryFailure(err) {
+				result := customNameserver.unhealthyResult(err)
+				checker.RecordNameserverResult(c, nameserver, result, nil)
+				failures = append(failures, targetFailure{
+					code:    result.Detail.Code,
+					message: fmt.Sprintf("%s: %s", nameserver, result.Detail.Message),
+				})
+			} else {
+				checker.RecordNameserverResult(c, nameserver, nil, err)
+				errs = append(errs, fmt.Errorf("%s: %w", nameserver, err))
+			}
+		} else {
+			checker.RecordNameserverResult(c, nameserver, checker.Healthy(), nil)
+		}
+	}
+
+	result, err := overallResult("Nameservers", ErrCodeNameserversFailed, failures, errs)
+	checker.RecordResult(c, result, err)
+}
+
+// checkResolvConf resolves the domain the way a pod does: through the nameservers and the search list of resolv.conf, expanding the
+// domain like glibc with the ndots option. The healthy result tells which expanded name answered after how many queries.
+func (c DNSChecker) checkResolvConf(ctx context.Context) (*checker.Result, error) {
+	resolvConf, err := dns.ClientConfigFromFile(c.resolvConfPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", c.resolvConfPath, err)
+	}
+	if len(resolvConf.Servers) == 0 {
+		return nil, fmt.Errorf("no nameservers in %s", c.resolvConfPath)
+	}
+	domain, err := c.queryDomain()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	defer func() {
+		checker.RecordPhaseDuration(c, phaseResolvConfResolution, time.Since(start))
+	}()
+	queries := 0
+	for _, name := range resolvConf.NameList(domain) {
+		var answers []string
+		for _, server := range resolvConf.Servers {
+			// Like glibc, try the next nameserver only if this one did not respond.
+			queries++
+			answers, err = c.queryName(ctx, phaseResolvConfQuery, net.JoinHostPort(server, resolvConf.Port), name)
+			if err == nil || isResponse(err) {
+				break
+			}
+		}
+		if err == nil {
+			message := fmt.Sprintf("resolved %s as %s after %d queries in %s", c.config.Domain, name, queries, time.Since(start))
+			klog.V(3).InfoS("Resolved domain through resolv.conf", "name", c.name, "domain", c.config.Domain, "answeredName", name,
+				"queries", queries)
+			return &checker.Result{Status: checker.StatusHealthy, Detail: checker.Detail{Message: message}}, nil
+		}
+		// Like glibc, go on with the next name if this one does not exist, has no records of the type or failed on the server.
+		var rcodeErr *rcodeError
+		nextName := (errors.As(err, &rcodeErr) && (rcodeErr.rcode == dns.RcodeNameError || rcodeErr.rcode == dns.RcodeServerFailure)) ||
+			(errors.Is(err, errUnexpectedAnswer) && len(answers) == 0)
+		if !nextName {
+			break
+		}
+	}
+	return resolvConfServer.unhealthyResult(fmt.Errorf("%w after %d queries", err, queries)), nil
+}
+
+// isResponse returns whether the error of a query means that the DNS server responded, as opposed to the query timing out or failing
+// to be sent.
+func isResponse(err error) bool {
+	var rcodeErr *rcodeError
+	return errors.As(err, &rcodeErr) || errors.Is(err, errTruncated) || errors.Is(err, errUnexpectedAnswer)
+}
+
+// serviceNamespace returns the namespace of the CoreDNS service.
+func (c DNSChecker) serviceNamespace() string {
+	if c.config.ServiceNamespace == "" {
+		return defaultCoreDNSNamespace
+	}
+	return c.config.ServiceNamespace
+}
+
+// serviceName returns the name of the CoreDNS service.
+func (c DNSChecker) serviceName() string {
+	if c.config.ServiceName == "" {
+		return defaultCoreDNSServiceName
+	}
+	return c.config.ServiceName
+}
+
+// query queries the configured domain and record type on the DNS server at the host:port address, records the query duration under the
+// given phase, checks the answers against the configured expectations and returns them.
+func (c DNSChecker) query(ctx context.Context, phase, address string) ([]string, error) {
+	domain, err := c.queryDomain()
+	if err != nil {
+		return nil, err
+	}
+	return c.queryName(ctx, phase, address, domain)
+}
+
+// queryName is like query for the given domain instead of the configured one. If the answers do not match the configured expectations,
+// they are returned along with the error.
+func (c DNSChecker) queryName(ctx context.Context, phase, address, domain string) ([]string, error) {
+	answers, err := c.exchange(ctx, c.resolver, phase, address, domain)
+	if c.tcpResolver == nil {
+		if err != nil {
+			return nil, err
+		}
+		return answers, c.checkAnswers(answers)
+	}
+
+	// UDPAndTCP protocol: retry a truncated response over TCP like a stub resolver would, and otherwise check that TCP works as well.
+	if errors.Is(err, errTruncated) {
+		answers, err := c.exchange(ctx, c.tcpResolver, phase+phaseTCPSuffix, address, domain)
+		if err == nil {
+			err = c.checkAnswers(answers)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errNoTCPFallback, err)
+		}
+		return answers, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := c.checkAnswers(answers); err != nil {
+		return answers, err
+	}
+	answers, err = c.exchange(ctx, c.tcpResolver, phase+phaseTCPSuffix, address, domain)
+	if err == nil {
+		err = c.checkAnswers(answers)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errTCPFailed, err)
+	}
+	return answers, nil
+}
+
+// exchange sends a query with the resolver and records its duration under the given phase.
+func (c DNSChecker) exchange(ctx context.Context, r resolver, phase, address, domain string) ([]string, error) {
+	start := time.Now()
+	answers, err := r.query(ctx, address, domain, c.recordType(), c.config.QueryTimeout)
+	checker.RecordPhaseDuration(c, phase, time.Since(start))
+	return answers, err
+}
+
+// recordType returns the configured record type of the queries, A by default.
+func (c DNSChecker) recordType() uint16 {
+	if c.config.RecordType == "" {
+		return dns.TypeA
+	}
+	return dns.StringToType[string(c.config.RecordType)]
+}
+
+// queryDomain returns the domain to query. For PTR queries of an IP address, it is the reverse lookup name of the address.
+func (c DNSChecker) queryDomain() (string, error) {
+	if c.config.RecordType == config.DNSRecordTypePTR && net.ParseIP(c.config.Domain) != nil {
+		return dns.ReverseAddr(c.config.Domain)
+	}
+	return c.config.Domain, nil
+}
+
+// checkAnswers returns an error wrapping errUnexpectedAnswer if the answers contain fewer than the minimum number of answers or miss any
+// of the expected answers.
+func (c DNSChecker) checkAnswers(answers []string) error {
+	minAnswers := max(c.config.MinAnswers, 1)
+	if len(answers) < minAnswers {
+		return fmt.Errorf("%w: got %d answers, expected at least %d", errUnexpectedAnswer, len(answers), minAnswers)
+	}
+	for _, expected := range c.config.ExpectedAnswers {
+		if !slices.ContainsFunc(answers, func(answer string) bool { return answerEqual(answer, expected) }) {
+			return fmt.Errorf("%w: expected answer %s not found in %v", errUnexpectedAnswer, expected, answers)
 		}
 	}
 	return nil
 }
 
-// getCoreDNSSvcIP returns the ClusterIP of the CoreDNS service in the cluster as a DNSTarget.
-func getCoreDNSSvcIP(ctx context.Context, kubeClient kubernetes.Interface) (string, error) {
-	svc, err := kubeClient.CoreV1().Services(coreDNSNamespace).Get(ctx, coreDNSServiceName, metav1.GetOptions{})
+// answerEqual returns whether an answer matches an expected answer. IP addresses are compared by value and domain names case-insensitively,
+// with or without the trailing dot.
+func answerEqual(answer, expected string) bool {
+	if ip := net.ParseIP(expected); ip != nil {
+		return ip.Equal(net.ParseIP(answer))
+	}
+	return strings.EqualFold(dns.Fqdn(answer), dns.Fqdn(expected))
+}
+
+// serverKind describes a kind of DNS server queried by the DNSChecker and the error codes of failed queries to it.
+type serverKind struct {
+	description      string
+	timeout          string
+	nxDomain         string
+	servFail         string
+	refused          string
+	truncated        string
+	unexpectedAnswer string
+	tcpFailed        string
+	noTCPFallback    string
+	other            string
+}
+
+var (
+	coreDNSService = serverKind{
+		description:      "CoreDNS service",
+		timeout:          ErrCodeServiceTimeout,
+		nxDomain:         ErrCodeServiceNXDomain,
+		servFail:         ErrCodeServiceServFail,
+		refused:          ErrCodeServiceRefused,
+		truncated:        ErrCodeServiceTruncated,
+		unexpectedAnswer: ErrCodeServiceUnexpectedAnswer,
+		tcpFailed:        ErrCodeServiceTCPFailed,
+		noTCPFallback:    ErrCodeServiceTruncatedNoTCPFallback,
+		other:            ErrCodeServiceError,
+	}
+	coreDNSPod = serverKind{
+		description:      "CoreDNS pod",
+		timeout:          ErrCodePodTimeout,
+		nxDomain:         ErrCodePodNXDomain,
+		servFail:         ErrCodePodServFail,
+		refused:          ErrCodePodRefused,
+		truncated:        ErrCodePodTruncated,
+		unexpectedAnswer: ErrCodePodUnexpectedAnswer,
+		tcpFailed:        ErrCodePodTCPFailed,
+		noTCPFallback:    ErrCodePodTruncatedNoTCPFallback,
+		other:            ErrCodePodError,
+	}
+	localDNS = serverKind{
+		description:      "LocalDNS",
+		timeout:          ErrCodeLocalDNSTimeout,
+		nxDomain:         ErrCodeLocalDNSNXDomain,
+		servFail:         ErrCodeLocalDNSServFail,
+		refused:          ErrCodeLocalDNSRefused,
+		truncated:        ErrCodeLocalDNSTruncated,
+		unexpectedAnswer: ErrCodeLocalDNSUnexpectedAnswer,
+		tcpFailed:        ErrCodeLocalDNSTCPFailed,
+		noTCPFallback:    ErrCodeLocalDNSTruncatedNoTCPFallback,
+		other:            ErrCodeLocalDNSError,
+	}
+	customNameserver = serverKind{
+		description:      "Nameserver",
+		timeout:          ErrCodeNameserverTimeout,
+		nxDomain:         ErrCodeNameserverNXDomain,
+		servFail:         ErrCodeNameserverServFail,
+		refused:          ErrCodeNameserverRefused,
+		truncated:        ErrCodeNameserverTruncated,
+		unexpectedAnswer: ErrCodeNameserverUnexpectedAnswer,
+		tcpFailed:        ErrCodeNameserverTCPFailed,
+		noTCPFallback:    ErrCodeNameserverTruncatedNoTCPFallback,
+		other:            ErrCodeNameserverError,
+	}
+	resolvConfServer = serverKind{
+		description:      "resolv.conf nameserver",
+		timeout:          ErrCodeResolvConfTimeout,
+		nxDomain:         ErrCodeResolvConfNXDomain,
+		servFail:         ErrCodeResolvConfServFail,
+		refused:          ErrCodeResolvConfRefused,
+		truncated:        ErrCodeResolvConfTruncated,
+		unexpectedAnswer: ErrCodeResolvConfUnexpectedAnswer,
+		tcpFailed:        ErrCodeResolvConfTCPFailed,
+		noTCPFallback:    ErrCodeResolvConfTruncatedNoTCPFallback,
+		other:            ErrCodeResolvConfError,
+	}
+)
+
+// unhealthyResult returns the unhealthy result of a failed query to a DNS server of this kind, with an error code telling timeouts,
+// error rcodes, truncated responses and unexpected answers apart.
+func (k serverKind) unhealthyResult(err error) *checker.Result {
+	// The TCP errors of the UDPAndTCP protocol wrap the error of the TCP query, so they are checked first.
+	if errors.Is(err, errTCPFailed) {
+		return checker.Unhealthy(k.tcpFailed, fmt.Sprintf("%s query error: %s", k.description, err))
+	}
+	if errors.Is(err, errNoTCPFallback) {
+		return checker.Unhealthy(k.noTCPFallback, fmt.Sprintf("%s query error: %s", k.description, err))
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return checker.Unhealthy(k.timeout, fmt.Sprintf("%s query timed out", k.description))
+	}
+	code := k.other
+	var rcodeErr *rcodeError
+	switch {
+	case errors.As(err, &rcodeErr) && rcodeErr.rcode == dns.RcodeNameError:
+		code = k.nxDomain
+	case errors.As(err, &rcodeErr) && rcodeErr.rcode == dns.RcodeServerFailure:
+		code = k.servFail
+	case errors.As(err, &rcodeErr) && rcodeErr.rcode == dns.RcodeRefused:
+		code = k.refused
+	case errors.Is(err, errTruncated):
+		code = k.truncated
+	case errors.Is(err, errUnexpectedAnswer):
+		code = k.unexpectedAnswer
+	}
+	return checker.Unhealthy(code, fmt.Sprintf("%s query error: %s", k.description, err))
+}
+
+// isQueryFailure returns whether the error of a query means that the DNS server failed to answer correctly, as opposed to the query not
+// being sent or answered at all for other reasons.
+func isQueryFailure(err error) bool {
+	var rcodeErr *rcodeError
+	return errors.Is(err, context.DeadlineExceeded) || errors.As(err, &rcodeErr) || errors.Is(err, errTruncated) ||
+		errors.Is(err, errUnexpectedAnswer) || errors.Is(err, errTCPFailed) || errors.Is(err, errNoTCPFallback)
+}
+
+// getCoreDNSSvcIP returns the ClusterIP of the CoreDNS service with the given namespace and name.
+func getCoreDNSSvcIP(ctx context.Context, kubeClient kubernetes.Interface, namespace, name string) (string, error) {
+	svc, err := kubeClient.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
 
 	if err != nil && apierrors.IsNotFound(err) {
 		return "", errServiceNotReady
@@ -206,10 +708,10 @@ func getCoreDNSSvcIP(ctx context.Context, kubeClient kubernetes.Interface) (stri
 	return svc.Spec.ClusterIP, nil
 }
 
-// getCoreDNSEndpoints returns all CoreDNS pod endpoints in the cluster.
-func getCoreDNSEndpoints(ctx context.Context, kubeClient kubernetes.Interface) ([]discoveryv1.Endpoint, error) {
-	endpointSliceList, err := kubeClient.DiscoveryV1().EndpointSlices(coreDNSNamespace).List(ctx, metav1.ListOptions{
-		LabelSelector: discoveryv1.LabelServiceName + "=" + coreDNSServiceName,
+// getCoreDNSEndpoints returns all ready pod endpoints of the CoreDNS service with the given namespace and name.
+func getCoreDNSEndpoints(ctx context.Context, kubeClient kubernetes.Interface, namespace, name string) ([]discoveryv1.Endpoint, error) {
+	endpointSliceList, err := kubeClient.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + name,
 	})
 	if err != nil && apierrors.IsNotFound(err) {
 		return nil, errPodsNotReady
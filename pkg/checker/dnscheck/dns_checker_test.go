@@ -4,24 +4,36 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/Azure/cluster-health-monitor/pkg/checker"
 	"github.com/Azure/cluster-health-monitor/pkg/config"
+	"github.com/Azure/cluster-health-monitor/pkg/metrics"
+	"github.com/Azure/cluster-health-monitor/pkg/status"
+	"github.com/miekg/dns"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
 	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 type fakeResolver struct {
-	lookupHostFunc func(ctx context.Context, ip, domain string, queryTimeout time.Duration) ([]string, error)
+	queryFunc func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error)
 }
 
-func (f *fakeResolver) lookupHost(ctx context.Context, ip, domain string, queryTimeout time.Duration) ([]string, error) {
-	return f.lookupHostFunc(ctx, ip, domain, queryTimeout)
+func (f *fakeResolver) query(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
+	return f.queryFunc(ctx, ip, domain, recordType, queryTimeout)
 }
 
 func TestDNSChecker_checkLocalDNS(t *testing.T) {
@@ -36,8 +48,8 @@ func TestDNSChecker_checkLocalDNS(t *testing.T) {
 			name:   "LocalDNS Healthy",
 			client: k8sfake.NewClientset(),
 			mockResolver: &fakeResolver{
-				lookupHostFunc: func(ctx context.Context, ip, domain string, queryTimeout time.Duration) ([]string, error) {
-					if ip != "169.254.10.11" {
+				queryFunc: func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
+					if ip != "169.254.10.11:53" {
 						return nil, fmt.Errorf("unexpected IP: %s", ip)
 					}
 					return []string{"1.2.3.4"}, nil
@@ -52,8 +64,8 @@ func TestDNSChecker_checkLocalDNS(t *testing.T) {
 			name:   "LocalDNS Error",
 			client: k8sfake.NewClientset(),
 			mockResolver: &fakeResolver{
-				lookupHostFunc: func(ctx context.Context, ip, domain string, queryTimeout time.Duration) ([]string, error) {
-					if ip != "169.254.10.11" {
+				queryFunc: func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
+					if ip != "169.254.10.11:53" {
 						return []string{"1.2.3.4"}, nil
 					}
 					return nil, fmt.Errorf("local dns error")
@@ -69,8 +81,8 @@ func TestDNSChecker_checkLocalDNS(t *testing.T) {
 			name:   "LocalDNS Timeout",
 			client: k8sfake.NewClientset(),
 			mockResolver: &fakeResolver{
-				lookupHostFunc: func(ctx context.Context, ip, domain string, queryTimeout time.Duration) ([]string, error) {
-					if ip != "169.254.10.11" {
+				queryFunc: func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
+					if ip != "169.254.10.11:53" {
 						return []string{"1.2.3.4"}, nil
 					}
 					return nil, context.DeadlineExceeded
@@ -121,7 +133,7 @@ func TestDNSChecker_checkCoreDNS(t *testing.T) {
 				makeCoreDNSEndpointSlice([]string{"10.0.0.11", "10.0.0.12"}),
 			),
 			mockResolver: &fakeResolver{
-				lookupHostFunc: func(ctx context.Context, ip, domain string, queryTimeout time.Duration) ([]string, error) {
+				queryFunc: func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
 					return []string{"1.2.3.4"}, nil
 				},
 			},
@@ -134,7 +146,7 @@ func TestDNSChecker_checkCoreDNS(t *testing.T) {
 			name:   "CoreDNS Service Not Ready",
 			client: k8sfake.NewClientset(), // No service.
 			mockResolver: &fakeResolver{
-				lookupHostFunc: func(ctx context.Context, ip, domain string, queryTimeout time.Duration) ([]string, error) {
+				queryFunc: func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
 					return []string{"1.2.3.4"}, nil
 				},
 			},
@@ -150,7 +162,7 @@ func TestDNSChecker_checkCoreDNS(t *testing.T) {
 				makeCoreDNSService("10.0.0.10"),
 			),
 			mockResolver: &fakeResolver{
-				lookupHostFunc: func(ctx context.Context, ip, domain string, queryTimeout time.Duration) ([]string, error) {
+				queryFunc: func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
 					return []string{"1.2.3.4"}, nil
 				},
 			},
@@ -167,7 +179,7 @@ func TestDNSChecker_checkCoreDNS(t *testing.T) {
 				makeCoreDNSEndpointSlice([]string{"10.0.0.11"}),
 			),
 			mockResolver: &fakeResolver{
-				lookupHostFunc: func(ctx context.Context, ip, domain string, queryTimeout time.Duration) ([]string, error) {
+				queryFunc: func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
 					return nil, context.DeadlineExceeded
 				},
 			},
@@ -214,7 +226,7 @@ func TestDNSChecker_checkCoreDNSPerPod(t *testing.T) {
 				makeCoreDNSEndpointSliceWithTargetref([]string{"10.0.0.11", "10.0.0.12"}),
 			),
 			mockResolver: &fakeResolver{
-				lookupHostFunc: func(ctx context.Context, ip, domain string, queryTimeout time.Duration) ([]string, error) {
+				queryFunc: func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
 					return []string{"1.2.3.4"}, nil
 				},
 			},
@@ -223,7 +235,7 @@ func TestDNSChecker_checkCoreDNSPerPod(t *testing.T) {
 			name:   "CoreDNS Pods Not Ready",
 			client: k8sfake.NewClientset(), // No endpoint slices.
 			mockResolver: &fakeResolver{
-				lookupHostFunc: func(ctx context.Context, ip, domain string, queryTimeout time.Duration) ([]string, error) {
+				queryFunc: func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
 					return []string{"1.2.3.4"}, nil
 				},
 			},
@@ -234,7 +246,7 @@ func TestDNSChecker_checkCoreDNSPerPod(t *testing.T) {
 				makeCoreDNSEndpointSliceWithTargetref([]string{"10.0.0.11"}),
 			),
 			mockResolver: &fakeResolver{
-				lookupHostFunc: func(ctx context.Context, ip, domain string, queryTimeout time.Duration) ([]string, error) {
+				queryFunc: func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
 					return nil, context.DeadlineExceeded
 				},
 			},
@@ -245,7 +257,7 @@ func TestDNSChecker_checkCoreDNSPerPod(t *testing.T) {
 				makeCoreDNSEndpointSliceWithTargetref([]string{"10.0.0.11"}),
 			),
 			mockResolver: &fakeResolver{
-				lookupHostFunc: func(ctx context.Context, ip, domain string, queryTimeout time.Duration) ([]string, error) {
+				queryFunc: func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
 					return nil, errors.New("some query error")
 				},
 			},
@@ -257,7 +269,7 @@ func TestDNSChecker_checkCoreDNSPerPod(t *testing.T) {
 				makeCoreDNSEndpointSliceWithTargetref([]string{"10.0.0.12"}),
 			),
 			mockResolver: &fakeResolver{
-				lookupHostFunc: func(ctx context.Context, ip, domain string, queryTimeout time.Duration) ([]string, error) {
+				queryFunc: func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
 					return []string{"1.2.3.4"}, nil
 				},
 			},
@@ -284,13 +296,671 @@ func TestDNSChecker_checkCoreDNSPerPod(t *testing.T) {
 	}
 }
 
+func TestDNSChecker_checkCoreDNSPerPod_DiscoveryFailures(t *testing.T) {
+	t.Parallel()
+	listErrorClient := k8sfake.NewClientset()
+	listErrorClient.PrependReactor("list", "endpointslices", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+
+	testCases := []struct {
+		name              string
+		client            *k8sfake.Clientset
+		expectedStatus    string
+		expectedCode      string
+		expectedRunErrors map[string]float64
+	}{
+		{
+			name:              "Endpoints cannot be listed",
+			client:            listErrorClient,
+			expectedStatus:    metrics.UnknownStatus,
+			expectedCode:      metrics.UnknownCode,
+			expectedRunErrors: map[string]float64{RunErrorEndpointDiscovery: 1},
+		},
+		{
+			name:           "CoreDNS Pods Not Ready",
+			client:         k8sfake.NewClientset(),
+			expectedStatus: string(checker.StatusUnhealthy),
+			expectedCode:   ErrCodePodsNotReady,
+		},
+		{
+			name: "Endpoint without targetRef",
+			client: k8sfake.NewClientset(
+				makeCoreDNSEndpointSlice([]string{"10.0.0.11"}),
+				makeCoreDNSEndpointSliceWithTargetref([]string{"10.0.0.12"}),
+			),
+			expectedStatus:    metrics.UnknownStatus,
+			expectedCode:      metrics.UnknownCode,
+			expectedRunErrors: map[string]float64{RunErrorMissingTargetRef: 1},
+		},
+		{
+			name:              "No endpoint with targetRef",
+			client:            k8sfake.NewClientset(makeCoreDNSEndpointSlice([]string{"10.0.0.11", "10.0.0.12"})),
+			expectedStatus:    metrics.UnknownStatus,
+			expectedCode:      metrics.UnknownCode,
+			expectedRunErrors: map[string]float64{RunErrorMissingTargetRef: 2},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			name := fmt.Sprintf("dns-discovery-test-%d", i)
+			chk := &DNSChecker{
+				name: name,
+				config: &config.DNSConfig{
+					Domain:       "example.com",
+					Target:       config.DNSCheckTargetCoreDNSPerPod,
+					QueryTimeout: 2 * time.Second,
+				},
+				kubeClient: tc.client,
+				resolver: &fakeResolver{
+					queryFunc: func(ctx context.Context, address, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
+						return []string{"1.2.3.4"}, nil
+					},
+				},
+			}
+			runErrors := func(reason string) float64 {
+				return testutil.ToFloat64(metrics.CheckerRunErrorCounter.WithLabelValues(string(config.CheckTypeDNS), name, reason))
+			}
+			before := map[string]float64{
+				RunErrorEndpointDiscovery: runErrors(RunErrorEndpointDiscovery),
+				RunErrorMissingTargetRef:  runErrors(RunErrorMissingTargetRef),
+			}
+
+			chk.Run(context.Background())
+
+			st, ok := status.DefaultStore.Get(name)
+			g.Expect(ok).To(BeTrue())
+			g.Expect(st.LastResult.Pod).To(BeEmpty())
+			g.Expect(st.LastResult.Status).To(Equal(tc.expectedStatus))
+			g.Expect(st.LastResult.Code).To(Equal(tc.expectedCode))
+			for reason, count := range before {
+				g.Expect(runErrors(reason) - count).To(Equal(tc.expectedRunErrors[reason]))
+			}
+		})
+	}
+}
+
+func TestDNSChecker_checkCoreDNSPerPod_OverallResult(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	name := "dns-per-pod-overall-test"
+	chk := &DNSChecker{
+		name: name,
+		config: &config.DNSConfig{
+			Domain:       "example.com",
+			Target:       config.DNSCheckTargetCoreDNSPerPod,
+			QueryTimeout: 2 * time.Second,
+		},
+		kubeClient: k8sfake.NewClientset(makeCoreDNSEndpointSliceWithTargetref([]string{"10.0.0.11", "10.0.0.12"})),
+		resolver: &fakeResolver{
+			queryFunc: func(ctx context.Context, address, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
+				if strings.HasPrefix(address, "10.0.0.11:") {
+					return nil, context.DeadlineExceeded
+				}
+				return []string{"1.2.3.4"}, nil
+			},
+		},
+	}
+
+	status.DefaultStore.Delete(name)
+	chk.Run(context.Background())
+
+	st, ok := status.DefaultStore.Get(name)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(st.LastResult.Pod).To(BeEmpty())
+	g.Expect(st.LastResult.Status).To(Equal(string(checker.StatusUnhealthy)))
+	g.Expect(st.LastResult.Code).To(Equal(ErrCodePodTimeout))
+	g.Expect(st.LastResult.Message).To(ContainSubstring("kube-system/coredns-0"))
+	g.Expect(st.LastResult.Message).ToNot(ContainSubstring("kube-system/coredns-1"))
+	podStatuses := make(map[string]string)
+	for _, result := range st.RecentResults {
+		if result.Pod != "" {
+			podStatuses[result.Pod] = result.Status
+		}
+	}
+	g.Expect(podStatuses).To(Equal(map[string]string{
+		"kube-system/coredns-0": string(checker.StatusUnhealthy),
+		"kube-system/coredns-1": string(checker.StatusHealthy),
+	}))
+}
+
+func TestDNSChecker_checkCustom(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	var queried []string
+	chk := &DNSChecker{
+		name: "dns-custom-test",
+		config: &config.DNSConfig{
+			Domain:       "example.com",
+			Target:       config.DNSCheckTargetCustom,
+			QueryTimeout: 2 * time.Second,
+		},
+		nameservers: []string{"168.63.129.16:53", "10.0.0.53:5353", "10.0.0.54:53"},
+		resolver: &fakeResolver{
+			queryFunc: func(ctx context.Context, address, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
+				queried = append(queried, address)
+				switch address {
+				case "10.0.0.53:5353":
+					return nil, &rcodeError{rcode: dns.RcodeServerFailure}
+				case "10.0.0.54:53":
+					return nil, errors.New("network unreachable")
+				}
+				return []string{"1.2.3.4"}, nil
+			},
+		},
+	}
+
+	status.DefaultStore.Delete("dns-custom-test")
+	chk.Run(context.Background())
+	g.Expect(queried).To(Equal([]string{"168.63.129.16:53", "10.0.0.53:5353", "10.0.0.54:53"}))
+
+	st, ok := status.DefaultStore.Get("dns-custom-test")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(st.RecentResults).To(HaveLen(4))
+	g.Expect(st.RecentResults[0].Nameserver).To(Equal("168.63.129.16:53"))
+	g.Expect(st.RecentResults[0].Status).To(Equal(string(checker.StatusHealthy)))
+	g.Expect(st.RecentResults[1].Nameserver).To(Equal("10.0.0.53:5353"))
+	g.Expect(st.RecentResults[1].Status).To(Equal(string(checker.StatusUnhealthy)))
+	g.Expect(st.RecentResults[1].Code).To(Equal(ErrCodeNameserverServFail))
+	g.Expect(st.RecentResults[2].Nameserver).To(Equal("10.0.0.54:53"))
+	g.Expect(st.RecentResults[2].Status).To(Equal(metrics.UnknownStatus))
+	g.Expect(st.RecentResults[3].Nameserver).To(BeEmpty())
+	g.Expect(st.RecentResults[3].Status).To(Equal(string(checker.StatusUnhealthy)))
+	g.Expect(st.RecentResults[3].Code).To(Equal(ErrCodeNameserverServFail))
+}
+
+func TestDNSChecker_checkCustom_OverallResult(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name            string
+		errs            map[string]error
+		expectedStatus  string
+		expectedCode    string
+		expectedMessage string
+	}{
+		{
+			name:           "All nameservers healthy",
+			expectedStatus: string(checker.StatusHealthy),
+		},
+		{
+			name:            "One nameserver timed out",
+			errs:            map[string]error{"10.0.0.54:53": context.DeadlineExceeded},
+			expectedStatus:  string(checker.StatusUnhealthy),
+			expectedCode:    ErrCodeNameserverTimeout,
+			expectedMessage: "Nameservers failed: 10.0.0.54:53: Nameserver query timed out",
+		},
+		{
+			name: "Nameservers failed differently",
+			errs: map[string]error{
+				"10.0.0.53:53": &rcodeError{rcode: dns.RcodeRefused},
+				"10.0.0.54:53": context.DeadlineExceeded,
+			},
+			expectedStatus: string(checker.StatusUnhealthy),
+			expectedCode:   ErrCodeNameserversFailed,
+		},
+		{
+			name:           "Nameserver not queried",
+			errs:           map[string]error{"10.0.0.54:53": errors.New("network unreachable")},
+			expectedStatus: metrics.UnknownStatus,
+			expectedCode:   metrics.UnknownCode,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			name := fmt.Sprintf("dns-custom-overall-test-%d", i)
+			chk := &DNSChecker{
+				name: name,
+				config: &config.DNSConfig{
+					Domain:       "example.com",
+					Target:       config.DNSCheckTargetCustom,
+					QueryTimeout: 2 * time.Second,
+				},
+				nameservers: []string{"10.0.0.53:53", "10.0.0.54:53"},
+				resolver: &fakeResolver{
+					queryFunc: func(ctx context.Context, address, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
+						if err := tc.errs[address]; err != nil {
+							return nil, err
+						}
+						return []string{"1.2.3.4"}, nil
+					},
+				},
+			}
+
+			status.DefaultStore.Delete(name)
+			chk.Run(context.Background())
+
+			st, ok := status.DefaultStore.Get(name)
+			g.Expect(ok).To(BeTrue())
+			g.Expect(st.LastResult.Nameserver).To(BeEmpty())
+			g.Expect(st.LastResult.Status).To(Equal(tc.expectedStatus))
+			g.Expect(st.LastResult.Code).To(Equal(tc.expectedCode))
+			g.Expect(st.LastResult.Message).To(ContainSubstring(tc.expectedMessage))
+		})
+	}
+}
+
+func TestDNSChecker_CustomCoreDNSService(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	var queried []string
+	chk := &DNSChecker{
+		name: "dns-test",
+		config: &config.DNSConfig{
+			Domain:           "example.com",
+			Target:           config.DNSCheckTargetCoreDNS,
+			QueryTimeout:     2 * time.Second,
+			ServiceNamespace: "dns-system",
+			ServiceName:      "coredns",
+		},
+		kubeClient: k8sfake.NewClientset(
+			// The default service must not be queried.
+			makeCoreDNSService("10.0.0.10"),
+			&corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "dns-system", Name: "coredns"},
+				Spec:       corev1.ServiceSpec{ClusterIP: "10.0.0.20"},
+			},
+			&discoveryv1.EndpointSlice{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "dns-system",
+					Labels:    map[string]string{discoveryv1.LabelServiceName: "coredns"},
+				},
+				Endpoints: []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.21"}}},
+			},
+		),
+		resolver: &fakeResolver{
+			queryFunc: func(ctx context.Context, address, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
+				queried = append(queried, address)
+				return []string{"1.2.3.4"}, nil
+			},
+		},
+	}
+
+	res, err := chk.checkCoreDNS(context.Background())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(res.Status).To(Equal(checker.StatusHealthy))
+	g.Expect(queried).To(Equal([]string{"10.0.0.20:53", "10.0.0.21:53"}))
+}
+
+func TestBuildDNSChecker_Nameservers(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+
+	chk, err := BuildDNSChecker(&config.CheckerConfig{
+		Name: "dns-custom",
+		Type: config.CheckTypeDNS,
+		DNSConfig: &config.DNSConfig{
+			Domain:       "example.com",
+			Target:       config.DNSCheckTargetCustom,
+			QueryTimeout: time.Second,
+			Nameservers:  []string{"168.63.129.16", "10.0.0.53:5353", "fd00::10"},
+		},
+	}, k8sfake.NewClientset(), nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(chk.(*DNSChecker).nameservers).To(Equal([]string{"168.63.129.16:53", "10.0.0.53:5353", "[fd00::10]:53"}))
+}
+
+func TestBuildDNSChecker_Protocol(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name                string
+		protocol            config.DNSProtocol
+		ednsBufferSize      int
+		expectedResolver    *defaultResolver
+		expectedTCPResolver bool
+	}{
+		{
+			name:             "Default retries truncated responses over TCP",
+			expectedResolver: &defaultResolver{network: "udp", tcpFallback: true},
+		},
+		{
+			name:             "UDP retries truncated responses over TCP",
+			protocol:         config.DNSProtocolUDP,
+			expectedResolver: &defaultResolver{network: "udp", tcpFallback: true},
+		},
+		{
+			name:             "UDP with EDNS0 reports truncated responses",
+			protocol:         config.DNSProtocolUDP,
+			ednsBufferSize:   1232,
+			expectedResolver: &defaultResolver{network: "udp", ednsBufferSize: 1232},
+		},
+		{
+			name:             "UDPOnly reports truncated responses",
+			protocol:         config.DNSProtocolUDPOnly,
+			expectedResolver: &defaultResolver{network: "udp"},
+		},
+		{
+			name:             "TCP",
+			protocol:         config.DNSProtocolTCP,
+			expectedResolver: &defaultResolver{network: "tcp"},
+		},
+		{
+			name:                "UDPAndTCP",
+			protocol:            config.DNSProtocolUDPAndTCP,
+			expectedResolver:    &defaultResolver{network: "udp"},
+			expectedTCPResolver: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			chk, err := BuildDNSChecker(&config.CheckerConfig{
+				Name: "dns-custom",
+				Type: config.CheckTypeDNS,
+				DNSConfig: &config.DNSConfig{
+					Domain:          "example.com",
+					Target:          config.DNSCheckTargetCustom,
+					QueryTimeout:    time.Second,
+					Nameservers:     []string{"10.0.0.53"},
+					Protocol:        tc.protocol,
+					EDNS0BufferSize: tc.ednsBufferSize,
+				},
+			}, k8sfake.NewClientset(), nil)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(chk.(*DNSChecker).resolver).To(Equal(tc.expectedResolver))
+			g.Expect(chk.(*DNSChecker).tcpResolver != nil).To(Equal(tc.expectedTCPResolver))
+		})
+	}
+}
+
+func TestDNSChecker_AnswerConsistency(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name       string
+		podAnswers map[string][]string
+		// timedOutPods are the IPs of the pods whose queries time out.
+		timedOutPods    []string
+		expectedStatus  checker.Status
+		expectedCode    string
+		unhealthyPods   []string
+		expectedMessage string
+	}{
+		{
+			name: "Same answers in different order and case",
+			podAnswers: map[string][]string{
+				"10.0.0.11": {"1.2.3.4", "5.6.7.8"},
+				"10.0.0.12": {"5.6.7.8", "1.2.3.4", "1.2.3.4"},
+			},
+			expectedStatus: checker.StatusHealthy,
+		},
+		{
+			name: "One stale pod",
+			podAnswers: map[string][]string{
+				"10.0.0.11": {"1.2.3.4"},
+				"10.0.0.12": {"1.2.3.4"},
+				"10.0.0.13": {"9.9.9.9"},
+			},
+			expectedStatus:  checker.StatusUnhealthy,
+			expectedCode:    ErrCodeInconsistentAnswers,
+			unhealthyPods:   []string{"coredns-2"},
+			expectedMessage: "kube-system/coredns-2 answered [9.9.9.9]",
+		},
+		{
+			name: "No majority",
+			podAnswers: map[string][]string{
+				"10.0.0.11": {"1.2.3.4"},
+				"10.0.0.12": {"9.9.9.9"},
+			},
+			expectedStatus: checker.StatusUnhealthy,
+			expectedCode:   ErrCodeInconsistentAnswers,
+			unhealthyPods:  []string{"coredns-0", "coredns-1"},
+		},
+		{
+			name: "Consistent answers and a timed out pod",
+			podAnswers: map[string][]string{
+				"10.0.0.11": {"1.2.3.4"},
+				"10.0.0.12": {"1.2.3.4"},
+			},
+			timedOutPods:    []string{"10.0.0.13"},
+			expectedStatus:  checker.StatusUnhealthy,
+			expectedCode:    ErrCodePodTimeout,
+			unhealthyPods:   []string{"coredns-2"},
+			expectedMessage: "kube-system/coredns-2: CoreDNS pod query timed out",
+		},
+		{
+			name: "Stale and timed out pods",
+			podAnswers: map[string][]string{
+				"10.0.0.11": {"1.2.3.4"},
+				"10.0.0.12": {"1.2.3.4"},
+				"10.0.0.14": {"9.9.9.9"},
+			},
+			timedOutPods:    []string{"10.0.0.13"},
+			expectedStatus:  checker.StatusUnhealthy,
+			expectedCode:    ErrCodePodsFailed,
+			unhealthyPods:   []string{"coredns-2", "coredns-3"},
+			expectedMessage: "kube-system/coredns-2: CoreDNS pod query timed out; kube-system/coredns-3 answered [9.9.9.9]",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			name := fmt.Sprintf("dns-consistency-test-%d", i)
+			ips := append(slices.Collect(maps.Keys(tc.podAnswers)), tc.timedOutPods...)
+			slices.Sort(ips)
+			chk := &DNSChecker{
+				name: name,
+				config: &config.DNSConfig{
+					Domain:                 "example.com",
+					Target:                 config.DNSCheckTargetCoreDNSPerPod,
+					QueryTimeout:           2 * time.Second,
+					CheckAnswerConsistency: true,
+				},
+				kubeClient: k8sfake.NewClientset(makeCoreDNSEndpointSliceWithTargetref(ips)),
+				resolver: &fakeResolver{
+					queryFunc: func(ctx context.Context, address, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
+						host, _, _ := net.SplitHostPort(address)
+						if slices.Contains(tc.timedOutPods, host) {
+							return nil, context.DeadlineExceeded
+						}
+						return tc.podAnswers[host], nil
+					},
+				},
+			}
+
+			status.DefaultStore.Delete(name)
+			chk.Run(context.Background())
+
+			st, ok := status.DefaultStore.Get(name)
+			g.Expect(ok).To(BeTrue())
+			g.Expect(st.LastResult.Pod).To(BeEmpty())
+			g.Expect(st.LastResult.Status).To(Equal(string(tc.expectedStatus)))
+			g.Expect(st.LastResult.Code).To(Equal(tc.expectedCode))
+			g.Expect(st.LastResult.Message).To(ContainSubstring(tc.expectedMessage))
+			var unhealthyPods []string
+			for _, result := range st.RecentResults {
+				if result.Pod != "" && result.Status == string(checker.StatusUnhealthy) {
+					if result.Code != ErrCodePodTimeout {
+						g.Expect(result.Code).To(Equal(ErrCodeInconsistentAnswers))
+					}
+					unhealthyPods = append(unhealthyPods, strings.TrimPrefix(result.Pod, defaultCoreDNSNamespace+"/"))
+				}
+			}
+			g.Expect(unhealthyPods).To(Equal(tc.unhealthyPods))
+		})
+	}
+}
+
+func TestNormalizeAnswers(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	g.Expect(normalizeAnswers([]string{"Example.COM", "10.0.0.2", "example.com.", "10.0.0.1", "::FFFF:10.0.0.1"})).To(Equal(
+		[]string{"10.0.0.1", "10.0.0.2", "example.com."}))
+}
+
+func TestDNSChecker_checkResolvConf(t *testing.T) {
+	t.Parallel()
+	resolvConfPath := filepath.Join(t.TempDir(), "resolv.conf")
+	err := os.WriteFile(resolvConfPath, []byte(`nameserver 10.0.0.10
+nameserver 10.0.0.20
+search default.svc.cluster.local svc.cluster.local cluster.local
+options ndots:5
+`), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write resolv.conf: %v", err)
+	}
+
+	nxDomain := &rcodeError{rcode: dns.RcodeNameError}
+	testCases := []struct {
+		name            string
+		domain          string
+		responses       map[string]error
+		answered        []string
+		expectedQueries []string
+		expectedCode    string
+		expectedMessage string
+	}{
+		{
+			name:   "Short name answered through the search list",
+			domain: "kubernetes.default",
+			responses: map[string]error{
+				"10.0.0.10:53/kubernetes.default.default.svc.cluster.local.": nxDomain,
+			},
+			answered: []string{"kubernetes.default.svc.cluster.local."},
+			expectedQueries: []string{
+				"10.0.0.10:53/kubernetes.default.default.svc.cluster.local.",
+				"10.0.0.10:53/kubernetes.default.svc.cluster.local.",
+			},
+			expectedMessage: "resolved kubernetes.default as kubernetes.default.svc.cluster.local. after 2 queries",
+		},
+		{
+			name:   "External name tries all search domains first",
+			domain: "mcr.microsoft.com",
+			responses: map[string]error{
+				"10.0.0.10:53/mcr.microsoft.com.default.svc.cluster.local.": nxDomain,
+				"10.0.0.10:53/mcr.microsoft.com.svc.cluster.local.":         nxDomain,
+				"10.0.0.10:53/mcr.microsoft.com.cluster.local.":             nxDomain,
+			},
+			answered: []string{"mcr.microsoft.com."},
+			expectedQueries: []string{
+				"10.0.0.10:53/mcr.microsoft.com.default.svc.cluster.local.",
+				"10.0.0.10:53/mcr.microsoft.com.svc.cluster.local.",
+				"10.0.0.10:53/mcr.microsoft.com.cluster.local.",
+				"10.0.0.10:53/mcr.microsoft.com.",
+			},
+			expectedMessage: "after 4 queries",
+		},
+		{
+			name:            "Fully qualified name is queried as is",
+			domain:          "mcr.microsoft.com.",
+			answered:        []string{"mcr.microsoft.com."},
+			expectedQueries: []string{"10.0.0.10:53/mcr.microsoft.com."},
+		},
+		{
+			name:            "Name with ndots dots is queried as is first",
+			domain:          "a.b.c.d.e.example.com",
+			answered:        []string{"a.b.c.d.e.example.com."},
+			expectedQueries: []string{"10.0.0.10:53/a.b.c.d.e.example.com."},
+		},
+		{
+			name:   "Next nameserver after a timeout",
+			domain: "kubernetes.default.svc.cluster.local.",
+			responses: map[string]error{
+				"10.0.0.10:53/kubernetes.default.svc.cluster.local.": context.DeadlineExceeded,
+			},
+			answered: []string{"kubernetes.default.svc.cluster.local."},
+			expectedQueries: []string{
+				"10.0.0.10:53/kubernetes.default.svc.cluster.local.",
+				"10.0.0.20:53/kubernetes.default.svc.cluster.local.",
+			},
+		},
+		{
+			name:   "Timeout of all nameservers stops the search",
+			domain: "kubernetes.default",
+			responses: map[string]error{
+				"10.0.0.10:53/kubernetes.default.default.svc.cluster.local.": context.DeadlineExceeded,
+				"10.0.0.20:53/kubernetes.default.default.svc.cluster.local.": context.DeadlineExceeded,
+			},
+			expectedQueries: []string{
+				"10.0.0.10:53/kubernetes.default.default.svc.cluster.local.",
+				"10.0.0.20:53/kubernetes.default.default.svc.cluster.local.",
+			},
+			expectedCode: ErrCodeResolvConfTimeout,
+		},
+		{
+			name:   "Name not found",
+			domain: "missing.example.com.",
+			responses: map[string]error{
+				"10.0.0.10:53/missing.example.com.": nxDomain,
+			},
+			expectedQueries: []string{"10.0.0.10:53/missing.example.com."},
+			expectedCode:    ErrCodeResolvConfNXDomain,
+			expectedMessage: "DNS server responded with rcode NXDOMAIN after 1 queries",
+		},
+		{
+			name:   "Empty answers and SERVFAIL go on with the next name",
+			domain: "kubernetes.default",
+			responses: map[string]error{
+				"10.0.0.10:53/kubernetes.default.svc.cluster.local.": &rcodeError{rcode: dns.RcodeServerFailure},
+			},
+			answered: []string{"kubernetes.default.cluster.local."},
+			expectedQueries: []string{
+				"10.0.0.10:53/kubernetes.default.default.svc.cluster.local.",
+				"10.0.0.10:53/kubernetes.default.svc.cluster.local.",
+				"10.0.0.10:53/kubernetes.default.cluster.local.",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			var queries []string
+			chk := &DNSChecker{
+				name: "dns-test",
+				config: &config.DNSConfig{
+					Domain:       tc.domain,
+					Target:       config.DNSCheckTargetResolvConf,
+					QueryTimeout: time.Second,
+				},
+				resolvConfPath: resolvConfPath,
+				resolver: &fakeResolver{
+					queryFunc: func(ctx context.Context, address, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
+						queries = append(queries, address+"/"+domain)
+						if err, ok := tc.responses[address+"/"+domain]; ok {
+							return nil, err
+						}
+						if slices.Contains(tc.answered, domain) {
+							return []string{"1.2.3.4"}, nil
+						}
+						// No records of the type.
+						return nil, nil
+					},
+				},
+			}
+
+			res, err := chk.checkResolvConf(context.Background())
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(queries).To(Equal(tc.expectedQueries))
+			if tc.expectedCode == "" {
+				g.Expect(res.Status).To(Equal(checker.StatusHealthy))
+			} else {
+				g.Expect(res.Status).To(Equal(checker.StatusUnhealthy))
+				g.Expect(res.Detail.Code).To(Equal(tc.expectedCode))
+			}
+			g.Expect(res.Detail.Message).To(ContainSubstring(tc.expectedMessage))
+		})
+	}
+}
+
 func TestDNSChecker_QueryTimeoutUsedByResolver(t *testing.T) {
 	t.Parallel()
 	g := NewWithT(t)
 
 	var capturedTimeout time.Duration
 	mockResolver := &fakeResolver{
-		lookupHostFunc: func(ctx context.Context, ip, domain string, queryTimeout time.Duration) ([]string, error) {
+		queryFunc: func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
 			capturedTimeout = queryTimeout
 			return []string{"1.2.3.4"}, nil
 		},
@@ -312,13 +982,238 @@ func TestDNSChecker_QueryTimeoutUsedByResolver(t *testing.T) {
 	g.Expect(capturedTimeout).To(Equal(5 * time.Second))
 }
 
+func TestDNSChecker_QueryErrorCodes(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name         string
+		serviceErr   error
+		podErr       error
+		expectedCode string
+	}{
+		{name: "Service NXDOMAIN", serviceErr: &rcodeError{rcode: dns.RcodeNameError}, expectedCode: ErrCodeServiceNXDomain},
+		{name: "Service SERVFAIL", serviceErr: &rcodeError{rcode: dns.RcodeServerFailure}, expectedCode: ErrCodeServiceServFail},
+		{name: "Service REFUSED", serviceErr: &rcodeError{rcode: dns.RcodeRefused}, expectedCode: ErrCodeServiceRefused},
+		{name: "Service other rcode", serviceErr: &rcodeError{rcode: dns.RcodeNotImplemented}, expectedCode: ErrCodeServiceError},
+		{name: "Service truncated", serviceErr: errTruncated, expectedCode: ErrCodeServiceTruncated},
+		{name: "Service timeout", serviceErr: fmt.Errorf("%w: i/o timeout", context.DeadlineExceeded), expectedCode: ErrCodeServiceTimeout},
+		{name: "Service unreachable", serviceErr: errors.New("connection refused"), expectedCode: ErrCodeServiceError},
+		{name: "Pod NXDOMAIN", podErr: &rcodeError{rcode: dns.RcodeNameError}, expectedCode: ErrCodePodNXDomain},
+		{name: "Pod SERVFAIL", podErr: &rcodeError{rcode: dns.RcodeServerFailure}, expectedCode: ErrCodePodServFail},
+		{name: "Pod truncated", podErr: errTruncated, expectedCode: ErrCodePodTruncated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			chk := &DNSChecker{
+				name:       "dns-test",
+				config:     &config.DNSConfig{Domain: "example.com", Target: config.DNSCheckTargetCoreDNS, QueryTimeout: 2 * time.Second},
+				kubeClient: k8sfake.NewClientset(makeCoreDNSService("10.0.0.10"), makeCoreDNSEndpointSlice([]string{"10.0.0.11"})),
+				resolver: &fakeResolver{
+					queryFunc: func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
+						if ip == "10.0.0.10:53" && tc.serviceErr != nil {
+							return nil, tc.serviceErr
+						}
+						if ip == "10.0.0.11:53" && tc.podErr != nil {
+							return nil, tc.podErr
+						}
+						return []string{"1.2.3.4"}, nil
+					},
+				},
+			}
+
+			res, err := chk.checkCoreDNS(context.Background())
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(res.Status).To(Equal(checker.StatusUnhealthy))
+			g.Expect(res.Detail.Code).To(Equal(tc.expectedCode))
+		})
+	}
+}
+
+func TestDNSChecker_UDPAndTCP(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name         string
+		udpErr       error
+		tcpErr       error
+		tcpAnswers   []string
+		expectedCode string
+	}{
+		{name: "UDP and TCP succeed", tcpAnswers: []string{"1.2.3.4"}},
+		{name: "Truncated UDP response retried over TCP", udpErr: errTruncated, tcpAnswers: []string{"1.2.3.4"}},
+		{name: "UDP succeeds and TCP times out", tcpErr: context.DeadlineExceeded, expectedCode: ErrCodeLocalDNSTCPFailed},
+		{name: "UDP succeeds and TCP is refused", tcpErr: errors.New("connection refused"), expectedCode: ErrCodeLocalDNSTCPFailed},
+		{name: "UDP succeeds and TCP has no answers", expectedCode: ErrCodeLocalDNSTCPFailed},
+		{name: "Truncated UDP response and TCP fails", udpErr: errTruncated, tcpErr: errors.New("connection refused"),
+			expectedCode: ErrCodeLocalDNSTruncatedNoTCPFallback},
+		{name: "UDP fails", udpErr: &rcodeError{rcode: dns.RcodeServerFailure}, expectedCode: ErrCodeLocalDNSServFail},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			chk := &DNSChecker{
+				name: "dns-test",
+				config: &config.DNSConfig{
+					Domain:       "example.com",
+					Target:       config.DNSCheckTargetLocalDNS,
+					QueryTimeout: time.Second,
+					Protocol:     config.DNSProtocolUDPAndTCP,
+				},
+				resolver: &fakeResolver{
+					queryFunc: func(ctx context.Context, address, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
+						if tc.udpErr != nil {
+							return nil, tc.udpErr
+						}
+						return []string{"1.2.3.4"}, nil
+					},
+				},
+				tcpResolver: &fakeResolver{
+					queryFunc: func(ctx context.Context, address, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
+						return tc.tcpAnswers, tc.tcpErr
+					},
+				},
+			}
+
+			res, err := chk.checkLocalDNS(context.Background())
+			g.Expect(err).ToNot(HaveOccurred())
+			if tc.expectedCode == "" {
+				g.Expect(res.Status).To(Equal(checker.StatusHealthy))
+			} else {
+				g.Expect(res.Status).To(Equal(checker.StatusUnhealthy))
+				g.Expect(res.Detail.Code).To(Equal(tc.expectedCode))
+			}
+		})
+	}
+}
+
+func TestDNSChecker_Answers(t *testing.T) {
+	t.Parallel()
+	testCases := []struct {
+		name         string
+		config       config.DNSConfig
+		answers      []string
+		expectedType uint16
+		expectedName string
+		expectedCode string
+	}{
+		{
+			name:         "Default record type requires an answer",
+			config:       config.DNSConfig{Domain: "example.com"},
+			answers:      nil,
+			expectedType: dns.TypeA,
+			expectedName: "example.com",
+			expectedCode: ErrCodeLocalDNSUnexpectedAnswer,
+		},
+		{
+			name:         "Expected A answers present",
+			config:       config.DNSConfig{Domain: "example.com", ExpectedAnswers: []string{"1.2.3.4"}, MinAnswers: 2},
+			answers:      []string{"5.6.7.8", "1.2.3.4"},
+			expectedType: dns.TypeA,
+			expectedName: "example.com",
+		},
+		{
+			name:         "Too few answers",
+			config:       config.DNSConfig{Domain: "example.com", MinAnswers: 2},
+			answers:      []string{"1.2.3.4"},
+			expectedType: dns.TypeA,
+			expectedName: "example.com",
+			expectedCode: ErrCodeLocalDNSUnexpectedAnswer,
+		},
+		{
+			name:         "Expected AAAA answer missing",
+			config:       config.DNSConfig{Domain: "example.com", RecordType: config.DNSRecordTypeAAAA, ExpectedAnswers: []string{"::1"}},
+			answers:      []string{"::2"},
+			expectedType: dns.TypeAAAA,
+			expectedName: "example.com",
+			expectedCode: ErrCodeLocalDNSUnexpectedAnswer,
+		},
+		{
+			name: "SRV targets are compared case-insensitively",
+			config: config.DNSConfig{
+				Domain:          "_dns._udp.kube-dns.kube-system.svc.cluster.local",
+				RecordType:      config.DNSRecordTypeSRV,
+				ExpectedAnswers: []string{"KUBE-DNS.kube-system.svc.cluster.local"},
+			},
+			answers:      []string{"kube-dns.kube-system.svc.cluster.local."},
+			expectedType: dns.TypeSRV,
+			expectedName: "_dns._udp.kube-dns.kube-system.svc.cluster.local",
+		},
+		{
+			name:         "PTR query of an IP address",
+			config:       config.DNSConfig{Domain: "10.0.0.10", RecordType: config.DNSRecordTypePTR},
+			answers:      []string{"kube-dns.kube-system.svc.cluster.local."},
+			expectedType: dns.TypePTR,
+			expectedName: "10.0.0.10.in-addr.arpa.",
+		},
+		{
+			name:         "CNAME query",
+			config:       config.DNSConfig{Domain: "www.example.com", RecordType: config.DNSRecordTypeCNAME, ExpectedAnswers: []string{"example.com"}},
+			answers:      []string{"example.com."},
+			expectedType: dns.TypeCNAME,
+			expectedName: "www.example.com",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			cfg := tc.config
+			cfg.Target = config.DNSCheckTargetLocalDNS
+			cfg.QueryTimeout = time.Second
+			chk := &DNSChecker{
+				name:   "dns-test",
+				config: &cfg,
+				resolver: &fakeResolver{
+					queryFunc: func(ctx context.Context, ip, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
+						g.Expect(domain).To(Equal(tc.expectedName))
+						g.Expect(recordType).To(Equal(tc.expectedType))
+						return tc.answers, nil
+					},
+				},
+			}
+
+			res, err := chk.checkLocalDNS(context.Background())
+			g.Expect(err).ToNot(HaveOccurred())
+			if tc.expectedCode == "" {
+				g.Expect(res.Status).To(Equal(checker.StatusHealthy))
+			} else {
+				g.Expect(res.Status).To(Equal(checker.StatusUnhealthy))
+				g.Expect(res.Detail.Code).To(Equal(tc.expectedCode))
+			}
+		})
+	}
+}
+
+func TestAnswers(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	resp := new(dns.Msg)
+	for _, record := range []string{
+		"www.example.com. 60 IN CNAME example.com.",
+		"example.com. 60 IN A 1.2.3.4",
+		"example.com. 60 IN A 5.6.7.8",
+	} {
+		rr, err := dns.NewRR(record)
+		g.Expect(err).ToNot(HaveOccurred())
+		resp.Answer = append(resp.Answer, rr)
+	}
+
+	g.Expect(answers(resp, dns.TypeA)).To(Equal([]string{"1.2.3.4", "5.6.7.8"}))
+	g.Expect(answers(resp, dns.TypeCNAME)).To(Equal([]string{"example.com."}))
+	g.Expect(answers(resp, dns.TypeAAAA)).To(BeEmpty())
+}
+
 // --- helpers ---
 
 func makeCoreDNSService(ip string) *corev1.Service {
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: coreDNSNamespace,
-			Name:      coreDNSServiceName,
+			Namespace: defaultCoreDNSNamespace,
+			Name:      defaultCoreDNSServiceName,
 		},
 		Spec: corev1.ServiceSpec{
 			ClusterIP: ip,
@@ -339,9 +1234,9 @@ func makeCoreDNSEndpointSlice(ips []string) *discoveryv1.EndpointSlice {
 	}
 	return &discoveryv1.EndpointSlice{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: coreDNSNamespace,
+			Namespace: defaultCoreDNSNamespace,
 			Labels: map[string]string{
-				discoveryv1.LabelServiceName: coreDNSServiceName,
+				discoveryv1.LabelServiceName: defaultCoreDNSServiceName,
 			},
 		},
 		Endpoints: endpoints,
@@ -360,16 +1255,16 @@ func makeCoreDNSEndpointSliceWithTargetref(ips []string) *discoveryv1.EndpointSl
 			},
 			TargetRef: &corev1.ObjectReference{
 				Kind:      "Pod",
-				Namespace: coreDNSNamespace,
+				Namespace: defaultCoreDNSNamespace,
 				Name:      podName,
 			},
 		})
 	}
 	return &discoveryv1.EndpointSlice{
 		ObjectMeta: metav1.ObjectMeta{
-			Namespace: coreDNSNamespace,
+			Namespace: defaultCoreDNSNamespace,
 			Labels: map[string]string{
-				discoveryv1.LabelServiceName: coreDNSServiceName,
+				discoveryv1.LabelServiceName: defaultCoreDNSServiceName,
 			},
 			Name: "coredns-ips-with-targetref",
 		},
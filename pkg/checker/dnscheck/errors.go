@@ -12,10 +12,80 @@ const (
 	ErrCodePodError        = "PodError"
 	ErrCodeLocalDNSTimeout = "LocalDNSTimeout"
 	ErrCodeLocalDNSError   = "LocalDNSError"
+
+	// ErrCodePodsFailed is the error code of the overall CoreDNSPerPod result when CoreDNS pods failed with different error codes.
+	ErrCodePodsFailed = "PodsFailed"
+
+	// ErrCodeInconsistentAnswers is the error code of CoreDNSPerPod results when CoreDNS pods return different answers.
+	ErrCodeInconsistentAnswers = "InconsistentAnswers"
+
+	// Error codes for responses with an error rcode, truncated responses and responses without the expected answers, for each kind of
+	// DNS server queried. With the UDPAndTCP protocol, TCPFailed means that a query succeeded over UDP but failed over TCP, and
+	// TruncatedNoTCPFallback that a truncated UDP response could not be retried over TCP.
+	ErrCodeServiceNXDomain               = "ServiceNXDomain"
+	ErrCodeServiceServFail               = "ServiceServFail"
+	ErrCodeServiceRefused                = "ServiceRefused"
+	ErrCodeServiceTruncated              = "ServiceTruncated"
+	ErrCodeServiceUnexpectedAnswer       = "ServiceUnexpectedAnswer"
+	ErrCodeServiceTCPFailed              = "ServiceTCPFailed"
+	ErrCodeServiceTruncatedNoTCPFallback = "ServiceTruncatedNoTCPFallback"
+
+	ErrCodePodNXDomain               = "PodNXDomain"
+	ErrCodePodServFail               = "PodServFail"
+	ErrCodePodRefused                = "PodRefused"
+	ErrCodePodTruncated              = "PodTruncated"
+	ErrCodePodUnexpectedAnswer       = "PodUnexpectedAnswer"
+	ErrCodePodTCPFailed              = "PodTCPFailed"
+	ErrCodePodTruncatedNoTCPFallback = "PodTruncatedNoTCPFallback"
+
+	ErrCodeLocalDNSNXDomain               = "LocalDNSNXDomain"
+	ErrCodeLocalDNSServFail               = "LocalDNSServFail"
+	ErrCodeLocalDNSRefused                = "LocalDNSRefused"
+	ErrCodeLocalDNSTruncated              = "LocalDNSTruncated"
+	ErrCodeLocalDNSUnexpectedAnswer       = "LocalDNSUnexpectedAnswer"
+	ErrCodeLocalDNSTCPFailed              = "LocalDNSTCPFailed"
+	ErrCodeLocalDNSTruncatedNoTCPFallback = "LocalDNSTruncatedNoTCPFallback"
+
+	// Error codes of the results of the nameservers queried by the Custom target.
+	ErrCodeNameserverTimeout                = "NameserverTimeout"
+	ErrCodeNameserverError                  = "NameserverError"
+	ErrCodeNameserverNXDomain               = "NameserverNXDomain"
+	ErrCodeNameserverServFail               = "NameserverServFail"
+	ErrCodeNameserverRefused                = "NameserverRefused"
+	ErrCodeNameserverTruncated              = "NameserverTruncated"
+	ErrCodeNameserverUnexpectedAnswer       = "NameserverUnexpectedAnswer"
+	ErrCodeNameserverTCPFailed              = "NameserverTCPFailed"
+	ErrCodeNameserverTruncatedNoTCPFallback = "NameserverTruncatedNoTCPFallback"
+
+	// ErrCodeNameserversFailed is the error code of the overall Custom result when nameservers failed with different error codes.
+	ErrCodeNameserversFailed = "NameserversFailed"
+
+	// Error codes of the ResolvConf target, for the last query of the resolution through the search list.
+	ErrCodeResolvConfTimeout                = "ResolvConfTimeout"
+	ErrCodeResolvConfError                  = "ResolvConfError"
+	ErrCodeResolvConfNXDomain               = "ResolvConfNXDomain"
+	ErrCodeResolvConfServFail               = "ResolvConfServFail"
+	ErrCodeResolvConfRefused                = "ResolvConfRefused"
+	ErrCodeResolvConfTruncated              = "ResolvConfTruncated"
+	ErrCodeResolvConfUnexpectedAnswer       = "ResolvConfUnexpectedAnswer"
+	ErrCodeResolvConfTCPFailed              = "ResolvConfTCPFailed"
+	ErrCodeResolvConfTruncatedNoTCPFallback = "ResolvConfTruncatedNoTCPFallback"
+)
+
+// Reasons of the run errors recorded by the DNSChecker.
+const (
+	// RunErrorEndpointDiscovery is recorded when the CoreDNS endpoints cannot be listed.
+	RunErrorEndpointDiscovery = "EndpointDiscoveryFailed"
+	// RunErrorMissingTargetRef is recorded for a CoreDNS endpoint without the pod in its targetRef.
+	RunErrorMissingTargetRef = "EndpointTargetRefMissing"
 )
 
 // This is the error list used by the DNSChecker.
 var (
-	errServiceNotReady = errors.New("service not ready")
-	errPodsNotReady    = errors.New("pods not ready")
+	errServiceNotReady  = errors.New("service not ready")
+	errPodsNotReady     = errors.New("pods not ready")
+	errTruncated        = errors.New("DNS response truncated")
+	errUnexpectedAnswer = errors.New("unexpected DNS answer")
+	errTCPFailed        = errors.New("DNS query succeeded over UDP but failed over TCP")
+	errNoTCPFallback    = errors.New("DNS response truncated and TCP fallback failed")
 )
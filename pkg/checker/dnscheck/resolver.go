@@ -2,28 +2,107 @@ package dnscheck
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net"
 	"time"
+
+	"github.com/miekg/dns"
 )
 
 // resolver is an interface for DNS resolution.
 type resolver interface {
-	lookupHost(ctx context.Context, dnsIP, domain string, queryTimeout time.Duration) ([]string, error)
+	// query sends a query for the record type of the domain to the DNS server at the host:port address and returns the answers of that record type.
+	// A response with an error rcode is returned as a *rcodeError, a truncated response as errTruncated and a timeout as an error
+	// wrapping context.DeadlineExceeded.
+	query(ctx context.Context, address, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error)
+}
+
+// rcodeError is returned for a response with an rcode other than NOERROR, e.g. NXDOMAIN or SERVFAIL.
+type rcodeError struct {
+	rcode int
+}
+
+func (e *rcodeError) Error() string {
+	return fmt.Sprintf("DNS server responded with rcode %s", dns.RcodeToString[e.rcode])
 }
 
-// defaultResolver implements the resolver interface using net.Resolver.
+// defaultResolver implements the resolver interface using miekg/dns.
 type defaultResolver struct {
+	// network is the transport of the queries, "udp" or "tcp". Defaults to "udp".
+	network string
+	// ednsBufferSize is the UDP buffer size advertised with EDNS0. Queries are sent without EDNS0 if it is 0.
+	ednsBufferSize uint16
+	// tcpFallback is whether a truncated UDP response is retried over TCP like a stub resolver would, instead of being returned as
+	// errTruncated.
+	tcpFallback bool
 }
 
-func (r *defaultResolver) lookupHost(ctx context.Context, dnsIP, domain string, queryTimeout time.Duration) ([]string, error) {
+func (r *defaultResolver) query(ctx context.Context, address, domain string, recordType uint16, queryTimeout time.Duration) ([]string, error) {
 	ctx, cancel := context.WithTimeout(ctx, queryTimeout)
 	defer cancel()
-	resolver := &net.Resolver{
-		PreferGo: true,
-		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
-			d := net.Dialer{}
-			return d.DialContext(ctx, network, net.JoinHostPort(dnsIP, "53"))
-		},
-	}
-	return resolver.LookupHost(ctx, domain)
+
+	msg := new(dns.Msg)
+	msg.SetQuestion(dns.Fqdn(domain), recordType)
+	if r.ednsBufferSize > 0 {
+		msg.SetEdns0(r.ednsBufferSize, false)
+	}
+	network := r.network
+	if network == "" {
+		network = "udp"
+	}
+	resp, err := exchange(ctx, network, msg, address, queryTimeout)
+	if err == nil && resp.Truncated && network == "udp" && r.tcpFallback {
+		resp, err = exchange(ctx, "tcp", msg, address, queryTimeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if resp.Truncated {
+		return nil, errTruncated
+	}
+	if resp.Rcode != dns.RcodeSuccess {
+		return nil, &rcodeError{rcode: resp.Rcode}
+	}
+	return answers(resp, recordType), nil
+}
+
+// exchange sends the query over the network to the DNS server at the address and returns its response. A timeout is returned as an error
+// wrapping context.DeadlineExceeded.
+func exchange(ctx context.Context, network string, msg *dns.Msg, address string, queryTimeout time.Duration) (*dns.Msg, error) {
+	// The client reads UDP responses up to the EDNS0 buffer size of the query.
+	client := &dns.Client{Net: network, Timeout: queryTimeout}
+	resp, _, err := client.ExchangeContext(ctx, msg, address)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return nil, fmt.Errorf("%w: %w", context.DeadlineExceeded, err)
+		}
+		return nil, err
+	}
+	return resp, nil
+}
+
+// answers returns the answers of the record type in the response, ignoring other records such as the CNAME records that lead to an A
+// record. IP addresses are returned for A and AAAA records and fully qualified domain names for SRV, CNAME and PTR records.
+func answers(resp *dns.Msg, recordType uint16) []string {
+	var answers []string
+	for _, rr := range resp.Answer {
+		if rr.Header().Rrtype != recordType {
+			continue
+		}
+		switch rr := rr.(type) {
+		case *dns.A:
+			answers = append(answers, rr.A.String())
+		case *dns.AAAA:
+			answers = append(answers, rr.AAAA.String())
+		case *dns.SRV:
+			answers = append(answers, rr.Target)
+		case *dns.CNAME:
+			answers = append(answers, rr.Target)
+		case *dns.PTR:
+			answers = append(answers, rr.Ptr)
+		}
+	}
+	return answers
 }
@@ -0,0 +1,142 @@
+package dnscheck
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/miekg/dns"
+	. "github.com/onsi/gomega"
+)
+
+// largeAnswerCount is the number of A records returned for large.example.com, which does not fit in a 512 byte UDP response.
+const largeAnswerCount = 40
+
+func TestDefaultResolver_Query(t *testing.T) {
+	t.Parallel()
+	address := startTestDNSServer(t)
+
+	testCases := []struct {
+		name        string
+		resolver    *defaultResolver
+		domain      string
+		validateRes func(g *WithT, answers []string, err error)
+	}{
+		{
+			name:     "UDP",
+			resolver: &defaultResolver{network: "udp"},
+			domain:   "small.example.com",
+			validateRes: func(g *WithT, answers []string, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(answers).To(Equal([]string{"10.0.0.1"}))
+			},
+		},
+		{
+			name:     "UDP large answer truncated without EDNS0",
+			resolver: &defaultResolver{network: "udp"},
+			domain:   "large.example.com",
+			validateRes: func(g *WithT, answers []string, err error) {
+				g.Expect(err).To(MatchError(errTruncated))
+			},
+		},
+		{
+			name:     "UDP large answer retried over TCP",
+			resolver: &defaultResolver{network: "udp", tcpFallback: true},
+			domain:   "large.example.com",
+			validateRes: func(g *WithT, answers []string, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(answers).To(HaveLen(largeAnswerCount))
+			},
+		},
+		{
+			name:     "UDP large answer with EDNS0",
+			resolver: &defaultResolver{network: "udp", ednsBufferSize: 4096},
+			domain:   "large.example.com",
+			validateRes: func(g *WithT, answers []string, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(answers).To(HaveLen(largeAnswerCount))
+			},
+		},
+		{
+			name:     "TCP large answer",
+			resolver: &defaultResolver{network: "tcp"},
+			domain:   "large.example.com",
+			validateRes: func(g *WithT, answers []string, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(answers).To(HaveLen(largeAnswerCount))
+			},
+		},
+		{
+			name:     "NXDOMAIN",
+			resolver: &defaultResolver{network: "udp"},
+			domain:   "missing.example.com",
+			validateRes: func(g *WithT, answers []string, err error) {
+				g.Expect(err).To(Equal(&rcodeError{rcode: dns.RcodeNameError}))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			answers, err := tc.resolver.query(context.Background(), address, tc.domain, dns.TypeA, 2*time.Second)
+			tc.validateRes(g, answers, err)
+		})
+	}
+}
+
+// startTestDNSServer starts a DNS server on a random local port for both UDP and TCP and returns its address. UDP responses are truncated
+// to 512 bytes or the EDNS0 buffer size of the query.
+func startTestDNSServer(t *testing.T) string {
+	t.Helper()
+	handler := dns.HandlerFunc(func(w dns.ResponseWriter, r *dns.Msg) {
+		m := new(dns.Msg)
+		m.SetReply(r)
+		switch r.Question[0].Name {
+		case "small.example.com.":
+			m.Answer = append(m.Answer, &dns.A{
+				Hdr: dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+				A:   net.ParseIP("10.0.0.1"),
+			})
+		case "large.example.com.":
+			for i := range largeAnswerCount {
+				m.Answer = append(m.Answer, &dns.A{
+					Hdr: dns.RR_Header{Name: r.Question[0].Name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+					A:   net.ParseIP(fmt.Sprintf("10.0.1.%d", i+1)),
+				})
+			}
+		default:
+			m.Rcode = dns.RcodeNameError
+		}
+		if w.LocalAddr().Network() == "udp" {
+			size := dns.MinMsgSize
+			if opt := r.IsEdns0(); opt != nil {
+				size = int(opt.UDPSize())
+			}
+			m.Truncate(size)
+		}
+		_ = w.WriteMsg(m)
+	})
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on UDP: %v", err)
+	}
+	// Listen on TCP on the same port as UDP.
+	l, err := net.Listen("tcp", pc.LocalAddr().String())
+	if err != nil {
+		t.Fatalf("failed to listen on TCP: %v", err)
+	}
+	udpServer := &dns.Server{PacketConn: pc, Handler: handler}
+	tcpServer := &dns.Server{Listener: l, Handler: handler}
+	go func() { _ = udpServer.ActivateAndServe() }()
+	go func() { _ = tcpServer.ActivateAndServe() }()
+	t.Cleanup(func() {
+		_ = udpServer.Shutdown()
+		_ = tcpServer.Shutdown()
+	})
+	return pc.LocalAddr().String()
+}
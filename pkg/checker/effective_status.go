@@ -0,0 +1,173 @@
+package checker
+
+import (
+	"sync"
+
+	"github.com/Azure/cluster-health-monitor/pkg/metrics"
+	"github.com/Azure/cluster-health-monitor/pkg/status"
+	"k8s.io/klog/v2"
+)
+
+// Transition describes a change of the effective status of a checker or of a pod, nameserver or placement group checked by a checker.
+type Transition struct {
+	// CheckerName is the name of the checker.
+	CheckerName string
+	// CheckerType is the type of the checker.
+	CheckerType string
+	// Pod is the namespace/name of the pod for per-pod results and empty for checker results.
+	Pod string
+	// Nameserver is the address of the nameserver for per-nameserver results and empty otherwise.
+	Nameserver string
+	// Group is the placement group for per-placement-group results and empty otherwise.
+	Group string
+	// From is the previous effective status.
+	From string
+	// To is the new effective status.
+	To string
+	// Code is the error code of the result that caused the transition.
+	Code string
+	// Message is the message of the result that caused the transition.
+	Message string
+}
+
+var (
+	effectiveMu sync.Mutex
+	// transitionHandlers are called on every effective status transition.
+	transitionHandlers []func(Transition)
+	// thresholds holds the failure and success thresholds of each checker, keyed by checker name. It is populated by SetThresholds when a
+	// checker is scheduled.
+	thresholds = make(map[string]threshold)
+	// debouncers holds the effective status state of each checker and, for per-pod, per-nameserver and per-placement-group results, of
+	// each pod, nameserver and placement group.
+	debouncers = make(map[debouncerKey]*debouncer)
+)
+
+// threshold holds the number of consecutive results needed to change the effective status of a checker.
+type threshold struct {
+	failure int
+	success int
+}
+
+type debouncerKey struct {
+	checkerName string
+	// target is the pod, nameserver or placement group of per-target results and empty for checker results.
+	target targetLabels
+}
+
+// debouncer computes the effective status of a checker from its consecutive raw results, like the failure and success thresholds of
+// Kubernetes probes. The effective status is unknown until either threshold has been reached for the first time.
+type debouncer struct {
+	threshold threshold
+	effective string
+	failures  int
+	successes int
+}
+
+// observe records a raw result status and returns the resulting effective status. Unhealthy and unknown results both count as failures,
+// and the effective status of a failing checker is the status of its latest failure.
+func (d *debouncer) observe(status string) string {
+	if status == metrics.HealthyStatus {
+		d.successes++
+		d.failures = 0
+		if d.successes >= d.threshold.success {
+			d.effective = metrics.HealthyStatus
+		}
+		return d.effective
+	}
+	d.failures++
+	d.successes = 0
+	if d.failures >= d.threshold.failure {
+		d.effective = status
+	}
+	return d.effective
+}
+
+// SetThresholds stores the failure and success thresholds of the named checker. Unset thresholds default to 1. It is called when the
+// checker is scheduled rather than when it is built, so that building a checker of a configuration that is then rejected does not change
+// the thresholds of the running checker.
+func SetThresholds(checkerName string, failureThreshold, successThreshold int) {
+	effectiveMu.Lock()
+	defer effectiveMu.Unlock()
+	thresholds[checkerName] = threshold{
+		failure: max(failureThreshold, 1),
+		success: max(successThreshold, 1),
+	}
+}
+
+// OnTransition registers a function that is called on every change of the effective status of a checker, pod, nameserver or placement
+// group. Handlers are called synchronously from the goroutine recording the result, so they must not block.
+func OnTransition(handler func(Transition)) {
+	effectiveMu.Lock()
+	defer effectiveMu.Unlock()
+	transitionHandlers = append(transitionHandlers, handler)
+}
+
+// recordEffectiveStatus feeds a raw result into the debouncer of the key and notifies the transition handlers if the effective
+// status changed. Statuses without a gauge series, e.g. skipped results, are ignored. It returns the effective status, which is empty if
+// the status was ignored.
+func recordEffectiveStatus(checkerType string, key debouncerKey, rawStatus, code, message string) string {
+	if !isGaugeStatus(rawStatus) {
+		return ""
+	}
+
+	effectiveMu.Lock()
+	d, ok := debouncers[key]
+	if !ok {
+		t, ok := thresholds[key.checkerName]
+		if !ok {
+			t = threshold{failure: 1, success: 1}
+		}
+		d = &debouncer{threshold: t, effective: metrics.UnknownStatus}
+		debouncers[key] = d
+	}
+	previous := d.effective
+	effective := d.observe(rawStatus)
+	handlers := transitionHandlers
+	effectiveMu.Unlock()
+
+	if previous != effective {
+		klog.InfoS("Checker effective status changed", "name", key.checkerName, "type", checkerType, "pod", key.target.pod(),
+			"nameserver", key.target.nameserver, "group", key.target.group, "from", previous, "to", effective)
+		transition := Transition{
+			CheckerName: key.checkerName,
+			CheckerType: checkerType,
+			Pod:         key.target.pod(),
+			Nameserver:  key.target.nameserver,
+			Group:       key.target.group,
+			From:        previous,
+			To:          effective,
+			Code:        code,
+			Message:     message,
+		}
+		for _, handler := range handlers {
+			handler(transition)
+		}
+	}
+	return effective
+}
+
+// recordTargetEffectiveStatus updates the effective status of a checker, or of a pod, nameserver or placement group checked by it, from a
+// raw result. The effective status of the checker itself is also stored in the status store.
+func recordTargetEffectiveStatus(checkerType, checkerName string, target targetLabels, rawStatus, code, message string) {
+	effective := recordEffectiveStatus(checkerType, debouncerKey{checkerName: checkerName, target: target}, rawStatus, code, message)
+	if effective == "" {
+		return
+	}
+	_, _, effectiveStatusGauge, targetLabelValues := target.metrics()
+	setLastStatus(effectiveStatusGauge, effective, append([]string{checkerType, checkerName}, targetLabelValues...)...)
+	if target == (targetLabels{}) {
+		status.DefaultStore.SetEffectiveStatus(checkerName, checkerType, effective)
+	}
+}
+
+// deleteEffectiveStatus drops the effective status state of the named checker and its pods, nameservers and placement groups. The
+// configured thresholds are kept, as the checker may be rebuilt with them right away.
+func deleteEffectiveStatus(checkerName string) {
+	effectiveMu.Lock()
+	defer effectiveMu.Unlock()
+	for key := range debouncers {
+		if key.checkerName == checkerName {
+			delete(debouncers, key)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package checker
+
+import (
+	"testing"
+
+	"github.com/Azure/cluster-health-monitor/pkg/config"
+	"github.com/Azure/cluster-health-monitor/pkg/metrics"
+	"github.com/Azure/cluster-health-monitor/pkg/status"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func TestDebouncer_Observe(t *testing.T) {
+	t.Parallel()
+	const (
+		healthy   = metrics.HealthyStatus
+		unhealthy = metrics.UnhealthyStatus
+		unknown   = metrics.UnknownStatus
+	)
+	testCases := []struct {
+		name              string
+		threshold         threshold
+		statuses          []string
+		expectedEffective []string
+	}{
+		{
+			name:              "default thresholds follow the raw status",
+			threshold:         threshold{failure: 1, success: 1},
+			statuses:          []string{healthy, unhealthy, healthy, unknown},
+			expectedEffective: []string{healthy, unhealthy, healthy, unknown},
+		},
+		{
+			name:              "single failure is damped",
+			threshold:         threshold{failure: 3, success: 1},
+			statuses:          []string{healthy, unhealthy, healthy, unhealthy, unhealthy, unhealthy},
+			expectedEffective: []string{healthy, healthy, healthy, healthy, healthy, unhealthy},
+		},
+		{
+			name:              "unhealthy and unknown both count as failures",
+			threshold:         threshold{failure: 2, success: 1},
+			statuses:          []string{healthy, unhealthy, unknown},
+			expectedEffective: []string{healthy, healthy, unknown},
+		},
+		{
+			name:              "recovery requires consecutive successes",
+			threshold:         threshold{failure: 1, success: 2},
+			statuses:          []string{unhealthy, healthy, unhealthy, healthy, healthy},
+			expectedEffective: []string{unhealthy, unhealthy, unhealthy, unhealthy, healthy},
+		},
+		{
+			name:              "effective status is unknown until a threshold is reached",
+			threshold:         threshold{failure: 2, success: 2},
+			statuses:          []string{healthy, healthy},
+			expectedEffective: []string{unknown, healthy},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			d := &debouncer{threshold: tc.threshold, effective: metrics.UnknownStatus}
+			var effective []string
+			for _, s := range tc.statuses {
+				effective = append(effective, d.observe(s))
+			}
+			g.Expect(effective).To(Equal(tc.expectedEffective))
+		})
+	}
+}
+
+func TestRecordResult_EffectiveStatus(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	testType := config.CheckerType("fake")
+	RegisterChecker(testType, fakeBuilder)
+	chk, err := Build(&config.CheckerConfig{Name: "effective", Type: testType}, k8sfake.NewClientset(), &rest.Config{})
+	g.Expect(err).ToNot(HaveOccurred())
+	SetThresholds("effective", 2, 0)
+
+	effectiveStatus := func(status string) float64 {
+		return testutil.ToFloat64(metrics.CheckerEffectiveStatusGauge.WithLabelValues("fake", "effective", status))
+	}
+
+	RecordResult(chk, Healthy(), nil)
+	g.Expect(effectiveStatus(metrics.HealthyStatus)).To(Equal(1.0))
+
+	// The first failure only changes the raw status.
+	RecordResult(chk, Unhealthy("SomeError", "failed"), nil)
+	g.Expect(effectiveStatus(metrics.HealthyStatus)).To(Equal(1.0))
+	g.Expect(effectiveStatus(metrics.UnhealthyStatus)).To(Equal(0.0))
+	g.Expect(testutil.ToFloat64(metrics.CheckerLastStatusGauge.WithLabelValues("fake", "effective", metrics.UnhealthyStatus))).To(Equal(1.0))
+
+	RecordResult(chk, Unhealthy("SomeError", "failed"), nil)
+	g.Expect(effectiveStatus(metrics.HealthyStatus)).To(Equal(0.0))
+	g.Expect(effectiveStatus(metrics.UnhealthyStatus)).To(Equal(1.0))
+	st, ok := status.DefaultStore.Get("effective")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(st.EffectiveStatus).To(Equal(metrics.UnhealthyStatus))
+
+	// Deleting the checker resets its effective status.
+	DeleteLastResult("effective")
+	RecordResult(chk, Unhealthy("SomeError", "failed"), nil)
+	g.Expect(effectiveStatus(metrics.UnknownStatus)).To(Equal(1.0))
+}
+
+func TestBuild_KeepsThresholds(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	testType := config.CheckerType("fake")
+	RegisterChecker(testType, fakeBuilder)
+	SetThresholds("rebuilt", 1, 1)
+
+	// Building a checker, e.g. of a configuration that is then rejected, does not change the thresholds of the scheduled checker.
+	chk, err := Build(&config.CheckerConfig{Name: "rebuilt", Type: testType, FailureThreshold: 3}, k8sfake.NewClientset(), &rest.Config{})
+	g.Expect(err).ToNot(HaveOccurred())
+	RecordResult(chk, Unhealthy("SomeError", "failed"), nil)
+	g.Expect(testutil.ToFloat64(metrics.CheckerEffectiveStatusGauge.WithLabelValues("fake", "rebuilt", metrics.UnhealthyStatus))).To(Equal(1.0))
+}
+
+func TestOnTransition(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	testType := config.CheckerType("fake")
+	RegisterChecker(testType, fakeBuilder)
+	chk, err := Build(&config.CheckerConfig{Name: "transitions", Type: testType}, k8sfake.NewClientset(), &rest.Config{})
+	g.Expect(err).ToNot(HaveOccurred())
+	DeleteLastResult("transitions")
+
+	// Handlers are global and cannot be unregistered, so only keep the transitions of the checker under test and never block.
+	transitions := make(chan Transition, 10)
+	OnTransition(func(t Transition) {
+		if t.CheckerName != "transitions" {
+			return
+		}
+		select {
+		case transitions <- t:
+		default:
+		}
+	})
+
+	RecordResult(chk, Healthy(), nil)
+	RecordResult(chk, Healthy(), nil)
+	RecordResult(chk, Unhealthy("SomeError", "failed"), nil)
+
+	g.Expect(transitions).To(HaveLen(2))
+	g.Expect(<-transitions).To(Equal(Transition{
+		CheckerName: "transitions", CheckerType: "fake", From: metrics.UnknownStatus, To: metrics.HealthyStatus,
+		Code: metrics.HealthyCode,
+	}))
+	g.Expect(<-transitions).To(Equal(Transition{
+		CheckerName: "transitions", CheckerType: "fake", From: metrics.HealthyStatus, To: metrics.UnhealthyStatus,
+		Code: "SomeError", Message: "failed",
+	}))
+}
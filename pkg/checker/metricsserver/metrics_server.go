@@ -30,15 +30,9 @@ func Register() {
 }
 
 // BuildMetricsServerChecker creates a new MetricsServerChecker instance.
-func BuildMetricsServerChecker(config *config.CheckerConfig, kubeClient kubernetes.Interface) (checker.Checker, error) {
-	// Get in-cluster config to access the API server
-	k8sConfig, err := rest.InClusterConfig()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get in-cluster config: %w", err)
-	}
-
+func BuildMetricsServerChecker(config *config.CheckerConfig, kubeClient kubernetes.Interface, restConfig *rest.Config) (checker.Checker, error) {
 	// Create metrics client using the official Kubernetes metrics client
-	metricsClient, err := metricsclientset.NewForConfig(k8sConfig)
+	metricsClient, err := metricsclientset.NewForConfig(restConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create metrics client: %w", err)
 	}
@@ -65,8 +59,10 @@ func (c *MetricsServerChecker) Type() config.CheckerType {
 }
 
 func (c *MetricsServerChecker) Run(ctx context.Context) {
+	start := time.Now()
 	result, err := c.check(ctx)
 	checker.RecordResult(c, result, err)
+	checker.RecordDuration(c, result, err, time.Since(start))
 }
 
 // check executes the metrics server check.
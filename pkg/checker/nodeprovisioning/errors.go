@@ -0,0 +1,35 @@
+package nodeprovisioning
+
+import "fmt"
+
+const (
+	// Error codes naming the stage of the node provisioning that did not complete before the checker timed out.
+	ErrCodeNodeClaimCreationTimeout       = "NodeClaimCreationTimeout"
+	ErrCodeNodeClaimLaunchTimeout         = "NodeClaimLaunchTimeout"
+	ErrCodeNodeClaimRegistrationTimeout   = "NodeClaimRegistrationTimeout"
+	ErrCodeNodeReadyTimeout               = "NodeReadyTimeout"
+	ErrCodeNodeClaimInitializationTimeout = "NodeClaimInitializationTimeout"
+
+	// Error codes for the failures reported by Karpenter, returned instead of the stage timeouts when the node was not provisioned because
+	// of them. InsufficientCapacity and NodeClassNotReady are reported as events of the NodeClaims, which Karpenter deletes and retries.
+	// NodePoolNotReady is returned when no NodeClaim was created and the NodePool is not ready, e.g. because its node class is not ready.
+	ErrCodeInsufficientCapacity        = "InsufficientCapacity"
+	ErrCodeNodeClassNotReady           = "NodeClassNotReady"
+	ErrCodeNodeClaimLaunchFailed       = "NodeClaimLaunchFailed"
+	ErrCodeNodeClaimRegistrationFailed = "NodeClaimRegistrationFailed"
+	ErrCodeNodePoolNotReady            = "NodePoolNotReady"
+
+	// Error codes for creating the NodePool and the pod that triggers the node provisioning.
+	ErrCodeNodePoolCreationError   = "NodePoolCreationError"
+	ErrCodeTriggerPodCreationError = "TriggerPodCreationError"
+)
+
+// nodeProvisioningError is returned when the node was not provisioned before the checker timed out.
+type nodeProvisioningError struct {
+	code    string
+	message string
+}
+
+func (e *nodeProvisioningError) Error() string {
+	return fmt.Sprintf("node was not provisioned: %s", e.message)
+}
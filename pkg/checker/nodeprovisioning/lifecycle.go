@@ -0,0 +1,329 @@
+package nodeprovisioning
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/awslabs/operatorpkg/status"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+	karpenter "sigs.k8s.io/karpenter/pkg/apis/v1"
+
+	"github.com/Azure/cluster-health-monitor/pkg/karpenterclient"
+)
+
+// Stages of the node provisioning recorded in the phase duration histogram. Each is measured from the creation of the NodePool, from the
+// timestamps of the NodeClaim and node, which are precise to the second.
+const (
+	phaseNodeClaimCreated = "nodeclaim_created"
+	phaseLaunched         = "launched"
+	phaseRegistered       = "registered"
+	phaseNodeReady        = "node_ready"
+	phaseInitialized      = "initialized"
+)
+
+// Reasons of the NodeClaim events for failures after which Karpenter deletes the NodeClaim.
+const (
+	eventReasonInsufficientCapacityError = "InsufficientCapacityError"
+	eventReasonNodeClassNotReady         = "NodeClassNotReady"
+)
+
+// The reason of the NodeClaim conditions that were not reconciled yet.
+const conditionReasonAwaitingReconciliation = "AwaitingReconciliation"
+
+// How often to poll the NodeClaims and node while they are not watched.
+var pollingInterval = 1 * time.Second // used for unit tests
+
+// How long to look into the NodeClaims and events of a node that was not provisioned before the checker timed out.
+var diagnosisTimeout = 10 * time.Second
+
+// provisioningPhase is the time from the creation of the NodePool until a stage of the node provisioning completed.
+type provisioningPhase struct {
+	name     string
+	duration time.Duration
+}
+
+// nodeLifecycle is the latest observed state of the node provisioned for a NodePool.
+type nodeLifecycle struct {
+	nodePoolName string
+	// nodePoolCreated is the creation time of the NodePool.
+	nodePoolCreated time.Time
+	// nodeClaim is the latest observed NodeClaim of the NodePool, which may have been deleted since, e.g. after failing to launch.
+	nodeClaim *karpenter.NodeClaim
+	// node is the latest observed node of nodeClaim.
+	node *corev1.Node
+	// nodeClaimNames are the names of all observed NodeClaims of the NodePool, including those deleted since, whose events explain
+	// why a node was not provisioned.
+	nodeClaimNames []string
+}
+
+// observeNodeClaim records the name of a NodeClaim of the NodePool.
+func (l *nodeLifecycle) observeNodeClaim(name string) {
+	if !slices.Contains(l.nodeClaimNames, name) {
+		l.nodeClaimNames = append(l.nodeClaimNames, name)
+	}
+}
+
+// ready returns true if the NodeClaim is initialized and its node is ready.
+func (l *nodeLifecycle) ready() bool {
+	return l.nodeClaim != nil && l.nodeClaim.DeletionTimestamp == nil && nodeClaimConditionTime(l.nodeClaim, karpenter.ConditionTypeInitialized) != nil &&
+		l.node != nil && nodeReadyTime(l.node) != nil
+}
+
+// phases returns the stages of the node provisioning that completed, in order.
+func (l *nodeLifecycle) phases() []provisioningPhase {
+	if l.nodeClaim == nil {
+		return nil
+	}
+	since := func(t time.Time) time.Duration {
+		return max(t.Sub(l.nodePoolCreated), 0)
+	}
+	phases := []provisioningPhase{{name: phaseNodeClaimCreated, duration: since(l.nodeClaim.CreationTimestamp.Time)}}
+	for _, stage := range []struct{ phase, conditionType string }{
+		{phaseLaunched, karpenter.ConditionTypeLaunched},
+		{phaseRegistered, karpenter.ConditionTypeRegistered},
+	} {
+		if t := nodeClaimConditionTime(l.nodeClaim, stage.conditionType); t != nil {
+			phases = append(phases, provisioningPhase{name: stage.phase, duration: since(t.Time)})
+		}
+	}
+	if l.node != nil {
+		if t := nodeReadyTime(l.node); t != nil {
+			phases = append(phases, provisioningPhase{name: phaseNodeReady, duration: since(t.Time)})
+		}
+	}
+	if t := nodeClaimConditionTime(l.nodeClaim, karpenter.ConditionTypeInitialized); t != nil {
+		phases = append(phases, provisioningPhase{name: phaseInitialized, duration: since(t.Time)})
+	}
+	return phases
+}
+
+// waitForNode waits until a node of the NodePool is ready and initialized. The NodeClaims and nodes of the NodePool are watched, and
+// polled instead if a watch cannot be established or breaks.
+func (c *NodeProvisioningChecker) waitForNode(ctx context.Context, lifecycle *nodeLifecycle) error {
+	selector := labels.SelectorFromSet(labels.Set{karpenter.NodePoolLabelKey: lifecycle.nodePoolName}).String()
+
+	var nodeClaimCh, nodeCh <-chan watch.Event
+	nodeClaims := c.dynamicClient.Resource(karpenterclient.NodeClaimGVR)
+	if nodeClaimWatch, err := nodeClaims.Watch(ctx, metav1.ListOptions{LabelSelector: selector}); err != nil {
+		klog.ErrorS(err, "Failed to watch NodeClaims, falling back to polling", "nodePool", lifecycle.nodePoolName)
+	} else {
+		defer nodeClaimWatch.Stop()
+		nodeClaimCh = nodeClaimWatch.ResultChan()
+	}
+	if nodeWatch, err := c.k8sClientset.CoreV1().Nodes().Watch(ctx, metav1.ListOptions{LabelSelector: selector}); err != nil {
+		klog.ErrorS(err, "Failed to watch nodes, falling back to polling", "nodePool", lifecycle.nodePoolName)
+	} else {
+		defer nodeWatch.Stop()
+		nodeCh = nodeWatch.ResultChan()
+	}
+	ticker := time.NewTicker(pollingInterval)
+	defer ticker.Stop()
+
+	for {
+		// The watches only signal changes, the NodeClaims and node are read again on each of them, so that no change is missed while a
+		// watch is being established.
+		if err := c.observe(ctx, lifecycle); err != nil {
+			klog.ErrorS(err, "Failed to get the NodeClaim and node of NodePool", "nodePool", lifecycle.nodePoolName)
+		} else if lifecycle.ready() {
+			return nil
+		}
+
+		var pollCh <-chan time.Time
+		if nodeClaimCh == nil || nodeCh == nil {
+			pollCh = ticker.C
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case event, ok := <-nodeClaimCh:
+			if !ok || event.Type == watch.Error {
+				klog.InfoS("NodeClaim watch broke, falling back to polling", "nodePool", lifecycle.nodePoolName)
+				nodeClaimCh = nil
+			} else if nodeClaim, ok := event.Object.(metav1.Object); ok {
+				// A NodeClaim that Karpenter deletes right after creating it is only seen by the watch.
+				lifecycle.observeNodeClaim(nodeClaim.GetName())
+			}
+		case event, ok := <-nodeCh:
+			if !ok || event.Type == watch.Error {
+				klog.InfoS("Node watch broke, falling back to polling", "nodePool", lifecycle.nodePoolName)
+				nodeCh = nil
+			}
+		case <-pollCh:
+		}
+	}
+}
+
+// observe records the latest NodeClaim of the NodePool and its node. The latest NodeClaim is kept if the NodePool has none anymore.
+func (c *NodeProvisioningChecker) observe(ctx context.Context, lifecycle *nodeLifecycle) error {
+	selector := labels.SelectorFromSet(labels.Set{karpenter.NodePoolLabelKey: lifecycle.nodePoolName}).String()
+	nodeClaims, err := karpenterclient.ListNodeClaims(ctx, c.dynamicClient, selector)
+	if err != nil {
+		return err
+	}
+	var latest *karpenter.NodeClaim
+	for i := range nodeClaims {
+		lifecycle.observeNodeClaim(nodeClaims[i].Name)
+		if latest == nil || latest.CreationTimestamp.Before(&nodeClaims[i].CreationTimestamp) {
+			latest = &nodeClaims[i]
+		}
+	}
+	if latest != nil {
+		if lifecycle.nodeClaim == nil || lifecycle.nodeClaim.Name != latest.Name {
+			lifecycle.node = nil
+		}
+		lifecycle.nodeClaim = latest
+	}
+	if lifecycle.nodeClaim == nil || lifecycle.nodeClaim.Status.NodeName == "" {
+		return nil
+	}
+	node, err := c.k8sClientset.CoreV1().Nodes().Get(ctx, lifecycle.nodeClaim.Status.NodeName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get node %s: %w", lifecycle.nodeClaim.Status.NodeName, err)
+	}
+	lifecycle.node = node
+	return nil
+}
+
+// provisioningError returns the error explaining why the node of the NodePool was not provisioned: the failure reported by
+// Karpenter if there is one, or else the stage the provisioning was stuck in. It is called after the checker timed out, so it gets the
+// objects with its own timeout.
+func (c *NodeProvisioningChecker) provisioningError(ctx context.Context, lifecycle *nodeLifecycle) *nodeProvisioningError {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), diagnosisTimeout)
+	defer cancel()
+
+	if err := c.observe(ctx, lifecycle); err != nil {
+		klog.ErrorS(err, "Failed to get the NodeClaim and node of NodePool", "nodePool", lifecycle.nodePoolName)
+	}
+
+	nodeClaim := lifecycle.nodeClaim
+	if nodeClaim != nil {
+		if launched := findCondition(nodeClaim.Status.Conditions, karpenter.ConditionTypeLaunched); launched != nil && !launched.IsTrue() &&
+			launched.Reason != "" && launched.Reason != conditionReasonAwaitingReconciliation {
+			return &nodeProvisioningError{code: ErrCodeNodeClaimLaunchFailed,
+				message: fmt.Sprintf("NodeClaim %s failed to launch: %s: %s", nodeClaim.Name, launched.Reason, launched.Message)}
+		}
+		if registered := findCondition(nodeClaim.Status.Conditions, karpenter.ConditionTypeRegistered); registered != nil && registered.IsFalse() {
+			return &nodeProvisioningError{code: ErrCodeNodeClaimRegistrationFailed,
+				message: fmt.Sprintf("NodeClaim %s failed to register: %s: %s", nodeClaim.Name, registered.Reason, registered.Message)}
+		}
+	}
+	if err := c.nodeClaimEventError(ctx, lifecycle); err != nil {
+		return err
+	}
+
+	switch {
+	case nodeClaim == nil:
+		nodePool, err := karpenterclient.GetNodePool(ctx, c.dynamicClient, lifecycle.nodePoolName)
+		if err != nil {
+			klog.ErrorS(err, "Failed to get NodePool", "nodePool", lifecycle.nodePoolName)
+		} else if nodePool != nil {
+			if ready := findCondition(nodePool.Status.Conditions, karpenter.ConditionTypeNodeClassReady); ready != nil && ready.IsFalse() {
+				return &nodeProvisioningError{code: ErrCodeNodePoolNotReady,
+					message: fmt.Sprintf("NodePool %s is not ready: %s: %s", nodePool.Name, ready.Reason, ready.Message)}
+			}
+		}
+		return &nodeProvisioningError{code: ErrCodeNodeClaimCreationTimeout,
+			message: fmt.Sprintf("no NodeClaim was created for NodePool %s", lifecycle.nodePoolName)}
+	case nodeClaimConditionTime(nodeClaim, karpenter.ConditionTypeLaunched) == nil:
+		return &nodeProvisioningError{code: ErrCodeNodeClaimLaunchTimeout,
+			message: fmt.Sprintf("NodeClaim %s was not launched", nodeClaim.Name)}
+	case nodeClaimConditionTime(nodeClaim, karpenter.ConditionTypeRegistered) == nil:
+		return &nodeProvisioningError{code: ErrCodeNodeClaimRegistrationTimeout,
+			message: fmt.Sprintf("NodeClaim %s was not registered", nodeClaim.Name)}
+	case lifecycle.node == nil || nodeReadyTime(lifecycle.node) == nil:
+		return &nodeProvisioningError{code: ErrCodeNodeReadyTimeout,
+			message: fmt.Sprintf("node %s of NodeClaim %s is not ready", nodeClaim.Status.NodeName, nodeClaim.Name)}
+	default:
+		message := fmt.Sprintf("NodeClaim %s was not initialized", nodeClaim.Name)
+		if initialized := findCondition(nodeClaim.Status.Conditions, karpenter.ConditionTypeInitialized); initialized != nil && initialized.Message != "" {
+			message = fmt.Sprintf("%s: %s", message, initialized.Message)
+		}
+		return &nodeProvisioningError{code: ErrCodeNodeClaimInitializationTimeout, message: message}
+	}
+}
+
+// nodeClaimEventError returns the error for the latest event of an observed NodeClaim of the NodePool reporting a failure after which
+// Karpenter deleted the NodeClaim, or nil if there is none. The events of cluster-scoped objects are in the default namespace.
+func (c *NodeProvisioningChecker) nodeClaimEventError(ctx context.Context, lifecycle *nodeLifecycle) *nodeProvisioningError {
+	var latest *corev1.Event
+	for _, nodeClaimName := range lifecycle.nodeClaimNames {
+		events, err := c.k8sClientset.CoreV1().Events(metav1.NamespaceDefault).List(ctx, metav1.ListOptions{
+			FieldSelector: fields.SelectorFromSet(fields.Set{
+				"involvedObject.kind": "NodeClaim",
+				"involvedObject.name": nodeClaimName,
+			}).String(),
+		})
+		if err != nil {
+			klog.ErrorS(err, "Failed to list NodeClaim events", "nodePool", lifecycle.nodePoolName, "nodeClaim", nodeClaimName)
+			continue
+		}
+		for i, event := range events.Items {
+			if event.Reason != eventReasonInsufficientCapacityError && event.Reason != eventReasonNodeClassNotReady {
+				continue
+			}
+			if latest == nil || eventTime(&event).After(eventTime(latest)) {
+				latest = &events.Items[i]
+			}
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+	code := ErrCodeInsufficientCapacity
+	if latest.Reason == eventReasonNodeClassNotReady {
+		code = ErrCodeNodeClassNotReady
+	}
+	return &nodeProvisioningError{code: code, message: latest.Message}
+}
+
+// findCondition returns the condition of the given type, or nil if there is none. Unlike StatusConditions, it does not add the missing
+// conditions of the object.
+func findCondition(conditions []status.Condition, conditionType string) *status.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// nodeClaimConditionTime returns the time at which the condition of the NodeClaim became true, or nil if it is not true.
+func nodeClaimConditionTime(nodeClaim *karpenter.NodeClaim, conditionType string) *metav1.Time {
+	condition := findCondition(nodeClaim.Status.Conditions, conditionType)
+	if condition == nil || !condition.IsTrue() {
+		return nil
+	}
+	return &condition.LastTransitionTime
+}
+
+// nodeReadyTime returns the time at which the node became ready, or nil if it is not ready.
+func nodeReadyTime(node *corev1.Node) *metav1.Time {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+			return &condition.LastTransitionTime
+		}
+	}
+	return nil
+}
+
+// eventTime returns the most precise time at which the event was last observed.
+func eventTime(event *corev1.Event) time.Time {
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	return event.FirstTimestamp.Time
+}
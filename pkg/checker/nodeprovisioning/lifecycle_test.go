@@ -0,0 +1,351 @@
+package nodeprovisioning
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/cluster-health-monitor/pkg/config"
+	"github.com/Azure/cluster-health-monitor/pkg/karpenterclient"
+	"github.com/awslabs/operatorpkg/status"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	karpenter "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+const (
+	testCheckerName  = "test"
+	testNamespace    = "test-namespace"
+	testLabelKey     = "cluster-health-monitor/checker-name"
+	testNodePoolName = "test-nodepool-1"
+)
+
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+func newTestDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		karpenterclient.NodePoolGVR:  "NodePoolList",
+		karpenterclient.NodeClaimGVR: "NodeClaimList",
+		crdGVR:                       "CustomResourceDefinitionList",
+	}, objects...)
+}
+
+func newTestChecker(kubeClient *k8sfake.Clientset, dynamicClient *dynamicfake.FakeDynamicClient) *NodeProvisioningChecker {
+	return &NodeProvisioningChecker{
+		name:    testCheckerName,
+		timeout: 10 * time.Minute,
+		config: &config.NodeProvisioningConfig{
+			Namespace:    testNamespace,
+			LabelKey:     testLabelKey,
+			MaxNodePools: 2,
+		},
+		k8sClientset:  kubeClient,
+		dynamicClient: dynamicClient,
+	}
+}
+
+func toUnstructured(obj runtime.Object) *unstructured.Unstructured {
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		panic(err)
+	}
+	return &unstructured.Unstructured{Object: object}
+}
+
+func condition(conditionType string, conditionStatus metav1.ConditionStatus, reason, message string, transitionTime time.Time) status.Condition {
+	return status.Condition{
+		Type:               conditionType,
+		Status:             conditionStatus,
+		Reason:             reason,
+		Message:            message,
+		LastTransitionTime: metav1.NewTime(transitionTime),
+	}
+}
+
+func testNodeClaim(name, nodePoolName string, created time.Time, nodeName string, conditions ...status.Condition) *unstructured.Unstructured {
+	return toUnstructured(&karpenter.NodeClaim{
+		TypeMeta: metav1.TypeMeta{APIVersion: "karpenter.sh/v1", Kind: "NodeClaim"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+			Labels:            map[string]string{karpenter.NodePoolLabelKey: nodePoolName, testLabelKey: testCheckerName},
+		},
+		Status: karpenter.NodeClaimStatus{NodeName: nodeName, Conditions: conditions},
+	})
+}
+
+func testNodePool(name string, created time.Time, conditions ...status.Condition) *unstructured.Unstructured {
+	return toUnstructured(&karpenter.NodePool{
+		TypeMeta: metav1.TypeMeta{APIVersion: "karpenter.sh/v1", Kind: "NodePool"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+			Labels:            map[string]string{testLabelKey: testCheckerName},
+		},
+		Status: karpenter.NodePoolStatus{Conditions: conditions},
+	})
+}
+
+func testNode(name, nodePoolName string, ready corev1.ConditionStatus, transitionTime time.Time) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{karpenter.NodePoolLabelKey: nodePoolName}},
+		Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+			{Type: corev1.NodeReady, Status: ready, LastTransitionTime: metav1.NewTime(transitionTime)},
+		}},
+	}
+}
+
+// provisionedNodeClaim returns an initialized NodeClaim of the NodePool with its node.
+func provisionedNodeClaim(nodePoolName string, created time.Time) (*unstructured.Unstructured, *corev1.Node) {
+	nodeClaim := testNodeClaim(nodePoolName+"-abcde", nodePoolName, created.Add(2*time.Second), "aks-node-1",
+		condition(karpenter.ConditionTypeLaunched, metav1.ConditionTrue, karpenter.ConditionTypeLaunched, "", created.Add(40*time.Second)),
+		condition(karpenter.ConditionTypeRegistered, metav1.ConditionTrue, karpenter.ConditionTypeRegistered, "", created.Add(70*time.Second)),
+		condition(karpenter.ConditionTypeInitialized, metav1.ConditionTrue, karpenter.ConditionTypeInitialized, "", created.Add(95*time.Second)),
+	)
+	return nodeClaim, testNode("aks-node-1", nodePoolName, corev1.ConditionTrue, created.Add(90*time.Second))
+}
+
+func TestNodeLifecycle_phases(t *testing.T) {
+	g := NewWithT(t)
+	created := time.Now().Truncate(time.Second)
+
+	lifecycle := &nodeLifecycle{nodePoolName: testNodePoolName, nodePoolCreated: created}
+	g.Expect(lifecycle.phases()).To(BeEmpty())
+	g.Expect(lifecycle.ready()).To(BeFalse())
+
+	nodeClaim, node := provisionedNodeClaim(testNodePoolName, created)
+	checker := newTestChecker(k8sfake.NewClientset(node), newTestDynamicClient(nodeClaim))
+	g.Expect(checker.observe(context.Background(), lifecycle)).To(Succeed())
+	g.Expect(lifecycle.ready()).To(BeTrue())
+	g.Expect(lifecycle.phases()).To(Equal([]provisioningPhase{
+		{name: phaseNodeClaimCreated, duration: 2 * time.Second},
+		{name: phaseLaunched, duration: 40 * time.Second},
+		{name: phaseRegistered, duration: 70 * time.Second},
+		{name: phaseNodeReady, duration: 90 * time.Second},
+		{name: phaseInitialized, duration: 95 * time.Second},
+	}))
+}
+
+func TestNodeProvisioningChecker_waitForNode(t *testing.T) {
+	defaultPollingInterval := pollingInterval
+	pollingInterval = 10 * time.Millisecond
+	t.Cleanup(func() { pollingInterval = defaultPollingInterval })
+
+	tests := []struct {
+		name string
+		// provisionAfter is the delay after which the NodeClaim and node are created, or a negative value if they are not.
+		provisionAfter time.Duration
+		failWatches    bool
+		expectedErr    error
+	}{
+		{
+			name:           "node already provisioned",
+			provisionAfter: 0,
+		},
+		{
+			name:           "node provisioned while watched",
+			provisionAfter: 100 * time.Millisecond,
+		},
+		{
+			name:           "node provisioned while polled",
+			provisionAfter: 100 * time.Millisecond,
+			failWatches:    true,
+		},
+		{
+			name:           "node not provisioned",
+			provisionAfter: -1,
+			expectedErr:    context.DeadlineExceeded,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			created := time.Now()
+
+			kubeClient := k8sfake.NewClientset()
+			dynamicClient := newTestDynamicClient()
+			if tt.failWatches {
+				failWatch := func(action k8stesting.Action) (bool, watch.Interface, error) {
+					return true, nil, errors.New("watch failed")
+				}
+				kubeClient.PrependWatchReactor("nodes", failWatch)
+				dynamicClient.PrependWatchReactor("nodeclaims", failWatch)
+			}
+			provision := func() {
+				nodeClaim, node := provisionedNodeClaim(testNodePoolName, created)
+				_, err := kubeClient.CoreV1().Nodes().Create(context.Background(), node, metav1.CreateOptions{})
+				g.Expect(err).ToNot(HaveOccurred())
+				_, err = dynamicClient.Resource(karpenterclient.NodeClaimGVR).Create(context.Background(), nodeClaim, metav1.CreateOptions{})
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+			switch {
+			case tt.provisionAfter == 0:
+				provision()
+			case tt.provisionAfter > 0:
+				time.AfterFunc(tt.provisionAfter, provision)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			if tt.expectedErr != nil {
+				ctx, cancel = context.WithTimeout(ctx, 300*time.Millisecond)
+				defer cancel()
+			}
+			lifecycle := &nodeLifecycle{nodePoolName: testNodePoolName, nodePoolCreated: created}
+			err := newTestChecker(kubeClient, dynamicClient).waitForNode(ctx, lifecycle)
+			if tt.expectedErr != nil {
+				g.Expect(err).To(MatchError(tt.expectedErr))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(lifecycle.ready()).To(BeTrue())
+			g.Expect(lifecycle.nodeClaimNames).To(Equal([]string{testNodePoolName + "-abcde"}))
+		})
+	}
+}
+
+func TestNodeProvisioningChecker_provisioningError(t *testing.T) {
+	created := time.Now()
+	nodeClaimName := testNodePoolName + "-abcde"
+	launched := condition(karpenter.ConditionTypeLaunched, metav1.ConditionTrue, karpenter.ConditionTypeLaunched, "", created.Add(40*time.Second))
+	registered := condition(karpenter.ConditionTypeRegistered, metav1.ConditionTrue, karpenter.ConditionTypeRegistered, "", created.Add(70*time.Second))
+	nodeClaimEvent := func(name, nodeClaimName, reason, message string, after time.Duration) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: name},
+			InvolvedObject: corev1.ObjectReference{Kind: "NodeClaim", Name: nodeClaimName},
+			Reason:         reason,
+			Message:        message,
+			LastTimestamp:  metav1.NewTime(created.Add(after)),
+		}
+	}
+
+	tests := []struct {
+		name           string
+		kubeObjects    []runtime.Object
+		dynamicObjects []runtime.Object
+		// observedNodeClaims are the NodeClaims observed while waiting for the node, which may have been deleted since.
+		observedNodeClaims []string
+		expectedCode       string
+		expectedMessage    string
+	}{
+		{
+			name:            "no NodeClaim",
+			dynamicObjects:  []runtime.Object{testNodePool(testNodePoolName, created)},
+			expectedCode:    ErrCodeNodeClaimCreationTimeout,
+			expectedMessage: "node was not provisioned: no NodeClaim was created for NodePool test-nodepool-1",
+		},
+		{
+			name: "node class of the NodePool not ready",
+			dynamicObjects: []runtime.Object{testNodePool(testNodePoolName, created,
+				condition(karpenter.ConditionTypeNodeClassReady, metav1.ConditionFalse, "NodeClassNotFound", "AKSNodeClass default not found", created),
+			)},
+			expectedCode:    ErrCodeNodePoolNotReady,
+			expectedMessage: "node was not provisioned: NodePool test-nodepool-1 is not ready: NodeClassNotFound: AKSNodeClass default not found",
+		},
+		{
+			name: "NodeClaim deleted after insufficient capacity",
+			kubeObjects: []runtime.Object{
+				nodeClaimEvent("ice", nodeClaimName, "InsufficientCapacityError", "NodeClaim test-nodepool-1-abcde event: all requested instance types were unavailable",
+					5*time.Second),
+				nodeClaimEvent("other-nodepool", "other-nodepool-abcde", "NodeClassNotReady", "node class not ready", 10*time.Second),
+			},
+			observedNodeClaims: []string{nodeClaimName},
+			expectedCode:       ErrCodeInsufficientCapacity,
+			expectedMessage:    "node was not provisioned: NodeClaim test-nodepool-1-abcde event: all requested instance types were unavailable",
+		},
+		{
+			name: "NodeClaim failed to launch",
+			dynamicObjects: []runtime.Object{testNodeClaim(nodeClaimName, testNodePoolName, created, "",
+				condition(karpenter.ConditionTypeLaunched, metav1.ConditionUnknown, "LaunchFailed", "quota exceeded", created),
+			)},
+			expectedCode:    ErrCodeNodeClaimLaunchFailed,
+			expectedMessage: "node was not provisioned: NodeClaim test-nodepool-1-abcde failed to launch: LaunchFailed: quota exceeded",
+		},
+		{
+			name: "NodeClaim not launched",
+			dynamicObjects: []runtime.Object{testNodeClaim(nodeClaimName, testNodePoolName, created, "",
+				condition(karpenter.ConditionTypeLaunched, metav1.ConditionUnknown, "AwaitingReconciliation", "object is awaiting reconciliation", created),
+			)},
+			expectedCode: ErrCodeNodeClaimLaunchTimeout,
+		},
+		{
+			name: "NodeClaim failed to register",
+			dynamicObjects: []runtime.Object{testNodeClaim(nodeClaimName, testNodePoolName, created, "", launched,
+				condition(karpenter.ConditionTypeRegistered, metav1.ConditionFalse, "MultipleNodesFound", "Invariant violated, matched multiple nodes", created),
+			)},
+			expectedCode: ErrCodeNodeClaimRegistrationFailed,
+		},
+		{
+			name:           "NodeClaim not registered",
+			dynamicObjects: []runtime.Object{testNodeClaim(nodeClaimName, testNodePoolName, created, "", launched)},
+			expectedCode:   ErrCodeNodeClaimRegistrationTimeout,
+		},
+		{
+			name:           "node not ready",
+			kubeObjects:    []runtime.Object{testNode("aks-node-1", testNodePoolName, corev1.ConditionFalse, created)},
+			dynamicObjects: []runtime.Object{testNodeClaim(nodeClaimName, testNodePoolName, created, "aks-node-1", launched, registered)},
+			expectedCode:   ErrCodeNodeReadyTimeout,
+		},
+		{
+			name:        "NodeClaim not initialized",
+			kubeObjects: []runtime.Object{testNode("aks-node-1", testNodePoolName, corev1.ConditionTrue, created)},
+			dynamicObjects: []runtime.Object{testNodeClaim(nodeClaimName, testNodePoolName, created, "aks-node-1", launched, registered,
+				condition(karpenter.ConditionTypeInitialized, metav1.ConditionUnknown, "StartupTaintsExist", `StartupTaint "node.cilium.io/agent-not-ready" still exists`, created),
+			)},
+			expectedCode:    ErrCodeNodeClaimInitializationTimeout,
+			expectedMessage: `node was not provisioned: NodeClaim test-nodepool-1-abcde was not initialized: StartupTaint "node.cilium.io/agent-not-ready" still exists`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			kubeClient := k8sfake.NewClientset(tt.kubeObjects...)
+			var eventSelectors []string
+			kubeClient.PrependReactor("list", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				selector := action.(k8stesting.ListAction).GetListRestrictions().Fields
+				eventSelectors = append(eventSelectors, selector.String())
+				// The fake clientset ignores field selectors, which the API server supports for the involved object of events.
+				events := &corev1.EventList{}
+				for _, object := range tt.kubeObjects {
+					event, ok := object.(*corev1.Event)
+					if ok && selector.Matches(fields.Set{"involvedObject.kind": event.InvolvedObject.Kind, "involvedObject.name": event.InvolvedObject.Name}) {
+						events.Items = append(events.Items, *event)
+					}
+				}
+				return true, events, nil
+			})
+			checker := newTestChecker(kubeClient, newTestDynamicClient(tt.dynamicObjects...))
+
+			// The context of the checker run has already expired when the provisioning is diagnosed.
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			lifecycle := &nodeLifecycle{nodePoolName: testNodePoolName, nodePoolCreated: created, nodeClaimNames: tt.observedNodeClaims}
+			err := checker.provisioningError(ctx, lifecycle)
+			g.Expect(err.code).To(Equal(tt.expectedCode))
+			if tt.expectedMessage != "" {
+				g.Expect(err.Error()).To(Equal(tt.expectedMessage))
+			}
+			// The events are only listed for the NodeClaims of the NodePool, which are all NodeClaims that were observed.
+			var expectedSelectors []string
+			for _, name := range lifecycle.nodeClaimNames {
+				expectedSelectors = append(expectedSelectors, "involvedObject.kind=NodeClaim,involvedObject.name="+name)
+			}
+			for _, selector := range eventSelectors {
+				g.Expect(expectedSelectors).To(ContainElement(selector))
+			}
+		})
+	}
+}
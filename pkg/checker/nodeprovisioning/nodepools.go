@@ -0,0 +1,208 @@
+package nodeprovisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	karpenter "sigs.k8s.io/karpenter/pkg/apis/v1"
+
+	"github.com/Azure/cluster-health-monitor/pkg/karpenterclient"
+)
+
+// triggerContainerName is the name of the container of the pod that triggers the node provisioning.
+const triggerContainerName = "trigger"
+
+// checkerLabels returns the labels applied to the NodePools, NodeClaims, nodes and pods created for this checker. The checker's name is a
+// unique identifier for each checker, and the configuration validates that it is a valid label value.
+func (c *NodeProvisioningChecker) checkerLabels() map[string]string {
+	return map[string]string{
+		c.config.LabelKey: c.name,
+	}
+}
+
+func (c *NodeProvisioningChecker) checkerLabelSelector() string {
+	return labels.SelectorFromSet(labels.Set(c.checkerLabels())).String()
+}
+
+// garbageCollect deletes the NodePools and trigger pods left over from previous runs of this checker that may not have been deleted, e.g.
+// because the checker was restarted during a run. Deleting a NodePool makes Karpenter delete its NodeClaims and nodes. NodeClaims that
+// outlived their NodePool are deleted too.
+func (c *NodeProvisioningChecker) garbageCollect(ctx context.Context) error {
+	var errs []error
+
+	nodePools, err := c.dynamicClient.Resource(karpenterclient.NodePoolGVR).List(ctx,
+		metav1.ListOptions{LabelSelector: c.checkerLabelSelector()})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to list NodePools for garbage collection: %w", err))
+	} else {
+		for _, nodePool := range nodePools.Items {
+			if time.Since(nodePool.GetCreationTimestamp().Time) <= c.timeout {
+				continue
+			}
+			if err := karpenterclient.DeleteNodePool(ctx, c.dynamicClient, nodePool.GetName()); err != nil {
+				errs = append(errs, fmt.Errorf("failed to delete old NodePool %s: %w", nodePool.GetName(), err))
+			}
+		}
+	}
+
+	nodeClaims, err := c.dynamicClient.Resource(karpenterclient.NodeClaimGVR).List(ctx,
+		metav1.ListOptions{LabelSelector: c.checkerLabelSelector()})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to list NodeClaims for garbage collection: %w", err))
+	} else {
+		for _, nodeClaim := range nodeClaims.Items {
+			if time.Since(nodeClaim.GetCreationTimestamp().Time) <= c.timeout || nodeClaim.GetDeletionTimestamp() != nil {
+				continue
+			}
+			nodePool, err := karpenterclient.GetNodePool(ctx, c.dynamicClient, nodeClaim.GetLabels()[karpenter.NodePoolLabelKey])
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if nodePool != nil {
+				continue
+			}
+			err = c.dynamicClient.Resource(karpenterclient.NodeClaimGVR).Delete(ctx, nodeClaim.GetName(), metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to delete old NodeClaim %s: %w", nodeClaim.GetName(), err))
+			}
+		}
+	}
+
+	pods, err := c.k8sClientset.CoreV1().Pods(c.config.Namespace).List(ctx, metav1.ListOptions{LabelSelector: c.checkerLabelSelector()})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("failed to list trigger pods for garbage collection: %w", err))
+	} else {
+		for _, pod := range pods.Items {
+			if time.Since(pod.CreationTimestamp.Time) <= c.timeout {
+				continue
+			}
+			err := c.k8sClientset.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{})
+			if err != nil && !apierrors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("failed to delete old trigger pod %s: %w", pod.Name, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// nodePool returns the NodePool of a run. Its nodes are labeled and tainted with the checker's label, so that only the trigger pod is
+// scheduled on them and Karpenter consolidation cannot disrupt unrelated workloads.
+func (c *NodeProvisioningChecker) nodePool(nodePoolName string) *karpenter.NodePool {
+	return &karpenter.NodePool{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NodePool",
+			APIVersion: "karpenter.sh/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   nodePoolName,
+			Labels: c.checkerLabels(),
+		},
+		Spec: karpenter.NodePoolSpec{
+			Template: karpenter.NodeClaimTemplate{
+				ObjectMeta: karpenter.ObjectMeta{
+					Labels: c.checkerLabels(),
+				},
+				Spec: karpenter.NodeClaimTemplateSpec{
+					NodeClassRef: c.nodeClassRef(),
+					Requirements: c.nodePoolRequirements(),
+					Taints: []corev1.Taint{
+						{
+							Key:    c.config.LabelKey,
+							Value:  c.name,
+							Effect: corev1.TaintEffectNoSchedule,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// nodeClassRef returns the node class of the NodePool, the AKSNodeClass named default unless configured otherwise.
+func (c *NodeProvisioningChecker) nodeClassRef() *karpenter.NodeClassReference {
+	if c.config.NodePool != nil && c.config.NodePool.NodeClassRef != nil {
+		ref := c.config.NodePool.NodeClassRef
+		return &karpenter.NodeClassReference{Group: ref.Group, Kind: ref.Kind, Name: ref.Name}
+	}
+	return &karpenter.NodeClassReference{
+		Group: "karpenter.azure.com",
+		Kind:  "AKSNodeClass",
+		Name:  "default",
+	}
+}
+
+// nodePoolRequirements returns the node requirements of the NodePool, the amd64 architecture unless configured otherwise.
+func (c *NodeProvisioningChecker) nodePoolRequirements() []karpenter.NodeSelectorRequirementWithMinValues {
+	if c.config.NodePool != nil && len(c.config.NodePool.Requirements) > 0 {
+		requirements := make([]karpenter.NodeSelectorRequirementWithMinValues, 0, len(c.config.NodePool.Requirements))
+		for _, requirement := range c.config.NodePool.Requirements {
+			requirements = append(requirements, karpenter.NodeSelectorRequirementWithMinValues{
+				NodeSelectorRequirement: corev1.NodeSelectorRequirement{
+					Key:      requirement.Key,
+					Operator: corev1.NodeSelectorOperator(requirement.Operator),
+					Values:   requirement.Values,
+				},
+			})
+		}
+		return requirements
+	}
+	return []karpenter.NodeSelectorRequirementWithMinValues{
+		{
+			// Restrict to amd64 skus. In certain regions, arm64 skus have limited capacity and fail to scale up within the checker timeout
+			// when selected.
+			NodeSelectorRequirement: corev1.NodeSelectorRequirement{
+				Key:      corev1.LabelArchStable,
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   []string{karpenter.ArchitectureAmd64},
+			},
+		},
+	}
+}
+
+// triggerPod returns the pod that makes Karpenter provision a node of the NodePool. It can only be scheduled on the nodes of the NodePool,
+// and is not required to start.
+func (c *NodeProvisioningChecker) triggerPod(podName, nodePoolName string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      podName,
+			Namespace: c.config.Namespace,
+			Labels:    c.checkerLabels(),
+		},
+		Spec: corev1.PodSpec{
+			NodeSelector: map[string]string{
+				karpenter.NodePoolLabelKey: nodePoolName,
+				corev1.LabelOSStable:       "linux",
+			},
+			Tolerations: []corev1.Toleration{
+				{
+					Key:      c.config.LabelKey,
+					Operator: corev1.TolerationOpEqual,
+					Value:    c.name,
+					Effect:   corev1.TaintEffectNoSchedule,
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:  triggerContainerName,
+					Image: c.config.TriggerPodImage(),
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("10m"),
+							corev1.ResourceMemory: resource.MustParse("16Mi"),
+						},
+					},
+				},
+			},
+			TerminationGracePeriodSeconds: new(int64),
+		},
+	}
+}
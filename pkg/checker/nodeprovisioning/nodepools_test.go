@@ -0,0 +1,133 @@
+package nodeprovisioning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Azure/cluster-health-monitor/pkg/config"
+	"github.com/Azure/cluster-health-monitor/pkg/karpenterclient"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	karpenter "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+func TestNodeProvisioningChecker_nodePool(t *testing.T) {
+	g := NewWithT(t)
+	checker := newTestChecker(k8sfake.NewClientset(), newTestDynamicClient())
+
+	nodePool := checker.nodePool(testNodePoolName)
+	g.Expect(nodePool.Name).To(Equal(testNodePoolName))
+	g.Expect(nodePool.Labels).To(Equal(map[string]string{testLabelKey: testCheckerName}))
+	g.Expect(nodePool.Spec.Template.Labels).To(Equal(map[string]string{testLabelKey: testCheckerName}))
+	g.Expect(nodePool.Spec.Template.Spec.Taints).To(Equal([]corev1.Taint{
+		{Key: testLabelKey, Value: testCheckerName, Effect: corev1.TaintEffectNoSchedule},
+	}))
+	g.Expect(nodePool.Spec.Template.Spec.NodeClassRef).To(Equal(&karpenter.NodeClassReference{
+		Group: "karpenter.azure.com",
+		Kind:  "AKSNodeClass",
+		Name:  "default",
+	}))
+	g.Expect(nodePool.Spec.Template.Spec.Requirements).To(HaveLen(1))
+	g.Expect(nodePool.Spec.Template.Spec.Requirements[0].Key).To(Equal(corev1.LabelArchStable))
+	g.Expect(nodePool.Spec.Template.Spec.Requirements[0].Values).To(Equal([]string{karpenter.ArchitectureAmd64}))
+
+	checker.config.NodePool = &config.NodePoolConfig{
+		NodeClassRef: &config.NodeClassRef{Group: "karpenter.azure.com", Kind: "AKSNodeClass", Name: "system-surge"},
+		Requirements: []config.NodeRequirement{
+			{Key: "karpenter.azure.com/sku-family", Operator: "In", Values: []string{"D"}},
+		},
+	}
+	nodePool = checker.nodePool(testNodePoolName)
+	g.Expect(nodePool.Spec.Template.Spec.NodeClassRef.Name).To(Equal("system-surge"))
+	g.Expect(nodePool.Spec.Template.Spec.Requirements).To(Equal([]karpenter.NodeSelectorRequirementWithMinValues{
+		{NodeSelectorRequirement: corev1.NodeSelectorRequirement{
+			Key:      "karpenter.azure.com/sku-family",
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{"D"},
+		}},
+	}))
+}
+
+func TestNodeProvisioningChecker_triggerPod(t *testing.T) {
+	g := NewWithT(t)
+	checker := newTestChecker(k8sfake.NewClientset(), newTestDynamicClient())
+
+	pod := checker.triggerPod("test-trigger-1", testNodePoolName)
+	g.Expect(pod.Name).To(Equal("test-trigger-1"))
+	g.Expect(pod.Namespace).To(Equal(testNamespace))
+	g.Expect(pod.Labels).To(Equal(map[string]string{testLabelKey: testCheckerName}))
+	g.Expect(pod.Spec.NodeSelector).To(Equal(map[string]string{
+		karpenter.NodePoolLabelKey: testNodePoolName,
+		corev1.LabelOSStable:       "linux",
+	}))
+	g.Expect(pod.Spec.Tolerations).To(Equal([]corev1.Toleration{
+		{Key: testLabelKey, Operator: corev1.TolerationOpEqual, Value: testCheckerName, Effect: corev1.TaintEffectNoSchedule},
+	}))
+	g.Expect(pod.Spec.Containers).To(HaveLen(1))
+	g.Expect(pod.Spec.Containers[0].Image).To(Equal(config.DefaultSyntheticPodImage))
+	g.Expect(pod.Spec.Containers[0].Resources.Requests).To(Equal(corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("10m"),
+		corev1.ResourceMemory: resource.MustParse("16Mi"),
+	}))
+
+	checker.config.Image = "example.azurecr.io/pause:3.10"
+	pod = checker.triggerPod("test-trigger-1", testNodePoolName)
+	g.Expect(pod.Spec.Containers[0].Image).To(Equal("example.azurecr.io/pause:3.10"))
+}
+
+func TestNodeProvisioningChecker_garbageCollect(t *testing.T) {
+	g := NewWithT(t)
+	now := time.Now()
+	old := now.Add(-time.Hour)
+
+	triggerPod := func(name string, created time.Time) runtime.Object {
+		return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         testNamespace,
+			CreationTimestamp: metav1.NewTime(created),
+			Labels:            map[string]string{testLabelKey: testCheckerName},
+		}}
+	}
+	otherCheckerNodePool := testNodePool("other-nodepool", old)
+	otherCheckerNodePool.SetLabels(map[string]string{testLabelKey: "other"})
+
+	kubeClient := k8sfake.NewClientset(
+		triggerPod("old-trigger", old),
+		triggerPod("new-trigger", now),
+	)
+	dynamicClient := newTestDynamicClient(
+		testNodePool("old-nodepool", old),
+		testNodePool("new-nodepool", now),
+		otherCheckerNodePool,
+		// The NodeClaim of a deleted NodePool.
+		testNodeClaim("deleted-nodepool-abcde", "deleted-nodepool", old, ""),
+		// The NodeClaim of a NodePool that is kept.
+		testNodeClaim("new-nodepool-abcde", "new-nodepool", old, ""),
+	)
+	checker := newTestChecker(kubeClient, dynamicClient)
+
+	g.Expect(checker.garbageCollect(context.Background())).To(Succeed())
+
+	nodePools, err := dynamicClient.Resource(karpenterclient.NodePoolGVR).List(context.Background(), metav1.ListOptions{})
+	g.Expect(err).ToNot(HaveOccurred())
+	var nodePoolNames []string
+	for _, nodePool := range nodePools.Items {
+		nodePoolNames = append(nodePoolNames, nodePool.GetName())
+	}
+	g.Expect(nodePoolNames).To(ConsistOf("new-nodepool", "other-nodepool"))
+
+	nodeClaims, err := karpenterclient.ListNodeClaims(context.Background(), dynamicClient, "")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(nodeClaims).To(HaveLen(1))
+	g.Expect(nodeClaims[0].Name).To(Equal("new-nodepool-abcde"))
+
+	pods, err := kubeClient.CoreV1().Pods(testNamespace).List(context.Background(), metav1.ListOptions{})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(pods.Items).To(HaveLen(1))
+	g.Expect(pods.Items[0].Name).To(Equal("new-trigger"))
+}
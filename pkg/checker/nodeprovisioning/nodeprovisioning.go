@@ -0,0 +1,181 @@
+// Package nodeprovisioning provides a checker for the node provisioning of Karpenter.
+package nodeprovisioning
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/cluster-health-monitor/pkg/checker"
+	"github.com/Azure/cluster-health-monitor/pkg/config"
+	"github.com/Azure/cluster-health-monitor/pkg/karpenterclient"
+)
+
+// How long to try deleting the objects of a run after it ended, e.g. because the checker timed out.
+var cleanupTimeout = 30 * time.Second
+
+// NodeProvisioningChecker implements the Checker interface for node provisioning checks.
+type NodeProvisioningChecker struct {
+	name          string
+	config        *config.NodeProvisioningConfig
+	timeout       time.Duration
+	k8sClientset  kubernetes.Interface
+	dynamicClient dynamic.Interface // to interact with Karpenter's custom resources
+}
+
+func Register() {
+	checker.RegisterChecker(config.CheckTypeNodeProvisioning, BuildNodeProvisioningChecker)
+}
+
+// BuildNodeProvisioningChecker creates a new NodeProvisioningChecker instance.
+func BuildNodeProvisioningChecker(config *config.CheckerConfig, kubeClient kubernetes.Interface, restConfig *rest.Config) (checker.Checker, error) {
+	chk := &NodeProvisioningChecker{
+		name:         config.Name,
+		config:       config.NodeProvisioningConfig,
+		timeout:      config.Timeout,
+		k8sClientset: kubeClient,
+	}
+	klog.InfoS("Built NodeProvisioningChecker",
+		"name", chk.name,
+		"config", chk.config,
+		"timeout", chk.timeout.String(),
+	)
+
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	chk.dynamicClient = dynamicClient
+
+	return chk, nil
+}
+
+func (c *NodeProvisioningChecker) Name() string {
+	return c.name
+}
+
+func (c *NodeProvisioningChecker) Type() config.CheckerType {
+	return config.CheckTypeNodeProvisioning
+}
+
+func (c *NodeProvisioningChecker) Run(ctx context.Context) {
+	start := time.Now()
+	result, err := c.check(ctx)
+	checker.RecordResult(c, result, err)
+	checker.RecordDuration(c, result, err, time.Since(start))
+}
+
+// check executes the node provisioning check. It creates a Karpenter NodePool and a pod that can only be scheduled on its nodes, which
+// makes Karpenter create a NodeClaim and provision a node for it. The check is healthy if the node becomes ready and initialized before the
+// checker times out. The time from the creation of the NodePool until the NodeClaim is created, launched, registered, its node is ready and
+// it is initialized is recorded in the phase duration histogram. If the node is not provisioned, the result names the failure reported by
+// Karpenter, or the stage the provisioning was stuck in. Before each run, the checker garbage collects the NodePools, NodeClaims and pods
+// left over from previous runs.
+func (c *NodeProvisioningChecker) check(ctx context.Context) (*checker.Result, error) {
+	if err := c.garbageCollect(ctx); err != nil {
+		// Logging instead of returning an error here to avoid failing the checker run.
+		klog.ErrorS(err, "Failed to garbage collect old node provisioning objects")
+	}
+
+	crdPresent, err := karpenterclient.IsNodePoolCRDPresent(ctx, c.dynamicClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check Karpenter NodePool CRD presence: %w", err)
+	}
+	if !crdPresent {
+		return checker.Skipped("Karpenter NodePool CRD was not found, node provisioning test was skipped"), nil
+	}
+
+	// Do not run the checker if the maximum number of NodePools has been reached.
+	nodePools, err := c.dynamicClient.Resource(karpenterclient.NodePoolGVR).List(ctx,
+		metav1.ListOptions{LabelSelector: c.checkerLabelSelector()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list NodePools: %w", err)
+	}
+	if len(nodePools.Items) >= c.config.MaxNodePools {
+		return nil, fmt.Errorf("maximum number of NodePools reached, current: %d, max allowed: %d, delete some NodePools before running the checker again",
+			len(nodePools.Items), c.config.MaxNodePools)
+	}
+
+	timestampStr := fmt.Sprintf("%d", time.Now().UnixNano())
+	nodePoolName := fmt.Sprintf("%s-nodepool-%s", strings.ToLower(c.name), timestampStr)
+	podName := fmt.Sprintf("%s-trigger-%s", strings.ToLower(c.name), timestampStr)
+
+	// The objects are deleted even if the checker timed out, so that the provisioned node does not outlive the run.
+	defer func() {
+		cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cleanupTimeout)
+		defer cancel()
+		err := c.k8sClientset.CoreV1().Pods(c.config.Namespace).Delete(cleanupCtx, podName, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Failed to delete trigger pod", "name", podName)
+		}
+		if err := karpenterclient.DeleteNodePool(cleanupCtx, c.dynamicClient, nodePoolName); err != nil {
+			klog.ErrorS(err, "Failed to delete Karpenter NodePool", "name", nodePoolName)
+		}
+	}()
+
+	createStart := time.Now()
+	created, err := karpenterclient.CreateNodePool(ctx, c.dynamicClient, c.nodePool(nodePoolName))
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return checker.Unhealthy(ErrCodeNodePoolCreationError, "timed out creating Karpenter NodePool"), nil
+		}
+		return checker.Unhealthy(ErrCodeNodePoolCreationError, fmt.Sprintf("error creating Karpenter NodePool: %s", err)), nil
+	}
+	nodePoolCreated := created.CreationTimestamp.Time
+	if nodePoolCreated.IsZero() {
+		nodePoolCreated = createStart
+	}
+
+	if _, err := c.k8sClientset.CoreV1().Pods(c.config.Namespace).Create(ctx, c.triggerPod(podName, nodePoolName), metav1.CreateOptions{}); err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return checker.Unhealthy(ErrCodeTriggerPodCreationError, "timed out creating trigger pod"), nil
+		}
+		return checker.Unhealthy(ErrCodeTriggerPodCreationError, fmt.Sprintf("error creating trigger pod: %s", err)), nil
+	}
+
+	lifecycle := &nodeLifecycle{nodePoolName: nodePoolName, nodePoolCreated: nodePoolCreated}
+	waitErr := c.waitForNode(ctx, lifecycle)
+	for _, phase := range lifecycle.phases() {
+		checker.RecordPhaseDuration(c, phase.name, phase.duration)
+	}
+	if waitErr != nil {
+		if !errors.Is(waitErr, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("failed waiting for node of NodePool %s: %w", nodePoolName, waitErr)
+		}
+		provisioningErr := c.provisioningError(ctx, lifecycle)
+		klog.V(3).InfoS("Node was not provisioned",
+			"checker", c.name,
+			"nodePool", nodePoolName,
+			"code", provisioningErr.code,
+			"phases", formatPhases(lifecycle.phases()),
+		)
+		return checker.Unhealthy(provisioningErr.code, provisioningErr.Error()), nil
+	}
+
+	klog.V(3).InfoS("Node was provisioned",
+		"checker", c.name,
+		"nodePool", nodePoolName,
+		"nodeClaim", lifecycle.nodeClaim.Name,
+		"node", lifecycle.node.Name,
+		"phases", formatPhases(lifecycle.phases()),
+	)
+	return checker.Healthy(), nil
+}
+
+// formatPhases formats the phases for logging, e.g. "nodeclaim_created=1s launched=40s".
+func formatPhases(phases []provisioningPhase) string {
+	parts := make([]string, 0, len(phases))
+	for _, phase := range phases {
+		parts = append(parts, fmt.Sprintf("%s=%s", phase.name, phase.duration))
+	}
+	return strings.Join(parts, " ")
+}
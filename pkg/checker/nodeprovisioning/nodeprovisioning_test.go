@@ -0,0 +1,162 @@
+package nodeprovisioning
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/cluster-health-monitor/pkg/checker"
+	"github.com/Azure/cluster-health-monitor/pkg/karpenterclient"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	karpenter "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+func TestNodeProvisioningChecker_check(t *testing.T) {
+	defaultPollingInterval := pollingInterval
+	pollingInterval = 10 * time.Millisecond
+	t.Cleanup(func() { pollingInterval = defaultPollingInterval })
+
+	nodePoolCRD := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata": map[string]interface{}{
+			"name": "nodepools.karpenter.sh",
+		},
+	}}
+
+	// provisionOnTriggerPod provisions a node of the NodePool selected by the trigger pod when the pod is created.
+	provisionOnTriggerPod := func(kubeClient *k8sfake.Clientset, dynamicClient *dynamicfake.FakeDynamicClient) {
+		kubeClient.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+			nodeClaim, node := provisionedNodeClaim(pod.Spec.NodeSelector[karpenter.NodePoolLabelKey], time.Now())
+			if err := dynamicClient.Tracker().Create(karpenterclient.NodeClaimGVR, nodeClaim, ""); err != nil {
+				return true, nil, err
+			}
+			if err := kubeClient.Tracker().Add(node); err != nil {
+				return true, nil, err
+			}
+			return false, nil, nil
+		})
+	}
+
+	tests := []struct {
+		name string
+		// dynamicObjects are added along with the NodePool CRD, which is left out if they are empty but not nil.
+		dynamicObjects  []runtime.Object
+		setup           func(kubeClient *k8sfake.Clientset, dynamicClient *dynamicfake.FakeDynamicClient)
+		timeout         time.Duration
+		validateResult  func(g *WithT, result *checker.Result, err error)
+		expectedCleanup bool
+	}{
+		{
+			name:  "healthy result",
+			setup: provisionOnTriggerPod,
+			validateResult: func(g *WithT, result *checker.Result, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result.Status).To(Equal(checker.StatusHealthy))
+			},
+			expectedCleanup: true,
+		},
+		{
+			name:           "Karpenter NodePool CRD not found",
+			dynamicObjects: []runtime.Object{},
+			validateResult: func(g *WithT, result *checker.Result, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result.Status).To(Equal(checker.StatusSkipped))
+			},
+		},
+		{
+			name: "maximum number of NodePools reached",
+			dynamicObjects: []runtime.Object{
+				testNodePool("test-nodepool-1", time.Now()),
+				testNodePool("test-nodepool-2", time.Now()),
+			},
+			validateResult: func(g *WithT, result *checker.Result, err error) {
+				g.Expect(err).To(MatchError(ContainSubstring("maximum number of NodePools reached")))
+				g.Expect(result).To(BeNil())
+			},
+		},
+		{
+			name: "NodePool creation error",
+			setup: func(kubeClient *k8sfake.Clientset, dynamicClient *dynamicfake.FakeDynamicClient) {
+				dynamicClient.PrependReactor("create", "nodepools", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errors.New("admission webhook denied the request")
+				})
+			},
+			validateResult: func(g *WithT, result *checker.Result, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result.Status).To(Equal(checker.StatusUnhealthy))
+				g.Expect(result.Detail.Code).To(Equal(ErrCodeNodePoolCreationError))
+			},
+			expectedCleanup: true,
+		},
+		{
+			name: "trigger pod creation error",
+			setup: func(kubeClient *k8sfake.Clientset, dynamicClient *dynamicfake.FakeDynamicClient) {
+				kubeClient.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, errors.New("pods is forbidden")
+				})
+			},
+			validateResult: func(g *WithT, result *checker.Result, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result.Status).To(Equal(checker.StatusUnhealthy))
+				g.Expect(result.Detail.Code).To(Equal(ErrCodeTriggerPodCreationError))
+			},
+			expectedCleanup: true,
+		},
+		{
+			name:    "node not provisioned before timeout",
+			timeout: 200 * time.Millisecond,
+			validateResult: func(g *WithT, result *checker.Result, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result.Status).To(Equal(checker.StatusUnhealthy))
+				g.Expect(result.Detail.Code).To(Equal(ErrCodeNodeClaimCreationTimeout))
+			},
+			expectedCleanup: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			dynamicObjects := tt.dynamicObjects
+			if dynamicObjects == nil {
+				dynamicObjects = []runtime.Object{nodePoolCRD}
+			} else if len(dynamicObjects) > 0 {
+				dynamicObjects = append([]runtime.Object{nodePoolCRD}, dynamicObjects...)
+			}
+			kubeClient := k8sfake.NewClientset()
+			dynamicClient := newTestDynamicClient(dynamicObjects...)
+			if tt.setup != nil {
+				tt.setup(kubeClient, dynamicClient)
+			}
+
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = 5 * time.Second
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			result, err := newTestChecker(kubeClient, dynamicClient).check(ctx)
+			tt.validateResult(g, result, err)
+
+			if tt.expectedCleanup {
+				nodePools, err := dynamicClient.Resource(karpenterclient.NodePoolGVR).List(context.Background(), metav1.ListOptions{})
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(nodePools.Items).To(BeEmpty())
+				pods, err := kubeClient.CoreV1().Pods(testNamespace).List(context.Background(), metav1.ListOptions{})
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(pods.Items).To(BeEmpty())
+			}
+		})
+	}
+}
@@ -1,5 +1,7 @@
 package podstartup
 
+import "fmt"
+
 const (
 	// This is the error code of the PodStartupCheckers's result.
 	ErrCodePodCreationError           = "PodCreationError"
@@ -8,4 +10,93 @@ const (
 	ErrCodeRequestFailed              = "RequestFailed"
 	ErrCodeRequestTimeout             = "RequestTimeout"
 	ErrCodeStorageClassNotFound       = "StorageClassNotFound"
+
+	// Error codes for the synthetic pod's image. ImagePullError and ImagePullBackOff are returned when the container never started because
+	// its image could not be pulled, and ImagePullDurationExceeded when the image pull took longer than the configured threshold.
+	ErrCodeImagePullError            = "ImagePullError"
+	ErrCodeImagePullBackOff          = "ImagePullBackOff"
+	ErrCodeImagePullDurationExceeded = "ImagePullDurationExceeded"
+
+	// Error codes naming the startup phase that was the slowest when the pod startup exceeded SyntheticPodStartupTimeout, or the phase the
+	// synthetic pod was stuck in.
+	ErrCodePodSchedulingDurationExceeded     = "PodSchedulingDurationExceeded"
+	ErrCodeVolumeAttachDurationExceeded      = "VolumeAttachDurationExceeded"
+	ErrCodeSandboxCreationDurationExceeded   = "SandboxCreationDurationExceeded"
+	ErrCodePodInitializationDurationExceeded = "PodInitializationDurationExceeded"
+	ErrCodeContainerStartDurationExceeded    = "ContainerStartDurationExceeded"
+
+	// Error codes for the volume I/O of the synthetic pod when VolumeIO is enabled. VolumeWriteFailed and VolumeReadFailed are returned when
+	// a file could not be written to a volume, or not read back intact, and VolumeIOSlow when the I/O exceeded the configured threshold or
+	// did not complete.
+	ErrCodeVolumeWriteFailed = "VolumeWriteFailed"
+	ErrCodeVolumeReadFailed  = "VolumeReadFailed"
+	ErrCodeVolumeIOSlow      = "VolumeIOSlow"
+
+	// Error codes for the volumes of the synthetic pod when CSIs are enabled, returned when provisioning a PVC, attaching or mounting a volume
+	// exceeded the configured threshold, or did not complete before the checker timed out.
+	ErrCodePVCProvisioningTimeout = "PVCProvisioningTimeout"
+	ErrCodeVolumeAttachTimeout    = "VolumeAttachTimeout"
+	ErrCodeVolumeMountTimeout     = "VolumeMountTimeout"
 )
+
+// Waiting reasons of a container whose image cannot be pulled.
+const (
+	waitingReasonErrImagePull     = "ErrImagePull"
+	waitingReasonImagePullBackOff = "ImagePullBackOff"
+)
+
+// imagePullError is returned when the synthetic pod's container did not start because its image could not be pulled.
+type imagePullError struct {
+	reason  string
+	message string
+}
+
+func (e *imagePullError) Error() string {
+	return fmt.Sprintf("container is waiting with reason %s: %s", e.reason, e.message)
+}
+
+// code returns the error code of the checker's result for the image pull failure.
+func (e *imagePullError) code() string {
+	if e.reason == waitingReasonImagePullBackOff {
+		return ErrCodeImagePullBackOff
+	}
+	return ErrCodeImagePullError
+}
+
+func isImagePullFailure(reason string) bool {
+	return reason == waitingReasonErrImagePull || reason == waitingReasonImagePullBackOff
+}
+
+// startupPhaseError is returned when the synthetic pod's container did not start because the pod was stuck in a startup phase, e.g. it
+// could not be scheduled.
+type startupPhaseError struct {
+	phase   string
+	reason  string
+	message string
+}
+
+func (e *startupPhaseError) Error() string {
+	return fmt.Sprintf("pod is stuck in startup phase %s: %s: %s", e.phase, e.reason, e.message)
+}
+
+// code returns the error code of the checker's result for the stuck phase.
+func (e *startupPhaseError) code() string {
+	return phaseErrCodes[e.phase]
+}
+
+// storageOperationError is returned when the synthetic pod's container did not start because an operation on one of its volumes did not
+// complete, e.g. its PVC was not provisioned.
+type storageOperationError struct {
+	operation    string
+	storageClass string
+	message      string
+}
+
+func (e *storageOperationError) Error() string {
+	return fmt.Sprintf("%s of the %s volume did not complete: %s", e.operation, e.storageClass, e.message)
+}
+
+// code returns the error code of the checker's result for the storage operation that did not complete.
+func (e *storageOperationError) code() string {
+	return storageOperationErrCodes[e.operation]
+}
@@ -6,61 +6,18 @@ import (
 	"fmt"
 
 	v1 "k8s.io/api/core/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
-	"k8s.io/kubectl/pkg/scheme"
 	karpenter "sigs.k8s.io/karpenter/pkg/apis/v1"
-)
-
-func (c *PodStartupChecker) isKarpenterNodePoolCRDPresent(ctx context.Context) (bool, error) {
-	// Check if the Karpenter NodePool CRD is present in the cluster.
-	customerresourcedefinitionsGVR := schema.GroupVersionResource{
-		Group:    "apiextensions.k8s.io",
-		Version:  "v1",
-		Resource: "customresourcedefinitions",
-	}
-	_, err := c.dynamicClient.Resource(customerresourcedefinitionsGVR).Get(ctx, "nodepools.karpenter.sh", metav1.GetOptions{})
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			return false, nil // CRD doesn't exist, but this is not an error condition
-		}
-		return false, err // Other errors should be returned
-	}
-	return true, nil
-}
-
-func (c *PodStartupChecker) createKarpenterNodePool(ctx context.Context, nodePool *karpenter.NodePool) error {
-	unstructuredNodePool := &unstructured.Unstructured{}
-	scheme.Scheme.AddKnownTypes(NodePoolGVR.GroupVersion(), nodePool)
-	if err := scheme.Scheme.Convert(nodePool, unstructuredNodePool, nil); err != nil {
-		return err
-	}
-
-	// Create the NodePool resource.
-	if _, err := c.dynamicClient.Resource(NodePoolGVR).Create(ctx, unstructuredNodePool, metav1.CreateOptions{}); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func (c *PodStartupChecker) deleteKarpenterNodePool(ctx context.Context, nodePoolName string) error {
-	// Delete the NodePool resource.
-	err := c.dynamicClient.Resource(NodePoolGVR).Delete(ctx, nodePoolName, metav1.DeleteOptions{})
-	if err != nil && !apierrors.IsNotFound(err) {
-		return err
-	}
 
-	return nil
-}
+	"github.com/Azure/cluster-health-monitor/pkg/karpenterclient"
+)
 
 func (c *PodStartupChecker) deleteAllKarpenterNodePools(ctx context.Context) error {
 	var errs []error
 
 	// List all NodePools.
-	nodePools, err := c.dynamicClient.Resource(NodePoolGVR).List(ctx, metav1.ListOptions{
+	nodePools, err := c.dynamicClient.Resource(karpenterclient.NodePoolGVR).List(ctx, metav1.ListOptions{
 		LabelSelector: c.config.SyntheticPodLabelKey,
 	})
 	if err != nil {
@@ -80,7 +37,7 @@ func (c *PodStartupChecker) deleteAllKarpenterNodePools(ctx context.Context) err
 			continue
 		}
 
-		if err := c.deleteKarpenterNodePool(ctx, nodePoolName); err != nil {
+		if err := karpenterclient.DeleteNodePool(ctx, c.dynamicClient, nodePoolName); err != nil {
 			errs = append(errs, fmt.Errorf("failed to delete old Karpenter Node Pool %s: %w", nodePoolName, err))
 		}
 	}
@@ -109,22 +66,8 @@ func (c *PodStartupChecker) karpenterNodePool(nodePoolName, timestampStr string)
 					},
 				},
 				Spec: karpenter.NodeClaimTemplateSpec{
-					NodeClassRef: &karpenter.NodeClassReference{
-						Group: "karpenter.azure.com",
-						Kind:  "AKSNodeClass",
-						Name:  "default",
-					},
-					Requirements: []karpenter.NodeSelectorRequirementWithMinValues{
-						{
-							// Restrict to amd64 skus. In certain regions, arm64 skus have limited capacity and fail to scale up within the
-							// test timeout when selected.
-							NodeSelectorRequirement: v1.NodeSelectorRequirement{
-								Key:      v1.LabelArchStable,
-								Operator: v1.NodeSelectorOpIn,
-								Values:   []string{karpenter.ArchitectureAmd64},
-							},
-						},
-					},
+					NodeClassRef: c.nodeClassRef(),
+					Requirements: c.nodePoolRequirements(),
 					Taints: []v1.Taint{
 						{
 							// Prevent non-synthetic pods from scheduling here, so Karpenter consolidation can't disrupt unrelated workloads.
@@ -137,3 +80,44 @@ func (c *PodStartupChecker) karpenterNodePool(nodePoolName, timestampStr string)
 		},
 	}
 }
+
+// nodeClassRef returns the node class of the NodePool, the AKSNodeClass named default unless configured otherwise.
+func (c *PodStartupChecker) nodeClassRef() *karpenter.NodeClassReference {
+	if c.config.NodePool != nil && c.config.NodePool.NodeClassRef != nil {
+		ref := c.config.NodePool.NodeClassRef
+		return &karpenter.NodeClassReference{Group: ref.Group, Kind: ref.Kind, Name: ref.Name}
+	}
+	return &karpenter.NodeClassReference{
+		Group: "karpenter.azure.com",
+		Kind:  "AKSNodeClass",
+		Name:  "default",
+	}
+}
+
+// nodePoolRequirements returns the node requirements of the NodePool, the amd64 architecture unless configured otherwise.
+func (c *PodStartupChecker) nodePoolRequirements() []karpenter.NodeSelectorRequirementWithMinValues {
+	if c.config.NodePool != nil && len(c.config.NodePool.Requirements) > 0 {
+		requirements := make([]karpenter.NodeSelectorRequirementWithMinValues, 0, len(c.config.NodePool.Requirements))
+		for _, requirement := range c.config.NodePool.Requirements {
+			requirements = append(requirements, karpenter.NodeSelectorRequirementWithMinValues{
+				NodeSelectorRequirement: v1.NodeSelectorRequirement{
+					Key:      requirement.Key,
+					Operator: v1.NodeSelectorOperator(requirement.Operator),
+					Values:   requirement.Values,
+				},
+			})
+		}
+		return requirements
+	}
+	return []karpenter.NodeSelectorRequirementWithMinValues{
+		{
+			// Restrict to amd64 skus. In certain regions, arm64 skus have limited capacity and fail to scale up within the
+			// test timeout when selected.
+			NodeSelectorRequirement: v1.NodeSelectorRequirement{
+				Key:      v1.LabelArchStable,
+				Operator: v1.NodeSelectorOpIn,
+				Values:   []string{karpenter.ArchitectureAmd64},
+			},
+		},
+	}
+}
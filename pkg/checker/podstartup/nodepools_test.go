@@ -6,10 +6,10 @@ import (
 	"testing"
 
 	"github.com/Azure/cluster-health-monitor/pkg/config"
+	"github.com/Azure/cluster-health-monitor/pkg/karpenterclient"
 	. "github.com/onsi/gomega"
 
 	v1 "k8s.io/api/core/v1"
-	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -48,6 +48,13 @@ func TestKarpenterNodePool(t *testing.T) {
 		Effect: v1.TaintEffectNoSchedule,
 	}))
 
+	// default node class
+	g.Expect(karpenterNodePool.Spec.Template.Spec.NodeClassRef).To(Equal(&karpenter.NodeClassReference{
+		Group: "karpenter.azure.com",
+		Kind:  "AKSNodeClass",
+		Name:  "default",
+	}))
+
 	// amd64 architecture requirement
 	g.Expect(karpenterNodePool.Spec.Template.Spec.Requirements).To(ContainElement(karpenter.NodeSelectorRequirementWithMinValues{
 		NodeSelectorRequirement: v1.NodeSelectorRequirement{
@@ -58,119 +65,43 @@ func TestKarpenterNodePool(t *testing.T) {
 	}))
 }
 
-func TestCreateKarpenterNodePool(t *testing.T) {
+func TestKarpenterNodePool_NodePoolConfig(t *testing.T) {
 	g := NewWithT(t)
 
-	tests := []struct {
-		name                string
-		getClient           func() *dynamicfake.FakeDynamicClient
-		expectedErrorString string
-	}{
-		{
-			name: "successful creation",
-		},
-		{
-			name: "creation failure",
-			getClient: func() *dynamicfake.FakeDynamicClient {
-				client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
-				client.PrependReactor("create", "nodepools", func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
-					return true, &unstructured.Unstructured{}, errors.New("unexpected error occurred while creating node pool")
-				})
-				return client
-			},
-			expectedErrorString: "unexpected error occurred while creating node pool",
-		},
-	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctx := context.Background()
-			nodePoolName := "test-nodepool"
-			timestampStr := "123456"
-
-			fakeDynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
-			if tt.getClient != nil {
-				fakeDynamicClient = tt.getClient()
-			}
-
-			checker := &PodStartupChecker{
-				dynamicClient: fakeDynamicClient,
-				config: &config.PodStartupConfig{
-					SyntheticPodNamespace: "test",
-					SyntheticPodLabelKey:  _testSyntheticLabelKey,
+	checker := &PodStartupChecker{
+		config: &config.PodStartupConfig{
+			SyntheticPodNamespace: "test",
+			SyntheticPodLabelKey:  _testSyntheticLabelKey,
+			NodePool: &config.NodePoolConfig{
+				NodeClassRef: &config.NodeClassRef{Group: "karpenter.k8s.aws", Kind: "EC2NodeClass", Name: "synthetic"},
+				Requirements: []config.NodeRequirement{
+					{Key: v1.LabelArchStable, Operator: "In", Values: []string{karpenter.ArchitectureArm64}},
+					{Key: "karpenter.k8s.aws/instance-cpu", Operator: "Gt", Values: []string{"2"}},
 				},
-			}
-			err := checker.createKarpenterNodePool(ctx, checker.karpenterNodePool(nodePoolName, timestampStr))
-			if tt.expectedErrorString != "" {
-				g.Expect(err).To(HaveOccurred())
-				g.Expect(err.Error()).To(ContainSubstring(tt.expectedErrorString))
-			} else {
-				g.Expect(err).To(BeNil())
-			}
-		})
-	}
-}
-
-func TestDeleteKarpenterNodePool(t *testing.T) {
-	g := NewWithT(t)
-
-	tests := []struct {
-		name                string
-		getClient           func() *dynamicfake.FakeDynamicClient
-		expectedErrorString string
-	}{
-		{
-			name: "successful deletion",
-		},
-		{
-			name: "not found - skip without error",
-			getClient: func() *dynamicfake.FakeDynamicClient {
-				client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
-				client.PrependReactor("delete", "nodepools", func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
-					return true, &unstructured.Unstructured{}, apierrors.NewNotFound(
-						NodePoolGVR.GroupResource(),
-						"test-nodepool",
-					)
-				})
-				return client
-			},
-		},
-		{
-			name: "deletion failure",
-			getClient: func() *dynamicfake.FakeDynamicClient {
-				client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
-				client.PrependReactor("delete", "nodepools", func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
-					return true, &unstructured.Unstructured{}, errors.New("unexpected error occurred while deleting node pool")
-				})
-				return client
 			},
-			expectedErrorString: "unexpected error occurred while deleting node pool",
 		},
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			ctx := context.Background()
-			nodePoolName := "test-nodepool"
 
-			fakeDynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
-			if tt.getClient != nil {
-				fakeDynamicClient = tt.getClient()
-			}
+	karpenterNodePool := checker.karpenterNodePool("test-nodepool", "123456")
 
-			checker := &PodStartupChecker{
-				dynamicClient: fakeDynamicClient,
-				config: &config.PodStartupConfig{
-					SyntheticPodNamespace: "test",
-				},
-			}
-			err := checker.deleteKarpenterNodePool(ctx, nodePoolName)
-			if tt.expectedErrorString != "" {
-				g.Expect(err).To(HaveOccurred())
-				g.Expect(err.Error()).To(ContainSubstring(tt.expectedErrorString))
-			} else {
-				g.Expect(err).To(BeNil())
-			}
-		})
-	}
+	g.Expect(karpenterNodePool.Spec.Template.Spec.NodeClassRef).To(Equal(&karpenter.NodeClassReference{
+		Group: "karpenter.k8s.aws",
+		Kind:  "EC2NodeClass",
+		Name:  "synthetic",
+	}))
+	// the configured requirements replace the amd64 architecture requirement
+	g.Expect(karpenterNodePool.Spec.Template.Spec.Requirements).To(Equal([]karpenter.NodeSelectorRequirementWithMinValues{
+		{NodeSelectorRequirement: v1.NodeSelectorRequirement{
+			Key:      v1.LabelArchStable,
+			Operator: v1.NodeSelectorOpIn,
+			Values:   []string{karpenter.ArchitectureArm64},
+		}},
+		{NodeSelectorRequirement: v1.NodeSelectorRequirement{
+			Key:      "karpenter.k8s.aws/instance-cpu",
+			Operator: v1.NodeSelectorOpGt,
+			Values:   []string{"2"},
+		}},
+	}))
 }
 
 func TestDeleteAllKarpenterNodePools(t *testing.T) {
@@ -285,7 +216,7 @@ func TestDeleteAllKarpenterNodePools(t *testing.T) {
 			ctx := context.Background()
 
 			fakeDynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
-				NodePoolGVR: "NodePoolList",
+				karpenterclient.NodePoolGVR: "NodePoolList",
 			})
 			if tt.mutateClient != nil {
 				tt.mutateClient(fakeDynamicClient)
@@ -0,0 +1,226 @@
+package podstartup
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Reasons of the pod events used to derive the startup phases of the synthetic pod.
+const (
+	eventReasonPulled                 = "Pulled"
+	eventReasonScheduled              = "Scheduled"
+	eventReasonSuccessfulAttachVolume = "SuccessfulAttachVolume"
+	eventReasonFailedCreatePodSandBox = "FailedCreatePodSandBox"
+)
+
+// phaseErrCodes maps each startup phase to the error code of the result when the phase is the slowest of a pod startup that exceeded
+// SyntheticPodStartupTimeout, or the phase the pod was stuck in.
+var phaseErrCodes = map[string]string{
+	phaseScheduling:     ErrCodePodSchedulingDurationExceeded,
+	phaseVolumeAttach:   ErrCodeVolumeAttachDurationExceeded,
+	phaseSandbox:        ErrCodeSandboxCreationDurationExceeded,
+	phaseInitialization: ErrCodePodInitializationDurationExceeded,
+	phaseContainerStart: ErrCodeContainerStartDurationExceeded,
+}
+
+// startupPhase is the duration of one phase of the synthetic pod's startup.
+type startupPhase struct {
+	name     string
+	duration time.Duration
+	// detail is additional information about the phase, e.g. sandbox creation failures, reported if it is the slowest phase.
+	detail string
+}
+
+// startupTimeline holds the times at which the synthetic pod passed the boundaries of its startup phases. A zero time means that the
+// boundary is unknown.
+type startupTimeline struct {
+	created      time.Time
+	scheduled    time.Time
+	attached     time.Time
+	sandboxReady time.Time
+	initialized  time.Time
+	running      time.Time
+
+	sandboxFailures    int32
+	lastSandboxFailure string
+
+	// precision is the unit of the least precise time of the timeline.
+	precision time.Duration
+}
+
+// getStartupPhases breaks the startup of a pod with a running container down into phases, in the order in which they happen:
+//   - scheduling: from the pod's creation until the PodScheduled condition, or the Scheduled event if the condition is missing.
+//   - volume_attach: until the last SuccessfulAttachVolume event. Only present if the pod has attached volumes.
+//   - sandbox: until the PodReadyToStartContainers condition, i.e. the pod sandbox and its network were created.
+//   - initialization: until the Initialized condition, i.e. the init containers completed.
+//   - container_start: until the container started running, minus the image pull duration (including waiting).
+//
+// If the tracker watched the whole startup, the phases are derived from the local times at which the transitions arrived. Otherwise, they
+// are derived from the timestamps of the pod's conditions and events, which are precise to the second.
+func (c *PodStartupChecker) getStartupPhases(ctx context.Context, pod *corev1.Pod, tracker *podStartupTracker, imagePullDuration time.Duration) ([]startupPhase, error) {
+	if tracker != nil && tracker.watchedStartup() {
+		return tracker.timeline().phases(imagePullDuration), nil
+	}
+
+	events, err := c.k8sClientset.CoreV1().Events(c.config.SyntheticPodNamespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.name=%s", pod.Name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events for pod %s: %w", pod.Name, err)
+	}
+	return podTimeline(pod, events.Items).phases(imagePullDuration), nil
+}
+
+// podTimeline returns the startup timeline recorded in the conditions and events of the pod.
+func podTimeline(pod *corev1.Pod, events []corev1.Event) startupTimeline {
+	timeline := startupTimeline{
+		created:      pod.CreationTimestamp.Time,
+		scheduled:    conditionTime(pod, corev1.PodScheduled),
+		sandboxReady: conditionTime(pod, corev1.PodReadyToStartContainers),
+		initialized:  conditionTime(pod, corev1.PodInitialized),
+		running:      containerRunningTime(pod),
+		precision:    time.Second,
+	}
+	for _, event := range events {
+		if event.InvolvedObject.Name != pod.Name {
+			continue
+		}
+		switch event.Reason {
+		case eventReasonScheduled:
+			if conditionTime(pod, corev1.PodScheduled).IsZero() {
+				timeline.scheduled = eventTime(&event)
+			}
+		case eventReasonSuccessfulAttachVolume:
+			if t := eventTime(&event); t.After(timeline.attached) {
+				timeline.attached = t
+			}
+		}
+		timeline.addSandboxFailure(&event)
+	}
+	return timeline
+}
+
+// addSandboxFailure counts the event if it reports a failure to create the pod sandbox.
+func (t *startupTimeline) addSandboxFailure(event *corev1.Event) {
+	if event.Reason != eventReasonFailedCreatePodSandBox {
+		return
+	}
+	t.sandboxFailures += max(event.Count, 1)
+	t.lastSandboxFailure = event.Message
+}
+
+// phases returns the durations of the startup phases. Each phase starts when the latest of the preceding phases ended. A phase whose end
+// is unknown, e.g. because the cluster does not set the condition, is left out and its time is counted in the next phase.
+func (t startupTimeline) phases(imagePullDuration time.Duration) []startupPhase {
+	ends := []struct {
+		phase string
+		at    time.Time
+	}{
+		{phaseScheduling, t.scheduled},
+		{phaseVolumeAttach, t.attached},
+		{phaseSandbox, t.sandboxReady},
+		{phaseInitialization, t.initialized},
+		{phaseContainerStart, t.running},
+	}
+	var phases []startupPhase
+	start := t.created
+	for _, end := range ends {
+		if end.at.IsZero() {
+			continue
+		}
+		phase := startupPhase{name: end.phase, duration: max(end.at.Sub(start), 0)}
+		switch end.phase {
+		case phaseSandbox:
+			if t.sandboxFailures > 0 {
+				phase.detail = fmt.Sprintf("%d %s events, last: %s", t.sandboxFailures, eventReasonFailedCreatePodSandBox, t.lastSandboxFailure)
+			}
+		case phaseContainerStart:
+			phase.duration = max(phase.duration-imagePullDuration, 0).Round(t.precision)
+		}
+		phases = append(phases, phase)
+		if end.at.After(start) {
+			start = end.at
+		}
+	}
+	return phases
+}
+
+// slowestPhase returns the longest of the phases, or false if there are fewer than two phases, e.g. because the pod has no conditions, and
+// the breakdown does not tell which part of the startup was slow.
+func slowestPhase(phases []startupPhase) (startupPhase, bool) {
+	if len(phases) < 2 {
+		return startupPhase{}, false
+	}
+	slowest := phases[0]
+	for _, phase := range phases[1:] {
+		if phase.duration > slowest.duration {
+			slowest = phase
+		}
+	}
+	return slowest, true
+}
+
+// stalledPhaseError returns a *startupPhaseError for the phase a pod without a running container is stuck in according to its conditions,
+// or nil if none of its conditions is false.
+func stalledPhaseError(pod *corev1.Pod) error {
+	for _, cond := range []struct {
+		conditionType corev1.PodConditionType
+		phase         string
+	}{
+		{corev1.PodScheduled, phaseScheduling},
+		{corev1.PodReadyToStartContainers, phaseSandbox},
+		{corev1.PodInitialized, phaseInitialization},
+	} {
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == cond.conditionType && condition.Status == corev1.ConditionFalse {
+				return &startupPhaseError{phase: cond.phase, reason: condition.Reason, message: condition.Message}
+			}
+		}
+	}
+	return nil
+}
+
+// formatStartupPhases formats the phases for logging, e.g. "scheduling=1s sandbox=3s container_start=1s".
+func formatStartupPhases(phases []startupPhase) string {
+	parts := make([]string, 0, len(phases))
+	for _, phase := range phases {
+		parts = append(parts, fmt.Sprintf("%s=%s", phase.name, phase.duration))
+	}
+	return strings.Join(parts, " ")
+}
+
+// conditionTime returns when the condition of the pod became true, or the zero time if it is not true.
+func conditionTime(pod *corev1.Pod, conditionType corev1.PodConditionType) time.Time {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == corev1.ConditionTrue {
+			return condition.LastTransitionTime.Time
+		}
+	}
+	return time.Time{}
+}
+
+// containerRunningTime returns when the first running container of the pod started, or the zero time if none is running.
+func containerRunningTime(pod *corev1.Pod) time.Time {
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Running != nil {
+			return status.State.Running.StartedAt.Time
+		}
+	}
+	return time.Time{}
+}
+
+// eventTime returns the most precise time at which the event was last observed.
+func eventTime(event *corev1.Event) time.Time {
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	if !event.LastTimestamp.IsZero() {
+		return event.LastTimestamp.Time
+	}
+	return event.FirstTimestamp.Time
+}
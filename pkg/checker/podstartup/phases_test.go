@@ -0,0 +1,207 @@
+package podstartup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/cluster-health-monitor/pkg/config"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPodStartupChecker_getStartupPhases(t *testing.T) {
+	namespace := "test-namespace"
+	podName := "pod1"
+	created := time.Now()
+
+	runningPod := func(runningAfter time.Duration, conditions ...corev1.PodCondition) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: namespace, CreationTimestamp: metav1.NewTime(created)},
+			Status: corev1.PodStatus{
+				Conditions: conditions,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(created.Add(runningAfter))},
+					},
+				}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name              string
+		pod               *corev1.Pod
+		events            []runtime.Object
+		imagePullDuration time.Duration
+		listEventsErr     error
+		expectedPhases    []startupPhase
+		expectedErr       string
+	}{
+		{
+			name: "all conditions",
+			pod: runningPod(10*time.Second,
+				podCondition(corev1.PodScheduled, created.Add(1*time.Second)),
+				podCondition(corev1.PodInitialized, created.Add(1*time.Second)),
+				podCondition(corev1.PodReadyToStartContainers, created.Add(4*time.Second)),
+			),
+			imagePullDuration: 2 * time.Second,
+			expectedPhases: []startupPhase{
+				{name: phaseScheduling, duration: 1 * time.Second},
+				{name: phaseSandbox, duration: 3 * time.Second},
+				{name: phaseInitialization, duration: 0},
+				{name: phaseContainerStart, duration: 4 * time.Second},
+			},
+		},
+		{
+			name: "volume attach and sandbox failures from events",
+			pod: runningPod(10*time.Second,
+				podCondition(corev1.PodScheduled, created.Add(1*time.Second)),
+				podCondition(corev1.PodInitialized, created.Add(1*time.Second)),
+				podCondition(corev1.PodReadyToStartContainers, created.Add(8*time.Second)),
+			),
+			events: []runtime.Object{
+				podEvent(namespace, podName, "attach1", "SuccessfulAttachVolume", "attached volume 1", 1, created.Add(3*time.Second)),
+				podEvent(namespace, podName, "attach2", "SuccessfulAttachVolume", "attached volume 2", 1, created.Add(5*time.Second)),
+				podEvent(namespace, podName, "sandbox", "FailedCreatePodSandBox", "failed to setup network", 2, created.Add(6*time.Second)),
+				podEvent(namespace, "other-pod", "other", "SuccessfulAttachVolume", "attached volume", 1, created.Add(7*time.Second)),
+			},
+			expectedPhases: []startupPhase{
+				{name: phaseScheduling, duration: 1 * time.Second},
+				{name: phaseVolumeAttach, duration: 4 * time.Second},
+				{name: phaseSandbox, duration: 3 * time.Second, detail: "2 FailedCreatePodSandBox events, last: failed to setup network"},
+				{name: phaseInitialization, duration: 0},
+				{name: phaseContainerStart, duration: 2 * time.Second},
+			},
+		},
+		{
+			name: "scheduled event without conditions",
+			pod:  runningPod(5 * time.Second),
+			events: []runtime.Object{
+				podEvent(namespace, podName, "scheduled", "Scheduled", "Successfully assigned pod1", 1, created.Add(2*time.Second)),
+			},
+			expectedPhases: []startupPhase{
+				{name: phaseScheduling, duration: 2 * time.Second},
+				{name: phaseContainerStart, duration: 3 * time.Second},
+			},
+		},
+		{
+			name: "no conditions or events",
+			pod:  runningPod(5 * time.Second),
+			expectedPhases: []startupPhase{
+				{name: phaseContainerStart, duration: 5 * time.Second},
+			},
+		},
+		{
+			name:          "error listing events",
+			pod:           runningPod(5 * time.Second),
+			listEventsErr: errors.New("error listing events"),
+			expectedErr:   "error listing events",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			client := k8sfake.NewClientset(tt.events...)
+			if tt.listEventsErr != nil {
+				client.PrependReactor("list", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.listEventsErr
+				})
+			}
+			checker := &PodStartupChecker{
+				k8sClientset: client,
+				config:       &config.PodStartupConfig{SyntheticPodNamespace: namespace},
+			}
+
+			phases, err := checker.getStartupPhases(context.Background(), tt.pod, nil, tt.imagePullDuration)
+			if tt.expectedErr != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tt.expectedErr))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(phases).To(Equal(tt.expectedPhases))
+		})
+	}
+}
+
+func TestSlowestPhase(t *testing.T) {
+	g := NewWithT(t)
+
+	_, ok := slowestPhase(nil)
+	g.Expect(ok).To(BeFalse())
+
+	_, ok = slowestPhase([]startupPhase{{name: phaseContainerStart, duration: 10 * time.Second}})
+	g.Expect(ok).To(BeFalse())
+
+	slowest, ok := slowestPhase([]startupPhase{
+		{name: phaseScheduling, duration: 2 * time.Second},
+		{name: phaseSandbox, duration: 5 * time.Second},
+		{name: phaseContainerStart, duration: 5 * time.Second},
+	})
+	g.Expect(ok).To(BeTrue())
+	g.Expect(slowest.name).To(Equal(phaseSandbox))
+}
+
+func TestStalledPhaseError(t *testing.T) {
+	tests := []struct {
+		name         string
+		conditions   []corev1.PodCondition
+		expectedCode string
+	}{
+		{
+			name: "unschedulable",
+			conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, Reason: corev1.PodReasonUnschedulable},
+			},
+			expectedCode: ErrCodePodSchedulingDurationExceeded,
+		},
+		{
+			name: "sandbox not ready",
+			conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+				{Type: corev1.PodInitialized, Status: corev1.ConditionTrue},
+				{Type: corev1.PodReadyToStartContainers, Status: corev1.ConditionFalse},
+			},
+			expectedCode: ErrCodeSandboxCreationDurationExceeded,
+		},
+		{
+			name: "init containers not completed",
+			conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+				{Type: corev1.PodReadyToStartContainers, Status: corev1.ConditionTrue},
+				{Type: corev1.PodInitialized, Status: corev1.ConditionFalse, Reason: "ContainersNotInitialized"},
+			},
+			expectedCode: ErrCodePodInitializationDurationExceeded,
+		},
+		{
+			name: "no false conditions",
+			conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+				{Type: corev1.ContainersReady, Status: corev1.ConditionFalse},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := stalledPhaseError(&corev1.Pod{Status: corev1.PodStatus{Conditions: tt.conditions}})
+			if tt.expectedCode == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			var phaseErr *startupPhaseError
+			g.Expect(errors.As(err, &phaseErr)).To(BeTrue())
+			g.Expect(phaseErr.code()).To(Equal(tt.expectedCode))
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package podstartup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/cluster-health-monitor/pkg/checker"
+)
+
+// placementGroups returns the sorted distinct values of the placement node label among the schedulable nodes that synthetic pods can be
+// scheduled on. If the pod template overrides the affinity, the nodes are only narrowed down by the node selector of the template.
+func (c *PodStartupChecker) placementGroups(ctx context.Context) ([]string, error) {
+	label := c.config.Placement.NodeLabel()
+	requirements := []corev1.NodeSelectorRequirement{{Key: label, Operator: corev1.NodeSelectorOpExists}}
+	if !c.overridesAffinity() {
+		requirements = append(requirements, syntheticPodNodeRequirements()...)
+	}
+	if c.config.PodTemplate != nil {
+		for key, value := range c.config.PodTemplate.NodeSelector {
+			requirements = append(requirements, corev1.NodeSelectorRequirement{Key: key, Operator: corev1.NodeSelectorOpIn, Values: []string{value}})
+		}
+	}
+	selector := labels.NewSelector()
+	for _, requirement := range requirements {
+		r, err := labels.NewRequirement(requirement.Key, nodeSelectorOperators[requirement.Operator], requirement.Values)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node requirement on %s: %w", requirement.Key, err)
+		}
+		selector = selector.Add(*r)
+	}
+
+	nodes, err := c.k8sClientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	var groups []string
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		groups = append(groups, node.Labels[label])
+	}
+	slices.Sort(groups)
+	return slices.Compact(groups), nil
+}
+
+// nodeSelectorOperators maps the node selector operators used by placementGroups to label selector operators.
+var nodeSelectorOperators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:     selection.In,
+	corev1.NodeSelectorOpNotIn:  selection.NotIn,
+	corev1.NodeSelectorOpExists: selection.Exists,
+}
+
+// overridesAffinity returns true if the pod template replaces the generated affinity of the synthetic pods.
+func (c *PodStartupChecker) overridesAffinity() bool {
+	return c.config.PodTemplate != nil && c.config.PodTemplate.Affinity != nil
+}
+
+// generatePlacementPod returns the synthetic pod of the placement group with the given index. The pod is scheduled on the nodes of the
+// group through its node selector. Unless the pod template overrides the affinity, the pod is not restricted to system-mode nodes.
+func (c *PodStartupChecker) generatePlacementPod(timestampStr string, index int, group string) *corev1.Pod {
+	pod := c.generateSyntheticPod(timestampStr)
+	pod.Name = fmt.Sprintf("%s-%d", pod.Name, index)
+	if !c.overridesAffinity() {
+		pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms = []corev1.NodeSelectorTerm{
+			{MatchExpressions: syntheticPodNodeRequirements()},
+		}
+	}
+	if pod.Spec.NodeSelector == nil {
+		pod.Spec.NodeSelector = map[string]string{}
+	}
+	pod.Spec.NodeSelector[c.config.Placement.NodeLabel()] = group
+	return pod
+}
+
+// checkPlacementGroups starts a synthetic pod in each placement group, at most Placement.MaxConcurrency and capacity at a time, and records
+// the result of each group. The checker is unhealthy if any group is unhealthy, with the error code of the first unhealthy group.
+func (c *PodStartupChecker) checkPlacementGroups(ctx context.Context, timestampStr string, capacity int) (*checker.Result, error) {
+	groups, err := c.placementGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 0 {
+		return nil, fmt.Errorf("no schedulable nodes with label %s found for placement", c.config.Placement.NodeLabel())
+	}
+
+	results := make([]*checker.Result, len(groups))
+	errs := make([]error, len(groups))
+	slots := make(chan struct{}, min(c.config.Placement.Concurrency(), capacity))
+	var wg sync.WaitGroup
+	for i, group := range groups {
+		wg.Go(func() {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			if ctx.Err() != nil {
+				errs[i] = fmt.Errorf("placement group %s was not probed before the checker timed out: %w", group, ctx.Err())
+			} else {
+				results[i], errs[i] = c.probe(ctx, c.generatePlacementPod(timestampStr, i, group), group)
+			}
+			checker.RecordPlacementGroupResult(c, group, results[i], errs[i])
+		})
+	}
+	wg.Wait()
+
+	var unhealthy []int
+	for i, result := range results {
+		if errs[i] == nil && result.Status == checker.StatusUnhealthy {
+			unhealthy = append(unhealthy, i)
+		}
+	}
+	if len(unhealthy) > 0 {
+		first := results[unhealthy[0]]
+		klog.V(3).InfoS("Placement groups unhealthy", "checker", c.name, "unhealthy", len(unhealthy), "groups", len(groups))
+		return checker.Unhealthy(first.Detail.Code, fmt.Sprintf("%d of %d placement groups unhealthy, %s: %s",
+			len(unhealthy), len(groups), groups[unhealthy[0]], first.Detail.Message)), nil
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return checker.Healthy(), nil
+}
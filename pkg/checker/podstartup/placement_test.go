@@ -0,0 +1,266 @@
+package podstartup
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/cluster-health-monitor/pkg/checker"
+	"github.com/Azure/cluster-health-monitor/pkg/config"
+	"github.com/Azure/cluster-health-monitor/pkg/status"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func placementNode(name, pool string, unschedulable bool, extraLabels map[string]string) *corev1.Node {
+	labels := map[string]string{
+		"kubernetes.azure.com/cluster":   "cluster",
+		"kubernetes.io/os":               "linux",
+		"kubernetes.azure.com/agentpool": pool,
+	}
+	for k, v := range extraLabels {
+		labels[k] = v
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+	}
+}
+
+func TestPodStartupChecker_placementGroups(t *testing.T) {
+	tests := []struct {
+		name           string
+		placement      *config.PlacementConfig
+		podTemplate    *config.SyntheticPodTemplate
+		expectedGroups []string
+	}{
+		{
+			name:           "node pools",
+			placement:      &config.PlacementConfig{By: config.PlacementByNodePool},
+			expectedGroups: []string{"pool1", "pool2"},
+		},
+		{
+			name:           "zones",
+			placement:      &config.PlacementConfig{By: config.PlacementByZone},
+			expectedGroups: []string{"eastus-1", "eastus-2"},
+		},
+		{
+			// Without the generated affinity, virtual and windows nodes are only left out by the node selector of the template.
+			name:      "node pools with the affinity and node selector of the pod template",
+			placement: &config.PlacementConfig{By: config.PlacementByNodePool},
+			podTemplate: &config.SyntheticPodTemplate{
+				Affinity:     &corev1.Affinity{},
+				NodeSelector: map[string]string{"topology.kubernetes.io/zone": "eastus-2"},
+			},
+			expectedGroups: []string{"pool1", "pool2"},
+		},
+		{
+			name:           "node pools with the affinity of the pod template",
+			placement:      &config.PlacementConfig{By: config.PlacementByNodePool},
+			podTemplate:    &config.SyntheticPodTemplate{Affinity: &corev1.Affinity{}},
+			expectedGroups: []string{"pool1", "pool2", "virtual", "windows"},
+		},
+		{
+			name:      "node label without matching nodes",
+			placement: &config.PlacementConfig{By: config.PlacementByNodeLabel, NodeLabelKey: "example.com/rack"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			client := k8sfake.NewClientset(
+				placementNode("node1", "pool2", false, map[string]string{"topology.kubernetes.io/zone": "eastus-2"}),
+				placementNode("node2", "pool1", false, map[string]string{"topology.kubernetes.io/zone": "eastus-1"}),
+				placementNode("node3", "pool1", false, map[string]string{"topology.kubernetes.io/zone": "eastus-2"}),
+				// Unschedulable, virtual and windows nodes are left out.
+				placementNode("node4", "cordoned", true, map[string]string{"topology.kubernetes.io/zone": "eastus-3"}),
+				placementNode("node5", "virtual", false, map[string]string{"type": "virtual-kubelet"}),
+				placementNode("node6", "windows", false, map[string]string{"kubernetes.io/os": "windows"}),
+			)
+			chk := &PodStartupChecker{
+				k8sClientset: client,
+				config:       &config.PodStartupConfig{Placement: tt.placement, PodTemplate: tt.podTemplate},
+			}
+
+			groups, err := chk.placementGroups(context.Background())
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(groups).To(Equal(tt.expectedGroups))
+		})
+	}
+}
+
+func TestGeneratePlacementPod(t *testing.T) {
+	g := NewWithT(t)
+	chk := &PodStartupChecker{
+		name: "test",
+		config: &config.PodStartupConfig{
+			SyntheticPodLabelKey: "cluster-health-monitor/checker-name",
+			Placement:            &config.PlacementConfig{By: config.PlacementByZone},
+		},
+	}
+
+	pod := chk.generatePlacementPod("123", 1, "eastus-2")
+	g.Expect(pod.Name).To(Equal("test-synthetic-123-1"))
+	g.Expect(pod.Labels).To(Equal(chk.syntheticPodLabels()))
+
+	g.Expect(pod.Spec.NodeSelector).To(Equal(map[string]string{"topology.kubernetes.io/zone": "eastus-2"}))
+
+	terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	g.Expect(terms).To(HaveLen(1))
+	g.Expect(terms[0].MatchExpressions).To(Equal(syntheticPodNodeRequirements()))
+
+	// An affinity of the pod template is kept.
+	affinity := &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{}}
+	chk.config.PodTemplate = &config.SyntheticPodTemplate{Affinity: affinity}
+	pod = chk.generatePlacementPod("123", 1, "eastus-2")
+	g.Expect(pod.Spec.Affinity).To(Equal(affinity))
+	g.Expect(pod.Spec.NodeSelector).To(Equal(map[string]string{"topology.kubernetes.io/zone": "eastus-2"}))
+}
+
+func TestPodStartupChecker_checkPlacementGroups(t *testing.T) {
+	namespace := "test-namespace"
+
+	tests := []struct {
+		name string
+		// unreachableGroups are the groups whose synthetic pods cannot be connected to.
+		unreachableGroups []string
+		nodes             []runtime.Object
+		validateRes       func(g *WithT, result *checker.Result, err error, st status.CheckerStatus)
+	}{
+		{
+			name: "all groups healthy",
+			nodes: []runtime.Object{
+				placementNode("node1", "pool1", false, nil),
+				placementNode("node2", "pool2", false, nil),
+				placementNode("node3", "pool3", false, nil),
+			},
+			validateRes: func(g *WithT, result *checker.Result, err error, st status.CheckerStatus) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result.Status).To(Equal(checker.StatusHealthy))
+				g.Expect(st.RecentResults).To(HaveLen(3))
+				groups := []string{}
+				for _, r := range st.RecentResults {
+					g.Expect(r.Status).To(Equal(string(checker.StatusHealthy)))
+					groups = append(groups, r.Group)
+				}
+				g.Expect(groups).To(ConsistOf("pool1", "pool2", "pool3"))
+			},
+		},
+		{
+			name:              "one group unhealthy",
+			unreachableGroups: []string{"pool2"},
+			nodes: []runtime.Object{
+				placementNode("node1", "pool1", false, nil),
+				placementNode("node2", "pool2", false, nil),
+				placementNode("node3", "pool3", false, nil),
+			},
+			validateRes: func(g *WithT, result *checker.Result, err error, st status.CheckerStatus) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result.Status).To(Equal(checker.StatusUnhealthy))
+				g.Expect(result.Detail.Code).To(Equal(ErrCodeRequestFailed))
+				g.Expect(result.Detail.Message).To(HavePrefix("1 of 3 placement groups unhealthy, pool2: "))
+				for _, r := range st.RecentResults {
+					if r.Group == "pool2" {
+						g.Expect(r.Status).To(Equal(string(checker.StatusUnhealthy)))
+						g.Expect(r.Code).To(Equal(ErrCodeRequestFailed))
+					} else {
+						g.Expect(r.Status).To(Equal(string(checker.StatusHealthy)))
+					}
+				}
+			},
+		},
+		{
+			name: "no groups",
+			validateRes: func(g *WithT, result *checker.Result, err error, st status.CheckerStatus) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("no schedulable nodes with label kubernetes.azure.com/agentpool"))
+				g.Expect(st.RecentResults).To(BeEmpty())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			checkerName := "placement-test"
+			status.DefaultStore.Delete(checkerName)
+
+			client := k8sfake.NewClientset(tt.nodes...)
+			var mu sync.Mutex
+			inflight, maxInflight := 0, 0
+			// Created pods are running right away with an IP telling their group apart, and their image is already present.
+			client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+				now := metav1.Now()
+				pod.CreationTimestamp = now
+				group := pod.Spec.NodeSelector["kubernetes.azure.com/agentpool"]
+				pod.Status.PodIP = fmt.Sprintf("10.0.0.%s", strings.TrimPrefix(group, "pool"))
+				pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+					State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: now}},
+				}}
+				if err := client.Tracker().Add(imageAlreadyPresentEvent(namespace, pod.Name)); err != nil {
+					// The events of all pods share a name, so the event of a later pod replaces the event of an earlier one.
+					if err := client.Tracker().Update(corev1.SchemeGroupVersion.WithResource("events"),
+						imageAlreadyPresentEvent(namespace, pod.Name), namespace); err != nil {
+						return true, nil, err
+					}
+				}
+				mu.Lock()
+				inflight++
+				maxInflight = max(maxInflight, inflight)
+				mu.Unlock()
+				return false, nil, nil
+			})
+			client.PrependReactor("delete", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				mu.Lock()
+				inflight--
+				mu.Unlock()
+				return false, nil, nil
+			})
+
+			chk := &PodStartupChecker{
+				name: checkerName,
+				config: &config.PodStartupConfig{
+					SyntheticPodNamespace:      namespace,
+					SyntheticPodLabelKey:       "cluster-health-monitor/checker-name",
+					SyntheticPodStartupTimeout: 5 * time.Second,
+					MaxSyntheticPods:           3,
+					TCPTimeout:                 1 * time.Second,
+					TCPMaxRetries:              1,
+					TCPRetryInterval:           1 * time.Millisecond,
+					Placement:                  &config.PlacementConfig{By: config.PlacementByNodePool, MaxConcurrency: 2},
+				},
+				k8sClientset: client,
+				dialer: &mockDialer{
+					dialFunc: func(ctx context.Context, network, address string) (net.Conn, error) {
+						for _, group := range tt.unreachableGroups {
+							if strings.HasPrefix(address, fmt.Sprintf("10.0.0.%s:", strings.TrimPrefix(group, "pool"))) {
+								return nil, fmt.Errorf("connection refused")
+							}
+						}
+						conn, _ := net.Pipe()
+						return conn, nil
+					},
+				},
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			result, err := chk.checkPlacementGroups(ctx, "123", 3)
+			g.Expect(maxInflight).To(BeNumerically("<=", 2))
+			st, _ := status.DefaultStore.Get(checkerName)
+			tt.validateRes(g, result, err, st)
+		})
+	}
+}
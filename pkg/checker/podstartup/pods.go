@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"strings"
 	"time"
 
@@ -28,52 +29,58 @@ func (c *PodStartupChecker) syntheticPodNamePrefix() string {
 	return strings.ToLower(fmt.Sprintf("%s-synthetic-", c.name))
 }
 
+// syntheticPodNodeRequirements returns the requirements of the nodes that synthetic pods can be scheduled on: linux nodes of the cluster
+// that are not virtual.
+func syntheticPodNodeRequirements() []corev1.NodeSelectorRequirement {
+	return []corev1.NodeSelectorRequirement{
+		{
+			Key:      "kubernetes.azure.com/cluster",
+			Operator: corev1.NodeSelectorOpExists,
+		},
+		{
+			Key:      "type",
+			Operator: corev1.NodeSelectorOpNotIn,
+			Values:   []string{"virtual-kubelet"},
+		},
+		{
+			Key:      "kubernetes.io/os",
+			Operator: corev1.NodeSelectorOpIn,
+			Values:   []string{"linux"},
+		},
+	}
+}
+
+// csiVolumeName returns the name of the volume of the storage probe at the given index of EnabledCSIs in the synthetic pod.
+func csiVolumeName(index int) string {
+	return fmt.Sprintf("csi-volume-%d", index)
+}
+
 func (c *PodStartupChecker) generateSyntheticPod(timestampStr string) *corev1.Pod {
 	podName := fmt.Sprintf("%s%s", c.syntheticPodNamePrefix(), timestampStr)
 
 	volumes := []corev1.Volume{}
 	volumeMounts := []corev1.VolumeMount{}
+	volumeDevices := []corev1.VolumeDevice{}
 
-	for _, csi := range c.config.EnabledCSIs {
-		switch csi.Type {
-		case config.CSITypeAzureFile:
-			volumes = append(volumes, corev1.Volume{
-				Name: "azurefile-volume",
-				VolumeSource: corev1.VolumeSource{
-					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-						ClaimName: c.azureFilePVC(timestampStr, csi.StorageClass).Name,
-					},
-				},
-			})
-			volumeMounts = append(volumeMounts, corev1.VolumeMount{
-				Name:      "azurefile-volume",
-				MountPath: "/mnt/azurefile",
-			})
-		case config.CSITypeAzureDisk:
-			volumes = append(volumes, corev1.Volume{
-				Name: "azuredisk-volume",
-				VolumeSource: corev1.VolumeSource{
-					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-						ClaimName: c.azureDiskPVC(timestampStr, csi.StorageClass).Name,
-					},
-				},
-			})
-			volumeMounts = append(volumeMounts, corev1.VolumeMount{
-				Name:      "azuredisk-volume",
-				MountPath: "/mnt/azuredisk",
-			})
-		case config.CSITypeAzureBlob:
-			volumes = append(volumes, corev1.Volume{
-				Name: "azureblob-volume",
-				VolumeSource: corev1.VolumeSource{
-					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-						ClaimName: c.azureBlobPVC(timestampStr, csi.StorageClass).Name,
-					},
+	for i, csi := range c.config.EnabledCSIs {
+		volumeName := csiVolumeName(i)
+		volumes = append(volumes, corev1.Volume{
+			Name: volumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: c.csiPVC(timestampStr, i, csi).Name,
 				},
+			},
+		})
+		if csi.PVCVolumeMode() == corev1.PersistentVolumeBlock {
+			volumeDevices = append(volumeDevices, corev1.VolumeDevice{
+				Name:       volumeName,
+				DevicePath: csi.VolumePath(),
 			})
+		} else {
 			volumeMounts = append(volumeMounts, corev1.VolumeMount{
-				Name:      "azureblob-volume",
-				MountPath: "/mnt/azureblob",
+				Name:      volumeName,
+				MountPath: csi.VolumePath(),
 			})
 		}
 	}
@@ -81,17 +88,20 @@ func (c *PodStartupChecker) generateSyntheticPod(timestampStr string) *corev1.Po
 	podSpec := corev1.PodSpec{
 		Containers: []corev1.Container{
 			{
-				Name:  "synthetic",
-				Image: syntheticPodImage,
+				Name:            "synthetic",
+				Image:           c.config.SyntheticPodImage(),
+				ImagePullPolicy: corev1.PullPolicy(c.config.ImagePullPolicy),
 				Ports: []corev1.ContainerPort{
 					{
-						ContainerPort: syntheticPodPort,
+						ContainerPort: int32(c.config.SyntheticPodPort()),
 						Protocol:      corev1.ProtocolTCP,
 					},
 				},
-				VolumeMounts: volumeMounts,
+				VolumeMounts:  volumeMounts,
+				VolumeDevices: volumeDevices,
 			},
 		},
+		ImagePullSecrets: c.imagePullSecrets(),
 		Tolerations: []corev1.Toleration{
 			{
 				Key:    "node-role.kubernetes.io/master",
@@ -107,27 +117,11 @@ func (c *PodStartupChecker) generateSyntheticPod(timestampStr string) *corev1.Po
 				RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
 					NodeSelectorTerms: []corev1.NodeSelectorTerm{
 						{
-							MatchExpressions: []corev1.NodeSelectorRequirement{
-								{
-									Key:      "kubernetes.azure.com/cluster",
-									Operator: corev1.NodeSelectorOpExists,
-								},
-								{
-									Key:      "type",
-									Operator: corev1.NodeSelectorOpNotIn,
-									Values:   []string{"virtual-kubelet"},
-								},
-								{
-									Key:      "kubernetes.io/os",
-									Operator: corev1.NodeSelectorOpIn,
-									Values:   []string{"linux"},
-								},
-								{
-									Key:      "kubernetes.azure.com/mode",
-									Operator: corev1.NodeSelectorOpIn,
-									Values:   []string{"system"},
-								},
-							},
+							MatchExpressions: append(syntheticPodNodeRequirements(), corev1.NodeSelectorRequirement{
+								Key:      "kubernetes.azure.com/mode",
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{"system"},
+							}),
 						},
 					},
 				},
@@ -137,6 +131,10 @@ func (c *PodStartupChecker) generateSyntheticPod(timestampStr string) *corev1.Po
 		// TODOcarlosalv: Add pod cpu/memory requests and/or limits.
 	}
 
+	if c.config.VolumeIO != nil && len(volumes) > 0 {
+		podSpec.InitContainers = []corev1.Container{c.volumeIOContainer(volumeMounts, volumeDevices)}
+	}
+
 	if c.config.EnableNodeProvisioningTest {
 		// If node provisioning test is enabled, we will add a node selector to ensure the synthetic pod is scheduled on a node from the NodePool created by the checker.
 		podSpec.NodeSelector = map[string]string{
@@ -153,13 +151,61 @@ func (c *PodStartupChecker) generateSyntheticPod(timestampStr string) *corev1.Po
 		})
 	}
 
-	return &corev1.Pod{
+	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   podName,
 			Labels: c.syntheticPodLabels(),
 		},
 		Spec: podSpec,
 	}
+	if c.config.PodTemplate != nil {
+		applyPodTemplate(pod, c.config.PodTemplate)
+	}
+	return pod
+}
+
+// applyPodTemplate merges the template onto the generated pod as described by config.SyntheticPodTemplate.
+func applyPodTemplate(pod *corev1.Pod, template *config.SyntheticPodTemplate) {
+	if len(template.NodeSelector) > 0 {
+		if pod.Spec.NodeSelector == nil {
+			pod.Spec.NodeSelector = map[string]string{}
+		}
+		maps.Copy(pod.Spec.NodeSelector, template.NodeSelector)
+	}
+	if template.Affinity != nil {
+		pod.Spec.Affinity = template.Affinity.DeepCopy()
+	}
+	for _, toleration := range template.Tolerations {
+		pod.Spec.Tolerations = append(pod.Spec.Tolerations, *toleration.DeepCopy())
+	}
+	if template.Resources != nil {
+		for i := range pod.Spec.InitContainers {
+			pod.Spec.InitContainers[i].Resources = *template.Resources.DeepCopy()
+		}
+		for i := range pod.Spec.Containers {
+			pod.Spec.Containers[i].Resources = *template.Resources.DeepCopy()
+		}
+	}
+	if template.PriorityClassName != "" {
+		pod.Spec.PriorityClassName = template.PriorityClassName
+	}
+	if template.RuntimeClassName != "" {
+		pod.Spec.RuntimeClassName = &template.RuntimeClassName
+	}
+	if template.SecurityContext != nil {
+		pod.Spec.SecurityContext = template.SecurityContext.DeepCopy()
+	}
+}
+
+func (c *PodStartupChecker) imagePullSecrets() []corev1.LocalObjectReference {
+	if len(c.config.ImagePullSecrets) == 0 {
+		return nil
+	}
+	secrets := make([]corev1.LocalObjectReference, 0, len(c.config.ImagePullSecrets))
+	for _, name := range c.config.ImagePullSecrets {
+		secrets = append(secrets, corev1.LocalObjectReference{Name: name})
+	}
+	return secrets
 }
 
 // getSyntheticPodIP gets the IP address assigned to the synthetic pod with the specified name
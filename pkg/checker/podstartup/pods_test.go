@@ -10,6 +10,7 @@ import (
 	"github.com/Azure/cluster-health-monitor/pkg/config"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -23,6 +24,10 @@ func TestGenerateSyntheticPod(t *testing.T) {
 		checkerName                string
 		enableNodeProvisioningTest bool
 		csiTests                   []config.CSIType
+		image                      string
+		imagePullSecrets           []string
+		port                       int
+		imagePullPolicy            string
 	}{
 		{
 			name:        "generates valid synthetic pod",
@@ -47,6 +52,14 @@ func TestGenerateSyntheticPod(t *testing.T) {
 			checkerName: "test",
 			csiTests:    []config.CSIType{config.CSITypeAzureFile},
 		},
+		{
+			name:             "successfully uses custom image, pull secrets, port and pull policy",
+			checkerName:      "test",
+			image:            "registry.example.com/nginx:latest",
+			imagePullSecrets: []string{"registry-secret", "other-secret"},
+			port:             8080,
+			imagePullPolicy:  "Always",
+		},
 	}
 
 	for _, tt := range tests {
@@ -58,6 +71,10 @@ func TestGenerateSyntheticPod(t *testing.T) {
 					SyntheticPodLabelKey:       _testSyntheticLabelKey,
 					EnableNodeProvisioningTest: tt.enableNodeProvisioningTest,
 					EnabledCSIs:                csiConfigsFromTypes(tt.csiTests),
+					Image:                      tt.image,
+					ImagePullSecrets:           tt.imagePullSecrets,
+					Port:                       tt.port,
+					ImagePullPolicy:            tt.imagePullPolicy,
 				},
 			}
 
@@ -86,53 +103,135 @@ func TestGenerateSyntheticPod(t *testing.T) {
 			g.Expect(len(pod.Spec.Containers)).To(Equal(1))
 			g.Expect(len(pod.Spec.Containers[0].VolumeMounts)).To(Equal(len(tt.csiTests)))
 
-			for _, csiTest := range tt.csiTests {
-				switch csiTest {
-				case config.CSITypeAzureFile:
-					g.Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
-						Name: "azurefile-volume",
-						VolumeSource: corev1.VolumeSource{
-							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-								ClaimName: checker.azureFilePVC(timestampStr, testAzureFileStorageClass).Name,
-							},
-						},
-					}))
-					g.Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
-						Name:      "azurefile-volume",
-						MountPath: "/mnt/azurefile",
-					}))
-				case config.CSITypeAzureDisk:
-					g.Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
-						Name: "azuredisk-volume",
-						VolumeSource: corev1.VolumeSource{
-							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-								ClaimName: checker.azureDiskPVC(timestampStr, testAzureDiskStorageClass).Name,
-							},
-						},
-					}))
-					g.Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
-						Name:      "azuredisk-volume",
-						MountPath: "/mnt/azuredisk",
-					}))
-				case config.CSITypeAzureBlob:
-					g.Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
-						Name: "azureblob-volume",
-						VolumeSource: corev1.VolumeSource{
-							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-								ClaimName: checker.azureBlobPVC(timestampStr, testAzureBlobStorageClass).Name,
-							},
+			container := pod.Spec.Containers[0]
+			if tt.image == "" {
+				g.Expect(container.Image).To(Equal(config.DefaultSyntheticPodImage))
+			} else {
+				g.Expect(container.Image).To(Equal(tt.image))
+			}
+			if tt.port == 0 {
+				g.Expect(container.Ports[0].ContainerPort).To(BeEquivalentTo(config.DefaultSyntheticPodPort))
+			} else {
+				g.Expect(container.Ports[0].ContainerPort).To(BeEquivalentTo(tt.port))
+			}
+			g.Expect(container.ImagePullPolicy).To(BeEquivalentTo(tt.imagePullPolicy))
+			g.Expect(pod.Spec.ImagePullSecrets).To(HaveLen(len(tt.imagePullSecrets)))
+			for i, secret := range tt.imagePullSecrets {
+				g.Expect(pod.Spec.ImagePullSecrets[i].Name).To(Equal(secret))
+			}
+
+			for i, csi := range checker.config.EnabledCSIs {
+				volumeName := fmt.Sprintf("csi-volume-%d", i)
+				g.Expect(pod.Spec.Volumes).To(ContainElement(corev1.Volume{
+					Name: volumeName,
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: checker.csiPVC(timestampStr, i, csi).Name,
 						},
-					}))
-					g.Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
-						Name:      "azureblob-volume",
-						MountPath: "/mnt/azureblob",
-					}))
-				}
+					},
+				}))
+				g.Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+					Name:      volumeName,
+					MountPath: "/mnt/" + defaultStorageClassForType(csi.Type),
+				}))
 			}
 		})
 	}
 }
 
+func TestGenerateSyntheticPod_PodTemplate(t *testing.T) {
+	g := NewWithT(t)
+	affinity := &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "node-role.kubernetes.io/worker", Operator: corev1.NodeSelectorOpExists}},
+				}},
+			},
+		},
+	}
+	resources := &corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m")},
+	}
+	runAsNonRoot := true
+	checker := &PodStartupChecker{
+		name: "test",
+		config: &config.PodStartupConfig{
+			SyntheticPodLabelKey:       _testSyntheticLabelKey,
+			EnableNodeProvisioningTest: true,
+			PodTemplate: &config.SyntheticPodTemplate{
+				NodeSelector:      map[string]string{"kubernetes.io/arch": "arm64"},
+				Affinity:          affinity,
+				Tolerations:       []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+				Resources:         resources,
+				PriorityClassName: "system-cluster-critical",
+				RuntimeClassName:  "kata",
+				SecurityContext:   &corev1.PodSecurityContext{RunAsNonRoot: &runAsNonRoot},
+			},
+		},
+	}
+
+	pod := checker.generateSyntheticPod("123")
+	// The node selector of the template is merged with the one of the node provisioning test.
+	g.Expect(pod.Spec.NodeSelector).To(Equal(map[string]string{_testSyntheticLabelKey: "123", "kubernetes.io/arch": "arm64"}))
+	g.Expect(pod.Spec.Affinity).To(Equal(affinity))
+	// The tolerations of the template are added to the generated ones.
+	g.Expect(pod.Spec.Tolerations).To(ContainElements(
+		corev1.Toleration{Key: "CriticalAddonsOnly", Operator: corev1.TolerationOpExists},
+		corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpExists},
+	))
+	g.Expect(pod.Spec.Containers[0].Resources).To(Equal(*resources))
+	g.Expect(pod.Spec.PriorityClassName).To(Equal("system-cluster-critical"))
+	g.Expect(*pod.Spec.RuntimeClassName).To(Equal("kata"))
+	g.Expect(*pod.Spec.SecurityContext.RunAsNonRoot).To(BeTrue())
+
+	// The generated pod does not share the template's objects.
+	pod.Spec.Affinity.NodeAffinity = nil
+	g.Expect(affinity.NodeAffinity).ToNot(BeNil())
+}
+
+func TestGenerateSyntheticPod_VolumeIO(t *testing.T) {
+	g := NewWithT(t)
+	checker := &PodStartupChecker{
+		name: "test",
+		config: &config.PodStartupConfig{
+			SyntheticPodLabelKey: _testSyntheticLabelKey,
+			EnabledCSIs:          csiConfigsFromTypes([]config.CSIType{config.CSITypeAzureFile, config.CSITypeAzureDisk}),
+			VolumeIO:             &config.VolumeIOConfig{FileSize: "4Mi"},
+		},
+	}
+
+	pod := checker.generateSyntheticPod("123")
+	g.Expect(pod.Spec.InitContainers).To(HaveLen(1))
+	initContainer := pod.Spec.InitContainers[0]
+	g.Expect(initContainer.Name).To(Equal(volumeIOContainerName))
+	g.Expect(initContainer.Image).To(Equal(config.DefaultSyntheticPodImage))
+	g.Expect(initContainer.VolumeMounts).To(Equal(pod.Spec.Containers[0].VolumeMounts))
+	g.Expect(initContainer.Env).To(ContainElements(
+		corev1.EnvVar{Name: "VOLUMES", Value: "azurefile-csi=/mnt/azurefile-csi managed-csi=/mnt/managed-csi"},
+		corev1.EnvVar{Name: "FILE_SIZE", Value: "4194304"},
+	))
+
+	// A Block volume is attached to both containers as a device, at its own path.
+	checker.config.EnabledCSIs = append(checker.config.EnabledCSIs, config.CSIConfig{
+		StorageClass: "local-block",
+		VolumeMode:   string(corev1.PersistentVolumeBlock),
+	})
+	pod = checker.generateSyntheticPod("123")
+	device := corev1.VolumeDevice{Name: "csi-volume-2", DevicePath: "/dev/local-block"}
+	g.Expect(pod.Spec.Containers[0].VolumeMounts).To(HaveLen(2))
+	g.Expect(pod.Spec.Containers[0].VolumeDevices).To(Equal([]corev1.VolumeDevice{device}))
+	g.Expect(pod.Spec.InitContainers[0].VolumeDevices).To(Equal([]corev1.VolumeDevice{device}))
+	g.Expect(pod.Spec.InitContainers[0].Env).To(ContainElement(corev1.EnvVar{
+		Name:  "VOLUMES",
+		Value: "azurefile-csi=/mnt/azurefile-csi managed-csi=/mnt/managed-csi local-block=/dev/local-block",
+	}))
+
+	// Without volumes there is nothing to verify.
+	checker.config.EnabledCSIs = nil
+	g.Expect(checker.generateSyntheticPod("123").Spec.InitContainers).To(BeEmpty())
+}
+
 func TestPodStartupChecker_getSyntheticPodIP(t *testing.T) {
 	podName := "test-pod"
 	namespace := "test-namespace"
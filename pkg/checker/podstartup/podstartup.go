@@ -11,10 +11,10 @@ import (
 	"time"
 
 	retry "github.com/avast/retry-go/v4"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
@@ -23,6 +23,7 @@ import (
 
 	"github.com/Azure/cluster-health-monitor/pkg/checker"
 	"github.com/Azure/cluster-health-monitor/pkg/config"
+	"github.com/Azure/cluster-health-monitor/pkg/karpenterclient"
 )
 
 // Dialer is an interface for making network connections
@@ -30,12 +31,21 @@ type Dialer interface {
 	DialContext(ctx context.Context, network, address string) (net.Conn, error)
 }
 
+// Phases of a PodStartupChecker run recorded in the phase duration histogram.
 const (
-	// syntheticPodImage is the hardcoded container image used for synthetic pods in pod-to-pod communication testing.
-	syntheticPodImage = "mcr.microsoft.com/azurelinux/base/nginx:1.25.4-4-azl3.0.20250702"
-
-	// syntheticPodPort is the hardcoded TCP port that synthetic pods listen on for connectivity testing.
-	syntheticPodPort = 80
+	// phaseCreationToRunning is the time between the synthetic pod's creation and its container running.
+	phaseCreationToRunning = "creation_to_running"
+	// phaseImagePull is the image pull duration of the synthetic pod, including waiting.
+	phaseImagePull = "image_pull"
+	// phasePodStartup is the pod startup duration compared against SyntheticPodStartupTimeout, i.e. creation to running minus image pull.
+	phasePodStartup = "pod_startup"
+
+	// The phases the pod startup is broken down into, see getStartupPhases.
+	phaseScheduling     = "scheduling"
+	phaseVolumeAttach   = "volume_attach"
+	phaseSandbox        = "sandbox"
+	phaseInitialization = "initialization"
+	phaseContainerStart = "container_start"
 )
 
 type PodStartupChecker struct {
@@ -47,12 +57,6 @@ type PodStartupChecker struct {
 	dynamicClient dynamic.Interface // to interact with Karpenter's custom resources
 }
 
-var NodePoolGVR = schema.GroupVersionResource{
-	Group:    "karpenter.sh",
-	Version:  "v1",
-	Resource: "nodepools",
-}
-
 // How often to poll the pod status to check if the container is running.
 var pollingInterval = 1 * time.Second // used for unit tests
 
@@ -64,7 +68,7 @@ func Register() {
 }
 
 // BuildPodStartupChecker creates a new PodStartupChecker instance.
-func BuildPodStartupChecker(config *config.CheckerConfig, kubeClient kubernetes.Interface) (checker.Checker, error) {
+func BuildPodStartupChecker(config *config.CheckerConfig, kubeClient kubernetes.Interface, restConfig *rest.Config) (checker.Checker, error) {
 	chk := &PodStartupChecker{
 		name:         config.Name,
 		config:       config.PodStartupConfig,
@@ -80,11 +84,6 @@ func BuildPodStartupChecker(config *config.CheckerConfig, kubeClient kubernetes.
 		"timeout", chk.timeout.String(),
 	)
 
-	restConfig, err := rest.InClusterConfig()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get in-cluster config: %w", err)
-	}
-
 	// create a dynamic client to interact with Karpenter's custom resources
 	dynamicClient, err := dynamic.NewForConfig(restConfig)
 	if err != nil {
@@ -104,8 +103,10 @@ func (c *PodStartupChecker) Type() config.CheckerType {
 }
 
 func (c *PodStartupChecker) Run(ctx context.Context) {
+	start := time.Now()
 	result, err := c.check(ctx)
 	checker.RecordResult(c, result, err)
+	checker.RecordDuration(c, result, err, time.Since(start))
 }
 
 // Run executes the pod startup checker logic. It creates synthetic pods to measure the startup time. The startup time is defined as the
@@ -153,10 +154,14 @@ func (c *PodStartupChecker) check(ctx context.Context) (*checker.Result, error)
 			len(pods.Items), c.config.MaxSyntheticPods)
 	}
 
+	if c.config.Placement != nil {
+		return c.checkPlacementGroups(ctx, timeStampStr, c.config.MaxSyntheticPods-len(pods.Items))
+	}
+
 	nodePoolName := fmt.Sprintf("%s-nodepool-%s", strings.ToLower(c.name), timeStampStr)
 
 	if c.config.EnableNodeProvisioningTest {
-		karpenterNodePoolCRDPresent, err := c.isKarpenterNodePoolCRDPresent(ctx)
+		karpenterNodePoolCRDPresent, err := karpenterclient.IsNodePoolCRDPresent(ctx, c.dynamicClient)
 		if err != nil {
 			return nil, fmt.Errorf("failed to check Karpenter NodePool CRD presence: %w", err)
 		}
@@ -164,13 +169,33 @@ func (c *PodStartupChecker) check(ctx context.Context) (*checker.Result, error)
 			return checker.Skipped("Karpenter NodePool CRD was not found, pod startup test was skipped"), nil
 		}
 		// create a NodePool first, then create synthetic pods on a new node from the node pool.
-		if err := c.createKarpenterNodePool(ctx, c.karpenterNodePool(nodePoolName, timeStampStr)); err != nil {
+		if _, err := karpenterclient.CreateNodePool(ctx, c.dynamicClient, c.karpenterNodePool(nodePoolName, timeStampStr)); err != nil {
 			return nil, fmt.Errorf("failed to create Karpenter NodePool: %w", err)
 		}
+		defer func() {
+			if err := karpenterclient.DeleteNodePool(ctx, c.dynamicClient, nodePoolName); err != nil {
+				klog.ErrorS(err, "Failed to delete Karpenter NodePool", "name", nodePoolName)
+			}
+		}()
+	}
+
+	return c.probe(ctx, c.generateSyntheticPod(timeStampStr), "")
+}
+
+// probe creates the synthetic pod, measures its startup and checks that it is reachable. The phase durations are recorded for the
+// placement group, or for the checker if group is empty.
+func (c *PodStartupChecker) probe(ctx context.Context, pod *corev1.Pod, group string) (*checker.Result, error) {
+	recordPhaseDuration := func(phase string, duration time.Duration) {
+		if group == "" {
+			checker.RecordPhaseDuration(c, phase, duration)
+			return
+		}
+		checker.RecordPlacementGroupPhaseDuration(c, group, phase, duration)
 	}
 
 	// Create a synthetic pod to measure the startup time.
-	synthPod, err := c.k8sClientset.CoreV1().Pods(c.config.SyntheticPodNamespace).Create(ctx, c.generateSyntheticPod(timeStampStr), metav1.CreateOptions{})
+	createStart := time.Now()
+	synthPod, err := c.k8sClientset.CoreV1().Pods(c.config.SyntheticPodNamespace).Create(ctx, pod, metav1.CreateOptions{})
 	if err != nil {
 		if errors.Is(err, context.DeadlineExceeded) {
 			return checker.Unhealthy(ErrCodePodCreationTimeout, "timed out creating synthetic pod"), nil
@@ -183,38 +208,126 @@ func (c *PodStartupChecker) check(ctx context.Context) (*checker.Result, error)
 			// Logging instead of returning an error here to avoid failing the checker run.
 			klog.ErrorS(err, "Failed to delete synthetic pod", "name", synthPod.Name)
 		}
-
-		if c.config.EnableNodeProvisioningTest {
-			if err := c.deleteKarpenterNodePool(ctx, nodePoolName); err != nil {
-				klog.ErrorS(err, "Failed to delete Karpenter NodePool", "name", nodePoolName)
-			}
-		}
 	}()
 
-	podCreationToContainerRunningDuration, err := c.pollPodCreationToContainerRunningDuration(ctx, synthPod.Name)
+	tracker := newPodStartupTracker(createStart)
+	podCreationToContainerRunningDuration, err := c.waitForContainerRunning(ctx, synthPod, tracker)
 	if err != nil {
+		var ioErr *volumeIOError
+		if errors.As(err, &ioErr) {
+			return checker.Unhealthy(ioErr.code, ioErr.Error()), nil
+		}
+		var pullErr *imagePullError
+		if errors.As(err, &pullErr) {
+			return checker.Unhealthy(pullErr.code(), pullErr.Error()), nil
+		}
+		if errors.Is(err, context.DeadlineExceeded) && len(c.config.EnabledCSIs) > 0 {
+			var storageErr *storageOperationError
+			if errors.As(c.stalledStorageError(ctx, synthPod), &storageErr) {
+				return checker.Unhealthy(storageErr.code(), storageErr.Error()), nil
+			}
+		}
+		var phaseErr *startupPhaseError
+		if errors.As(err, &phaseErr) {
+			return checker.Unhealthy(phaseErr.code(), phaseErr.Error()), nil
+		}
 		if errors.Is(err, context.DeadlineExceeded) {
 			return checker.Unhealthy(ErrCodePodStartupDurationExceeded, "pod has no running container"), nil
 		}
 		return nil, fmt.Errorf("pod has no running container: %w", err)
 	}
-	imagePullDuration, err := c.getImagePullDuration(ctx, synthPod.Name)
+	var imagePullDuration time.Duration
+	if events, ok := tracker.watchedEvents(); ok {
+		imagePullDuration, err = c.imagePullDurationFromEvents(synthPod.Name, events)
+	} else {
+		imagePullDuration, err = c.getImagePullDuration(ctx, synthPod.Name)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get image pull duration: %w", err)
 	}
 
-	// Calculate the pod startup duration. Round to the seconds place because that is the unit of the least precise measurement.
-	podStartupDuration := (podCreationToContainerRunningDuration - imagePullDuration).Round(time.Second)
+	var volumeIO []volumeIOResult
+	if c.config.VolumeIO != nil && len(c.config.EnabledCSIs) > 0 {
+		if volumeIO, err = c.getVolumeIOResults(ctx, synthPod.Name); err != nil {
+			return nil, fmt.Errorf("failed to get volume I/O results: %w", err)
+		}
+		for _, result := range volumeIO {
+			checker.RecordVolumeIODuration(c, result.storageClass, volumeIOOperationWrite, result.write)
+			checker.RecordVolumeIODuration(c, result.storageClass, volumeIOOperationRead, result.read)
+		}
+	}
+
+	// Calculate the pod startup duration, which does not include the volume I/O. Round to the unit of the least precise measurement: the
+	// second if the pod timestamps are used, the millisecond of the image pull duration if the startup was watched.
+	precision := time.Second
+	if tracker.watchedStartup() {
+		precision = time.Millisecond
+	}
+	podStartupDuration := (podCreationToContainerRunningDuration - imagePullDuration - volumeIODuration(volumeIO)).Round(precision)
+	recordPhaseDuration(phaseCreationToRunning, podCreationToContainerRunningDuration)
+	recordPhaseDuration(phaseImagePull, imagePullDuration)
+	recordPhaseDuration(phasePodStartup, podStartupDuration)
+
+	// The phase breakdown only explains the pod startup duration, and the storage operations are measured after the fact, so failing to get
+	// them does not fail the checker run.
+	var phases []startupPhase
+	var storageOperations []storageOperation
+	if pod, err := c.k8sClientset.CoreV1().Pods(c.config.SyntheticPodNamespace).Get(ctx, synthPod.Name, metav1.GetOptions{}); err != nil {
+		klog.ErrorS(err, "Failed to get synthetic pod for the startup phases", "name", synthPod.Name)
+	} else {
+		if phases, err = c.getStartupPhases(ctx, pod, tracker, imagePullDuration); err != nil {
+			klog.ErrorS(err, "Failed to get startup phases of synthetic pod", "name", synthPod.Name)
+		}
+		if storageOperations, err = c.getStorageOperations(ctx, pod); err != nil {
+			klog.ErrorS(err, "Failed to get storage operations of synthetic pod", "name", synthPod.Name)
+		}
+	}
+	for _, phase := range phases {
+		recordPhaseDuration(phase.name, phase.duration)
+	}
+	for _, operation := range storageOperations {
+		checker.RecordStorageOperationDuration(c, operation.storageClass, operation.volumeName, operation.name, operation.duration)
+	}
+
+	if operation, threshold, ok := c.slowStorageOperation(storageOperations); ok {
+		klog.V(3).InfoS("Storage operation duration exceeded healthy threshold",
+			"checker", c.name,
+			"pod", synthPod.Name,
+			"storageClass", operation.storageClass,
+			"volume", operation.volumeName,
+			"operation", operation.name,
+			"duration", operation.duration.String(),
+			"threshold", threshold.String(),
+		)
+		msg := fmt.Sprintf("%s of the %s volume %s took %s, exceeding the threshold of %s", operation.name, operation.storageClass,
+			operation.volumeName, operation.duration, threshold)
+		if operation.detail != "" {
+			msg = fmt.Sprintf("%s (%s)", msg, operation.detail)
+		}
+		return checker.Unhealthy(storageOperationErrCodes[operation.name], msg), nil
+	}
+
 	if podStartupDuration >= c.config.SyntheticPodStartupTimeout {
 		klog.V(3).InfoS("Pod startup duration exceeded healthy threshold",
 			"checker", c.name,
 			"pod", synthPod.Name,
+			"group", group,
 			"podStartupDuration", podStartupDuration.String(),
 			"podCreationToContainerRunningDuration", podCreationToContainerRunningDuration.String(),
 			"imagePullDuration", imagePullDuration.String(),
 			"syntheticPodStartupTimeout", c.config.SyntheticPodStartupTimeout.String(),
+			"startupPhases", formatStartupPhases(phases),
 		)
-		return checker.Unhealthy(ErrCodePodStartupDurationExceeded, "pod exceeded the maximum healthy startup duration"), nil
+		slowest, ok := slowestPhase(phases)
+		if !ok {
+			return checker.Unhealthy(ErrCodePodStartupDurationExceeded, "pod exceeded the maximum healthy startup duration"), nil
+		}
+		msg := fmt.Sprintf("pod exceeded the maximum healthy startup duration of %s in %s, slowest phase %s took %s",
+			c.config.SyntheticPodStartupTimeout, podStartupDuration, slowest.name, slowest.duration)
+		if slowest.detail != "" {
+			msg = fmt.Sprintf("%s (%s)", msg, slowest.detail)
+		}
+		return checker.Unhealthy(phaseErrCodes[slowest.name], msg), nil
 	}
 
 	// perform pod communication check - get pod IP and create TCP connection
@@ -233,6 +346,26 @@ func (c *PodStartupChecker) check(ctx context.Context) (*checker.Result, error)
 		return checker.Unhealthy(ErrCodeRequestFailed, fmt.Sprintf("TCP request to synthetic pod failed: %s", err)), nil
 	}
 
+	if c.config.VolumeIO != nil {
+		if msg := c.slowVolumeIO(volumeIO); msg != "" {
+			klog.V(3).InfoS("Volume I/O duration exceeded healthy threshold", "checker", c.name, "pod", synthPod.Name, "message", msg)
+			return checker.Unhealthy(ErrCodeVolumeIOSlow, msg), nil
+		}
+	}
+
+	if c.config.ImagePullThreshold > 0 && imagePullDuration >= c.config.ImagePullThreshold {
+		klog.V(3).InfoS("Image pull duration exceeded healthy threshold",
+			"checker", c.name,
+			"pod", synthPod.Name,
+			"group", group,
+			"image", c.config.SyntheticPodImage(),
+			"imagePullDuration", imagePullDuration.String(),
+			"imagePullThreshold", c.config.ImagePullThreshold.String(),
+		)
+		return checker.Unhealthy(ErrCodeImagePullDurationExceeded,
+			fmt.Sprintf("pulling image %s took %s, exceeding the threshold of %s", c.config.SyntheticPodImage(), imagePullDuration, c.config.ImagePullThreshold)), nil
+	}
+
 	return checker.Healthy(), nil
 }
 
@@ -257,42 +390,88 @@ func (c *PodStartupChecker) garbageCollect(ctx context.Context) error {
 	return errors.Join(errs...)
 }
 
-// Returns the duration between the pod creation and the container running. This is precise to the second.
+// Returns the duration between the pod creation and the container running. This is precise to the second. If the volume I/O of the pod
+// fails or does not complete, the returned error is a *volumeIOError. If the container is still waiting for its image to be pulled when
+// polling stops, the returned error is an *imagePullError. If the pod is stuck in a startup phase according to its conditions, e.g. it is
+// unschedulable, the returned error is a *startupPhaseError.
 func (c *PodStartupChecker) pollPodCreationToContainerRunningDuration(ctx context.Context, podName string) (time.Duration, error) {
 	var podCreationToContainerRunningDuration time.Duration
+	var lastPod *corev1.Pod
 	err := wait.PollUntilContextCancel(ctx, pollingInterval, true, func(ctx context.Context) (bool, error) {
 		pod, err := c.k8sClientset.CoreV1().Pods(c.config.SyntheticPodNamespace).Get(ctx, podName, metav1.GetOptions{})
 		if err != nil {
 			return false, nil
 		}
-		if len(pod.Status.ContainerStatuses) == 0 {
-			return false, nil
-		}
-		for _, status := range pod.Status.ContainerStatuses {
-			if status.State.Running != nil {
-				containerRunningTime := status.State.Running.StartedAt.Time
-				podCreationToContainerRunningDuration = containerRunningTime.Sub(pod.CreationTimestamp.Time)
-				return true, nil
-			}
+		lastPod = pod
+		if err := volumeIOFailure(pod); err != nil {
+			return false, err
 		}
-		return false, nil
+		duration, ok := creationToContainerRunningDuration(pod)
+		podCreationToContainerRunningDuration = duration
+		return ok, nil
 	})
-	return podCreationToContainerRunningDuration, err
+	if err != nil {
+		return 0, podStartupError(err, lastPod)
+	}
+	return podCreationToContainerRunningDuration, nil
+}
+
+// creationToContainerRunningDuration returns the duration between the pod creation and its first container running, or false if no
+// container is running.
+func creationToContainerRunningDuration(pod *corev1.Pod) (time.Duration, bool) {
+	runningTime := containerRunningTime(pod)
+	if runningTime.IsZero() {
+		return 0, false
+	}
+	return runningTime.Sub(pod.CreationTimestamp.Time), true
+}
+
+// podStartupError returns the error of waiting for the container of the pod to run. It joins err with a *volumeIOError if the volume I/O
+// of the pod did not complete, an *imagePullError if the container is waiting for its image to be pulled, or a *startupPhaseError if the
+// pod is stuck in a startup phase.
+func podStartupError(err error, pod *corev1.Pod) error {
+	if pod == nil {
+		return err
+	}
+	if ioErr := volumeIOStalledError(pod); ioErr != nil {
+		return errors.Join(err, ioErr)
+	}
+	var lastWaiting *corev1.ContainerStateWaiting
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.State.Waiting != nil {
+			lastWaiting = status.State.Waiting
+		}
+	}
+	if lastWaiting != nil && isImagePullFailure(lastWaiting.Reason) {
+		return errors.Join(err, &imagePullError{reason: lastWaiting.Reason, message: lastWaiting.Message})
+	}
+	if phaseErr := stalledPhaseError(pod); phaseErr != nil {
+		return errors.Join(err, phaseErr)
+	}
+	return err
 }
 
 // Returns the image pull duration including waiting time. This is precise to the millisecond.
 func (c *PodStartupChecker) getImagePullDuration(ctx context.Context, podName string) (time.Duration, error) {
 	events, err := c.k8sClientset.CoreV1().Events(c.config.SyntheticPodNamespace).List(ctx, metav1.ListOptions{
-		FieldSelector: fmt.Sprintf("involvedObject.name=%s,reason=Pulled", podName),
+		FieldSelector: fmt.Sprintf("involvedObject.name=%s,reason=%s", podName, eventReasonPulled),
 	})
 	if err != nil {
 		return 0, fmt.Errorf("failed to list events for pod %s: %w", podName, err)
 	}
 
+	return c.imagePullDurationFromEvents(podName, events.Items)
+}
+
+// Returns the image pull duration including waiting time from the Pulled event among the events of the pod.
+func (c *PodStartupChecker) imagePullDurationFromEvents(podName string, events []corev1.Event) (time.Duration, error) {
 	// events with reason=Pulled have messages expected to be in one of two formats:
 	// 1. "Successfully pulled image \"k8s.gcr.io/pause:3.2\" in 426ms (426ms including waiting). Image size: 299513 bytes."
 	// 2. "Container image \"k8s.gcr.io/pause:3.2\" already present on machine"
-	for _, event := range events.Items {
+	for _, event := range events {
+		if event.Reason != eventReasonPulled {
+			continue
+		}
 		if strings.Contains(event.Message, "Successfully pulled image") {
 			return c.parseImagePullDuration(event.Message)
 		}
@@ -318,7 +497,7 @@ func (c *PodStartupChecker) parseImagePullDuration(message string) (time.Duratio
 
 // createTCPConnection makes a simple TCP connection to the pod IP
 func (c *PodStartupChecker) createTCPConnection(ctx context.Context, podIP string) error {
-	address := fmt.Sprintf("%s:%s", podIP, strconv.Itoa(syntheticPodPort))
+	address := net.JoinHostPort(podIP, strconv.Itoa(c.config.SyntheticPodPort()))
 
 	conn, err := c.dialer.DialContext(ctx, "tcp", address)
 	if err != nil {
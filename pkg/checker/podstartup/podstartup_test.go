@@ -10,6 +10,7 @@ import (
 
 	"github.com/Azure/cluster-health-monitor/pkg/checker"
 	"github.com/Azure/cluster-health-monitor/pkg/config"
+	"github.com/Azure/cluster-health-monitor/pkg/karpenterclient"
 	retry "github.com/avast/retry-go/v4"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
@@ -48,6 +49,25 @@ func imageAlreadyPresentEvent(namespace, podName string) *corev1.Event {
 	}
 }
 
+func podEvent(namespace, podName, name, reason, message string, count int32, timestamp time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Namespace: namespace, Name: name},
+		Message:        message,
+		Reason:         reason,
+		Count:          count,
+		LastTimestamp:  metav1.NewTime(timestamp),
+		InvolvedObject: corev1.ObjectReference{Name: podName},
+	}
+}
+
+func podCondition(conditionType corev1.PodConditionType, transitionTime time.Time) corev1.PodCondition {
+	return corev1.PodCondition{
+		Type:               conditionType,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.NewTime(transitionTime),
+	}
+}
+
 // mockDialer is a mock implementation of the Dialer interface for testing
 type mockDialer struct {
 	dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
@@ -97,6 +117,16 @@ func TestPodStartupChecker_check(t *testing.T) {
 		hasCSICreateError         bool
 		hasStorageClassGetError   bool
 		fakeDynamicClient         *dynamicfake.FakeDynamicClient
+		imagePullDuration         time.Duration
+		imagePullThreshold        time.Duration
+		waitingReason             string
+		timeout                   time.Duration
+		// conditionDelays are the delays after the pod creation at which the pod conditions became true.
+		conditionDelays map[corev1.PodConditionType]time.Duration
+		sandboxFailures int32
+		volumeIO        *config.VolumeIOConfig
+		// volumeIOState is the state of the volume I/O container if volumeIO is set.
+		volumeIOState corev1.ContainerState
 	}
 
 	// Mutator function type
@@ -137,6 +167,171 @@ func TestPodStartupChecker_check(t *testing.T) {
 				g.Expect(fakeDynamicClient.Actions()).To(HaveLen(0)) // No dynamic client actions should be taken
 			},
 		},
+		{
+			name: "unhealthy result - scheduling was the slowest startup phase",
+			mutators: []scenarioMutator{
+				func(s *testScenario) {
+					s.startupDelay = 10 * time.Second
+					s.conditionDelays = map[corev1.PodConditionType]time.Duration{
+						corev1.PodScheduled:              7 * time.Second,
+						corev1.PodInitialized:            7 * time.Second,
+						corev1.PodReadyToStartContainers: 8 * time.Second,
+					}
+				},
+			},
+			validateResult: func(g *WithT, result *checker.Result, err error, fakeDynamicClient *dynamicfake.FakeDynamicClient) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result).ToNot(BeNil())
+				g.Expect(result.Status).To(Equal(checker.StatusUnhealthy))
+				g.Expect(result.Detail.Code).To(Equal(ErrCodePodSchedulingDurationExceeded))
+				g.Expect(result.Detail.Message).To(ContainSubstring("slowest phase scheduling took 7s"))
+			},
+		},
+		{
+			name: "unhealthy result - sandbox creation was the slowest startup phase",
+			mutators: []scenarioMutator{
+				func(s *testScenario) {
+					s.startupDelay = 10 * time.Second
+					s.conditionDelays = map[corev1.PodConditionType]time.Duration{
+						corev1.PodScheduled:              1 * time.Second,
+						corev1.PodInitialized:            1 * time.Second,
+						corev1.PodReadyToStartContainers: 9 * time.Second,
+					}
+					s.sandboxFailures = 3
+				},
+			},
+			validateResult: func(g *WithT, result *checker.Result, err error, fakeDynamicClient *dynamicfake.FakeDynamicClient) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result).ToNot(BeNil())
+				g.Expect(result.Status).To(Equal(checker.StatusUnhealthy))
+				g.Expect(result.Detail.Code).To(Equal(ErrCodeSandboxCreationDurationExceeded))
+				g.Expect(result.Detail.Message).To(ContainSubstring("slowest phase sandbox took 8s"))
+				g.Expect(result.Detail.Message).To(ContainSubstring("3 FailedCreatePodSandBox events"))
+			},
+		},
+		{
+			name: "unhealthy result - image pull took too long",
+			mutators: []scenarioMutator{
+				func(s *testScenario) {
+					s.imagePullDuration = 4 * time.Second
+					s.imagePullThreshold = 3 * time.Second
+				},
+			},
+			validateResult: func(g *WithT, result *checker.Result, err error, fakeDynamicClient *dynamicfake.FakeDynamicClient) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result).ToNot(BeNil())
+				g.Expect(result.Status).To(Equal(checker.StatusUnhealthy))
+				g.Expect(result.Detail.Code).To(Equal(ErrCodeImagePullDurationExceeded))
+			},
+		},
+		{
+			name: "healthy result - image pull within threshold",
+			mutators: []scenarioMutator{
+				func(s *testScenario) {
+					s.imagePullDuration = 2 * time.Second
+					s.imagePullThreshold = 3 * time.Second
+				},
+			},
+			validateResult: func(g *WithT, result *checker.Result, err error, fakeDynamicClient *dynamicfake.FakeDynamicClient) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result).ToNot(BeNil())
+				g.Expect(result.Status).To(Equal(checker.StatusHealthy))
+			},
+		},
+		{
+			name: "unhealthy result - image pull back-off",
+			mutators: []scenarioMutator{
+				func(s *testScenario) {
+					s.waitingReason = "ImagePullBackOff"
+					s.timeout = 1 * time.Second
+				},
+			},
+			validateResult: func(g *WithT, result *checker.Result, err error, fakeDynamicClient *dynamicfake.FakeDynamicClient) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result).ToNot(BeNil())
+				g.Expect(result.Status).To(Equal(checker.StatusUnhealthy))
+				g.Expect(result.Detail.Code).To(Equal(ErrCodeImagePullBackOff))
+				g.Expect(result.Detail.Message).To(ContainSubstring("Back-off pulling image"))
+			},
+		},
+		{
+			name: "healthy result - volume I/O verified",
+			mutators: []scenarioMutator{
+				func(s *testScenario) {
+					s.enabledCSITests = []config.CSIType{config.CSITypeAzureDisk}
+					s.preExistingStorageClasses = []string{testAzureDiskStorageClass}
+					s.volumeIO = &config.VolumeIOConfig{Threshold: 1 * time.Second}
+					s.volumeIOState = corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+						Message: "managed-csi ok 20000000 5000000\n",
+					}}
+				},
+			},
+			validateResult: func(g *WithT, result *checker.Result, err error, fakeDynamicClient *dynamicfake.FakeDynamicClient) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result).ToNot(BeNil())
+				g.Expect(result.Status).To(Equal(checker.StatusHealthy))
+			},
+		},
+		{
+			name: "unhealthy result - volume write failed",
+			mutators: []scenarioMutator{
+				func(s *testScenario) {
+					s.enabledCSITests = []config.CSIType{config.CSITypeAzureDisk}
+					s.preExistingStorageClasses = []string{testAzureDiskStorageClass}
+					s.volumeIO = &config.VolumeIOConfig{}
+					s.volumeIOState = corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+						Message:  "managed-csi write-failed dd: error writing '/mnt/managed-csi/cluster-health-monitor-volume-io': Read-only file system\n",
+					}}
+				},
+			},
+			validateResult: func(g *WithT, result *checker.Result, err error, fakeDynamicClient *dynamicfake.FakeDynamicClient) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result).ToNot(BeNil())
+				g.Expect(result.Status).To(Equal(checker.StatusUnhealthy))
+				g.Expect(result.Detail.Code).To(Equal(ErrCodeVolumeWriteFailed))
+				g.Expect(result.Detail.Message).To(ContainSubstring("managed-csi volume"))
+				g.Expect(result.Detail.Message).To(ContainSubstring("Read-only file system"))
+			},
+		},
+		{
+			name: "unhealthy result - volume I/O took too long",
+			mutators: []scenarioMutator{
+				func(s *testScenario) {
+					s.enabledCSITests = []config.CSIType{config.CSITypeAzureFile}
+					s.preExistingStorageClasses = []string{testAzureFileStorageClass}
+					s.volumeIO = &config.VolumeIOConfig{Threshold: 1 * time.Second}
+					s.volumeIOState = corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+						Message: "azurefile-csi ok 2000000000 1000000\n",
+					}}
+				},
+			},
+			validateResult: func(g *WithT, result *checker.Result, err error, fakeDynamicClient *dynamicfake.FakeDynamicClient) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result).ToNot(BeNil())
+				g.Expect(result.Status).To(Equal(checker.StatusUnhealthy))
+				g.Expect(result.Detail.Code).To(Equal(ErrCodeVolumeIOSlow))
+				g.Expect(result.Detail.Message).To(Equal("writing 1Mi to the azurefile-csi volume took 2s, exceeding the threshold of 1s"))
+			},
+		},
+		{
+			name: "unhealthy result - volume I/O did not complete",
+			mutators: []scenarioMutator{
+				func(s *testScenario) {
+					s.enabledCSITests = []config.CSIType{config.CSITypeAzureFile}
+					s.preExistingStorageClasses = []string{testAzureFileStorageClass}
+					s.volumeIO = &config.VolumeIOConfig{}
+					s.volumeIOState = corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+					s.timeout = 1 * time.Second
+				},
+			},
+			validateResult: func(g *WithT, result *checker.Result, err error, fakeDynamicClient *dynamicfake.FakeDynamicClient) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(result).ToNot(BeNil())
+				g.Expect(result.Status).To(Equal(checker.StatusUnhealthy))
+				g.Expect(result.Detail.Code).To(Equal(ErrCodeVolumeIOSlow))
+			},
+		},
 		{
 			name: "error - max synthetic pods reached",
 			mutators: []scenarioMutator{
@@ -286,13 +481,13 @@ func TestPodStartupChecker_check(t *testing.T) {
 				g.Expect(result.Status).To(Equal(checker.StatusHealthy))
 
 				g.Expect(fakeDynamicClient.Actions()).To(HaveLen(4))
-				g.Expect(fakeDynamicClient.Actions()[0].GetResource()).To(Equal(NodePoolGVR))
+				g.Expect(fakeDynamicClient.Actions()[0].GetResource()).To(Equal(karpenterclient.NodePoolGVR))
 				g.Expect(fakeDynamicClient.Actions()[0].GetVerb()).To(Equal("list"))
 				g.Expect(fakeDynamicClient.Actions()[1].GetResource()).To(Equal(crdGVR))
 				g.Expect(fakeDynamicClient.Actions()[1].GetVerb()).To(Equal("get"))
-				g.Expect(fakeDynamicClient.Actions()[2].GetResource()).To(Equal(NodePoolGVR))
+				g.Expect(fakeDynamicClient.Actions()[2].GetResource()).To(Equal(karpenterclient.NodePoolGVR))
 				g.Expect(fakeDynamicClient.Actions()[2].GetVerb()).To(Equal("create"))
-				g.Expect(fakeDynamicClient.Actions()[3].GetResource()).To(Equal(NodePoolGVR))
+				g.Expect(fakeDynamicClient.Actions()[3].GetResource()).To(Equal(karpenterclient.NodePoolGVR))
 				g.Expect(fakeDynamicClient.Actions()[3].GetVerb()).To(Equal("delete"))
 			},
 		},
@@ -325,13 +520,13 @@ func TestPodStartupChecker_check(t *testing.T) {
 				g.Expect(result.Status).To(Equal(checker.StatusHealthy))
 
 				g.Expect(fakeDynamicClient.Actions()).To(HaveLen(4))
-				g.Expect(fakeDynamicClient.Actions()[0].GetResource()).To(Equal(NodePoolGVR))
+				g.Expect(fakeDynamicClient.Actions()[0].GetResource()).To(Equal(karpenterclient.NodePoolGVR))
 				g.Expect(fakeDynamicClient.Actions()[0].GetVerb()).To(Equal("list"))
 				g.Expect(fakeDynamicClient.Actions()[1].GetResource()).To(Equal(crdGVR))
 				g.Expect(fakeDynamicClient.Actions()[1].GetVerb()).To(Equal("get"))
-				g.Expect(fakeDynamicClient.Actions()[2].GetResource()).To(Equal(NodePoolGVR))
+				g.Expect(fakeDynamicClient.Actions()[2].GetResource()).To(Equal(karpenterclient.NodePoolGVR))
 				g.Expect(fakeDynamicClient.Actions()[2].GetVerb()).To(Equal("create"))
-				g.Expect(fakeDynamicClient.Actions()[3].GetResource()).To(Equal(NodePoolGVR))
+				g.Expect(fakeDynamicClient.Actions()[3].GetResource()).To(Equal(karpenterclient.NodePoolGVR))
 				g.Expect(fakeDynamicClient.Actions()[3].GetVerb()).To(Equal("delete"))
 			},
 		},
@@ -382,15 +577,15 @@ func TestPodStartupChecker_check(t *testing.T) {
 				g.Expect(result.Status).To(Equal(checker.StatusHealthy))
 
 				g.Expect(fakeDynamicClient.Actions()).To(HaveLen(5))
-				g.Expect(fakeDynamicClient.Actions()[0].GetResource()).To(Equal(NodePoolGVR))
+				g.Expect(fakeDynamicClient.Actions()[0].GetResource()).To(Equal(karpenterclient.NodePoolGVR))
 				g.Expect(fakeDynamicClient.Actions()[0].GetVerb()).To(Equal("list"))
-				g.Expect(fakeDynamicClient.Actions()[1].GetResource()).To(Equal(NodePoolGVR))
+				g.Expect(fakeDynamicClient.Actions()[1].GetResource()).To(Equal(karpenterclient.NodePoolGVR))
 				g.Expect(fakeDynamicClient.Actions()[1].GetVerb()).To(Equal("delete"))
 				g.Expect(fakeDynamicClient.Actions()[2].GetResource()).To(Equal(crdGVR))
 				g.Expect(fakeDynamicClient.Actions()[2].GetVerb()).To(Equal("get"))
-				g.Expect(fakeDynamicClient.Actions()[3].GetResource()).To(Equal(NodePoolGVR))
+				g.Expect(fakeDynamicClient.Actions()[3].GetResource()).To(Equal(karpenterclient.NodePoolGVR))
 				g.Expect(fakeDynamicClient.Actions()[3].GetVerb()).To(Equal("create"))
-				g.Expect(fakeDynamicClient.Actions()[4].GetResource()).To(Equal(NodePoolGVR))
+				g.Expect(fakeDynamicClient.Actions()[4].GetResource()).To(Equal(karpenterclient.NodePoolGVR))
 				g.Expect(fakeDynamicClient.Actions()[4].GetVerb()).To(Equal("delete"))
 			},
 		},
@@ -414,7 +609,7 @@ func TestPodStartupChecker_check(t *testing.T) {
 				g.Expect(result.Status).To(Equal(checker.StatusSkipped))
 
 				g.Expect(fakeDynamicClient.Actions()).To(HaveLen(2))
-				g.Expect(fakeDynamicClient.Actions()[0].GetResource()).To(Equal(NodePoolGVR))
+				g.Expect(fakeDynamicClient.Actions()[0].GetResource()).To(Equal(karpenterclient.NodePoolGVR))
 				g.Expect(fakeDynamicClient.Actions()[0].GetVerb()).To(Equal("list"))
 				g.Expect(fakeDynamicClient.Actions()[1].GetResource()).To(Equal(crdGVR))
 				g.Expect(fakeDynamicClient.Actions()[1].GetVerb()).To(Equal("get"))
@@ -450,11 +645,11 @@ func TestPodStartupChecker_check(t *testing.T) {
 				g.Expect(err.Error()).To(ContainSubstring("unexpected error occurred while creating node pool"))
 
 				g.Expect(fakeDynamicClient.Actions()).To(HaveLen(3))
-				g.Expect(fakeDynamicClient.Actions()[0].GetResource()).To(Equal(NodePoolGVR))
+				g.Expect(fakeDynamicClient.Actions()[0].GetResource()).To(Equal(karpenterclient.NodePoolGVR))
 				g.Expect(fakeDynamicClient.Actions()[0].GetVerb()).To(Equal("list"))
 				g.Expect(fakeDynamicClient.Actions()[1].GetResource()).To(Equal(crdGVR))
 				g.Expect(fakeDynamicClient.Actions()[1].GetVerb()).To(Equal("get"))
-				g.Expect(fakeDynamicClient.Actions()[2].GetResource()).To(Equal(NodePoolGVR))
+				g.Expect(fakeDynamicClient.Actions()[2].GetResource()).To(Equal(karpenterclient.NodePoolGVR))
 				g.Expect(fakeDynamicClient.Actions()[2].GetVerb()).To(Equal("create"))
 			},
 		},
@@ -517,8 +712,9 @@ func TestPodStartupChecker_check(t *testing.T) {
 				startupDelay:   3 * time.Second,
 				hasDeleteError: false,
 				dialer:         successfulDialer(),
+				timeout:        5 * time.Second,
 				fakeDynamicClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
-					NodePoolGVR: "NodePoolList",
+					karpenterclient.NodePoolGVR: "NodePoolList",
 				}),
 			}
 
@@ -528,11 +724,18 @@ func TestPodStartupChecker_check(t *testing.T) {
 			}
 
 			// Build the test client and setup
+			podCreationTimestamp := time.Now()
 			events := []runtime.Object{imageAlreadyPresentEvent(scenario.namespace, scenario.podName)}
+			if scenario.imagePullDuration > 0 {
+				events = []runtime.Object{imageSuccessfullyPulledEvent(scenario.namespace, scenario.podName, scenario.imagePullDuration)}
+			}
+			if scenario.sandboxFailures > 0 {
+				events = append(events, podEvent(scenario.namespace, scenario.podName, "event2", "FailedCreatePodSandBox",
+					"failed to setup network for sandbox", scenario.sandboxFailures, podCreationTimestamp.Add(2*time.Second)))
+			}
 			client := k8sfake.NewClientset(events...)
 
 			// Add pre-existing pods if any
-			podCreationTimestamp := time.Now()
 			for _, podName := range scenario.preExistingPods {
 				pod := podWithLabels(podName, scenario.namespace, scenario.labels, podCreationTimestamp)
 				client.CoreV1().Pods(scenario.namespace).Create(context.Background(), pod, metav1.CreateOptions{}) //nolint:errcheck
@@ -553,6 +756,25 @@ func TestPodStartupChecker_check(t *testing.T) {
 					},
 				}},
 			}
+			if scenario.volumeIO != nil {
+				fakePod.Status.InitContainerStatuses = []corev1.ContainerStatus{{Name: volumeIOContainerName, State: scenario.volumeIOState}}
+				if scenario.volumeIOState.Terminated == nil || scenario.volumeIOState.Terminated.ExitCode != 0 {
+					fakePod.Status.ContainerStatuses[0].State = corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"},
+					}
+				}
+			}
+			for conditionType, delay := range scenario.conditionDelays {
+				fakePod.Status.Conditions = append(fakePod.Status.Conditions, podCondition(conditionType, podCreationTimestamp.Add(delay)))
+			}
+			if scenario.waitingReason != "" {
+				fakePod.Status.ContainerStatuses[0].State = corev1.ContainerState{
+					Waiting: &corev1.ContainerStateWaiting{
+						Reason:  scenario.waitingReason,
+						Message: "Back-off pulling image \"registry.example.com/nginx:latest\"",
+					},
+				}
+			}
 
 			// Add reactors
 			client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
@@ -598,8 +820,10 @@ func TestPodStartupChecker_check(t *testing.T) {
 					TCPRetryInterval:           1 * time.Millisecond,
 					EnableNodeProvisioningTest: scenario.enableNodeProvisioning,
 					EnabledCSIs:                csiConfigsFromTypes(scenario.enabledCSITests),
+					ImagePullThreshold:         scenario.imagePullThreshold,
+					VolumeIO:                   scenario.volumeIO,
 				},
-				timeout:       5 * time.Second,
+				timeout:       scenario.timeout,
 				k8sClientset:  client,
 				dialer:        scenario.dialer,
 				dynamicClient: scenario.fakeDynamicClient,
@@ -653,7 +877,7 @@ func TestPodStartupChecker_garbageCollect(t *testing.T) {
 			enableNodeProvisioningTest: true,
 			client:                     k8sfake.NewClientset(),
 			dynamicClient: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
-				NodePoolGVR: "NodePoolList",
+				karpenterclient.NodePoolGVR: "NodePoolList",
 			}),
 			validateRes: func(g *WithT, err error) {
 				g.Expect(err).NotTo(HaveOccurred())
@@ -665,7 +889,7 @@ func TestPodStartupChecker_garbageCollect(t *testing.T) {
 			client:                     k8sfake.NewClientset(),
 			dynamicClient: func() *dynamicfake.FakeDynamicClient {
 				client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
-					NodePoolGVR: "NodePoolList",
+					karpenterclient.NodePoolGVR: "NodePoolList",
 				})
 				client.PrependReactor("list", "nodepools", func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
 					return true, nil, errors.New("error listing node pools")
@@ -750,6 +974,74 @@ func TestPodStartupChecker_pollPodCreationToContainerRunningDuration(t *testing.
 				g.Expect(duration).To(Equal(10 * time.Second))
 			},
 		},
+		{
+			name: "error - image pull failed",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      podName,
+					Namespace: syntheticPodNamespace,
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{{
+						State: corev1.ContainerState{
+							Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "unauthorized"},
+						},
+					}},
+				},
+			},
+			validateRes: func(g *WithT, duration time.Duration, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+				var pullErr *imagePullError
+				g.Expect(errors.As(err, &pullErr)).To(BeTrue())
+				g.Expect(pullErr.code()).To(Equal(ErrCodeImagePullError))
+				g.Expect(pullErr.Error()).To(ContainSubstring("unauthorized"))
+			},
+		},
+		{
+			name: "error - pod unschedulable",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      podName,
+					Namespace: syntheticPodNamespace,
+				},
+				Status: corev1.PodStatus{
+					Conditions: []corev1.PodCondition{{
+						Type:    corev1.PodScheduled,
+						Status:  corev1.ConditionFalse,
+						Reason:  corev1.PodReasonUnschedulable,
+						Message: "0/3 nodes are available",
+					}},
+				},
+			},
+			validateRes: func(g *WithT, duration time.Duration, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+				var phaseErr *startupPhaseError
+				g.Expect(errors.As(err, &phaseErr)).To(BeTrue())
+				g.Expect(phaseErr.code()).To(Equal(ErrCodePodSchedulingDurationExceeded))
+				g.Expect(phaseErr.Error()).To(ContainSubstring("0/3 nodes are available"))
+			},
+		},
+		{
+			name: "error - polling timeout while container creating",
+			pod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      podName,
+					Namespace: syntheticPodNamespace,
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{{
+						State: corev1.ContainerState{
+							Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"},
+						},
+					}},
+				},
+			},
+			validateRes: func(g *WithT, duration time.Duration, err error) {
+				g.Expect(err).To(Equal(context.DeadlineExceeded))
+			},
+		},
 		{
 			name: "error - polling timeout occurred",
 			pod: &corev1.Pod{
@@ -949,6 +1241,7 @@ func TestPodStartupChecker_makeTCPRequest(t *testing.T) {
 			g := NewWithT(t)
 
 			checker := &PodStartupChecker{
+				config: &config.PodStartupConfig{},
 				dialer: tt.dialer,
 			}
 
@@ -9,86 +9,35 @@ import (
 	"github.com/Azure/cluster-health-monitor/pkg/config"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 )
 
-const (
-	azureDiskPVCNamePrefix = "clusterhealthmonitor-azuredisk-pvc"
-	azureFilePVCNamePrefix = "clusterhealthmonitor-azurefile-pvc"
-	azureBlobPVCNamePrefix = "clusterhealthmonitor-azureblob-pvc"
-)
-
-func (c *PodStartupChecker) azureDiskPVC(timestampStr string, storageClassName string) *corev1.PersistentVolumeClaim {
-	return &corev1.PersistentVolumeClaim{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "PersistentVolumeClaim",
-			APIVersion: "v1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-%s", azureDiskPVCNamePrefix, timestampStr),
-			Namespace: c.config.SyntheticPodNamespace,
-			Labels:    c.syntheticPodLabels(),
-		},
-		Spec: corev1.PersistentVolumeClaimSpec{
-			AccessModes: []corev1.PersistentVolumeAccessMode{
-				corev1.ReadWriteOnce,
-			},
-			StorageClassName: &storageClassName,
-			Resources: corev1.VolumeResourceRequirements{
-				Requests: corev1.ResourceList{
-					corev1.ResourceStorage: resource.MustParse("5Gi"),
-				},
-			},
-		},
-	}
-}
+const csiPVCNamePrefix = "clusterhealthmonitor-csi-pvc"
 
-func (c *PodStartupChecker) azureFilePVC(timestampStr string, storageClassName string) *corev1.PersistentVolumeClaim {
+// csiPVC returns the PVC of the storage probe at the given index of EnabledCSIs.
+func (c *PodStartupChecker) csiPVC(timestampStr string, index int, csi config.CSIConfig) *corev1.PersistentVolumeClaim {
+	storageClassName := csi.StorageClass
+	volumeMode := csi.PVCVolumeMode()
 	return &corev1.PersistentVolumeClaim{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "PersistentVolumeClaim",
 			APIVersion: "v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-%s", azureFilePVCNamePrefix, timestampStr),
+			Name:      fmt.Sprintf("%s-%s-%d", csiPVCNamePrefix, timestampStr, index),
 			Namespace: c.config.SyntheticPodNamespace,
 			Labels:    c.syntheticPodLabels(),
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes: []corev1.PersistentVolumeAccessMode{
-				corev1.ReadWriteMany,
+				csi.PVCAccessMode(),
 			},
 			StorageClassName: &storageClassName,
+			VolumeMode:       &volumeMode,
 			Resources: corev1.VolumeResourceRequirements{
 				Requests: corev1.ResourceList{
-					corev1.ResourceStorage: resource.MustParse("5Gi"),
-				},
-			},
-		},
-	}
-}
-
-func (c *PodStartupChecker) azureBlobPVC(timestampStr string, storageClassName string) *corev1.PersistentVolumeClaim {
-	return &corev1.PersistentVolumeClaim{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "PersistentVolumeClaim",
-			APIVersion: "v1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-%s", azureBlobPVCNamePrefix, timestampStr),
-			Namespace: c.config.SyntheticPodNamespace,
-			Labels:    c.syntheticPodLabels(),
-		},
-		Spec: corev1.PersistentVolumeClaimSpec{
-			AccessModes: []corev1.PersistentVolumeAccessMode{
-				corev1.ReadWriteMany,
-			},
-			StorageClassName: &storageClassName,
-			Resources: corev1.VolumeResourceRequirements{
-				Requests: corev1.ResourceList{
-					corev1.ResourceStorage: resource.MustParse("5Gi"),
+					corev1.ResourceStorage: csi.PVCSize(),
 				},
 			},
 		},
@@ -99,57 +48,27 @@ func (c *PodStartupChecker) validateStorageClasses(ctx context.Context) error {
 	for _, csi := range c.config.EnabledCSIs {
 		_, err := c.k8sClientset.StorageV1().StorageClasses().Get(ctx, csi.StorageClass, metav1.GetOptions{})
 		if err != nil {
-			return fmt.Errorf("failed to get StorageClass %q for CSI type %q: %w", csi.StorageClass, csi.Type, err)
+			return fmt.Errorf("failed to get StorageClass %q: %w", csi.StorageClass, err)
 		}
 	}
 	return nil
 }
 
 func (c *PodStartupChecker) createCSIResources(ctx context.Context, timestampStr string) error {
-	for _, csi := range c.config.EnabledCSIs {
-		switch csi.Type {
-		case config.CSITypeAzureDisk:
-			_, err := c.k8sClientset.CoreV1().PersistentVolumeClaims(c.config.SyntheticPodNamespace).Create(ctx, c.azureDiskPVC(timestampStr, csi.StorageClass), metav1.CreateOptions{})
-			if err != nil {
-				return fmt.Errorf("failed to create Azure Disk PVC: %w", err)
-			}
-		case config.CSITypeAzureFile:
-			_, err := c.k8sClientset.CoreV1().PersistentVolumeClaims(c.config.SyntheticPodNamespace).Create(ctx, c.azureFilePVC(timestampStr, csi.StorageClass), metav1.CreateOptions{})
-			if err != nil {
-				return fmt.Errorf("failed to create Azure File PVC: %w", err)
-			}
-		case config.CSITypeAzureBlob:
-			_, err := c.k8sClientset.CoreV1().PersistentVolumeClaims(c.config.SyntheticPodNamespace).Create(ctx, c.azureBlobPVC(timestampStr, csi.StorageClass), metav1.CreateOptions{})
-			if err != nil {
-				return fmt.Errorf("failed to create Azure Blob PVC: %w", err)
-			}
-		default:
-			return fmt.Errorf("failed to create resources for unsupported CSI type: %s", csi.Type)
+	for i, csi := range c.config.EnabledCSIs {
+		_, err := c.k8sClientset.CoreV1().PersistentVolumeClaims(c.config.SyntheticPodNamespace).Create(ctx, c.csiPVC(timestampStr, i, csi), metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create PVC of StorageClass %q: %w", csi.StorageClass, err)
 		}
 	}
 	return nil
 }
 
 func (c *PodStartupChecker) deleteCSIResources(ctx context.Context, timestampStr string) error {
-	for _, csi := range c.config.EnabledCSIs {
-		switch csi.Type {
-		case config.CSITypeAzureDisk:
-			err := c.k8sClientset.CoreV1().PersistentVolumeClaims(c.config.SyntheticPodNamespace).Delete(ctx, c.azureDiskPVC(timestampStr, csi.StorageClass).Name, metav1.DeleteOptions{})
-			if err != nil && !apierrors.IsNotFound(err) {
-				return fmt.Errorf("failed to delete Azure Disk PVC: %w", err)
-			}
-		case config.CSITypeAzureFile:
-			err := c.k8sClientset.CoreV1().PersistentVolumeClaims(c.config.SyntheticPodNamespace).Delete(ctx, c.azureFilePVC(timestampStr, csi.StorageClass).Name, metav1.DeleteOptions{})
-			if err != nil && !apierrors.IsNotFound(err) {
-				return fmt.Errorf("failed to delete Azure File PVC: %w", err)
-			}
-		case config.CSITypeAzureBlob:
-			err := c.k8sClientset.CoreV1().PersistentVolumeClaims(c.config.SyntheticPodNamespace).Delete(ctx, c.azureBlobPVC(timestampStr, csi.StorageClass).Name, metav1.DeleteOptions{})
-			if err != nil && !apierrors.IsNotFound(err) {
-				return fmt.Errorf("failed to delete Azure Blob PVC: %w", err)
-			}
-		default:
-			return fmt.Errorf("failed to delete resources for unsupported CSI type: %s", csi.Type)
+	for i, csi := range c.config.EnabledCSIs {
+		err := c.k8sClientset.CoreV1().PersistentVolumeClaims(c.config.SyntheticPodNamespace).Delete(ctx, c.csiPVC(timestampStr, i, csi).Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete PVC of StorageClass %q: %w", csi.StorageClass, err)
 		}
 	}
 	return nil
@@ -33,15 +33,78 @@ func TestStorageResources(t *testing.T) {
 
 	g.Expect(pods.Spec.Volumes[0]).ToNot(BeNil())
 	g.Expect(pods.Spec.Volumes[0].PersistentVolumeClaim).ToNot(BeNil())
-	g.Expect(pods.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal(checker.azureDiskPVC("timestampstr", testAzureDiskStorageClass).Name))
+	g.Expect(pods.Spec.Volumes[0].PersistentVolumeClaim.ClaimName).To(Equal(checker.csiPVC("timestampstr", 0, checker.config.EnabledCSIs[0]).Name))
 
 	g.Expect(pods.Spec.Volumes[1]).ToNot(BeNil())
 	g.Expect(pods.Spec.Volumes[1].PersistentVolumeClaim).ToNot(BeNil())
-	g.Expect(pods.Spec.Volumes[1].PersistentVolumeClaim.ClaimName).To(Equal(checker.azureFilePVC("timestampstr", testAzureFileStorageClass).Name))
+	g.Expect(pods.Spec.Volumes[1].PersistentVolumeClaim.ClaimName).To(Equal(checker.csiPVC("timestampstr", 1, checker.config.EnabledCSIs[1]).Name))
 
 	g.Expect(pods.Spec.Volumes[2]).ToNot(BeNil())
 	g.Expect(pods.Spec.Volumes[2].PersistentVolumeClaim).ToNot(BeNil())
-	g.Expect(pods.Spec.Volumes[2].PersistentVolumeClaim.ClaimName).To(Equal(checker.azureBlobPVC("timestampstr", testAzureBlobStorageClass).Name))
+	g.Expect(pods.Spec.Volumes[2].PersistentVolumeClaim.ClaimName).To(Equal(checker.csiPVC("timestampstr", 2, checker.config.EnabledCSIs[2]).Name))
+}
+
+func TestCSIPVC(t *testing.T) {
+	tests := []struct {
+		name               string
+		csi                config.CSIConfig
+		expectedAccessMode corev1.PersistentVolumeAccessMode
+		expectedSize       string
+		expectedVolumeMode corev1.PersistentVolumeMode
+	}{
+		{
+			name:               "azure disk preset",
+			csi:                config.CSIConfig{Type: config.CSITypeAzureDisk, StorageClass: testAzureDiskStorageClass},
+			expectedAccessMode: corev1.ReadWriteOnce,
+			expectedSize:       "5Gi",
+			expectedVolumeMode: corev1.PersistentVolumeFilesystem,
+		},
+		{
+			name:               "azure file preset",
+			csi:                config.CSIConfig{Type: config.CSITypeAzureFile, StorageClass: testAzureFileStorageClass},
+			expectedAccessMode: corev1.ReadWriteMany,
+			expectedSize:       "5Gi",
+			expectedVolumeMode: corev1.PersistentVolumeFilesystem,
+		},
+		{
+			name: "preset with overrides",
+			csi: config.CSIConfig{
+				Type:         config.CSITypeAzureBlob,
+				StorageClass: testAzureBlobStorageClass,
+				AccessMode:   string(corev1.ReadWriteOnce),
+				Size:         "10Gi",
+			},
+			expectedAccessMode: corev1.ReadWriteOnce,
+			expectedSize:       "10Gi",
+			expectedVolumeMode: corev1.PersistentVolumeFilesystem,
+		},
+		{
+			name:               "generic block volume",
+			csi:                config.CSIConfig{StorageClass: "local-path", Size: "1Gi", VolumeMode: string(corev1.PersistentVolumeBlock)},
+			expectedAccessMode: corev1.ReadWriteOnce,
+			expectedSize:       "1Gi",
+			expectedVolumeMode: corev1.PersistentVolumeBlock,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			checker := &PodStartupChecker{
+				name:   "test",
+				config: &config.PodStartupConfig{SyntheticPodNamespace: "default", SyntheticPodLabelKey: _testSyntheticLabelKey},
+			}
+
+			pvc := checker.csiPVC("timestampstr", 1, tt.csi)
+			g.Expect(pvc.Name).To(Equal("clusterhealthmonitor-csi-pvc-timestampstr-1"))
+			g.Expect(pvc.Namespace).To(Equal("default"))
+			g.Expect(pvc.Labels).To(Equal(checker.syntheticPodLabels()))
+			g.Expect(*pvc.Spec.StorageClassName).To(Equal(tt.csi.StorageClass))
+			g.Expect(pvc.Spec.AccessModes).To(Equal([]corev1.PersistentVolumeAccessMode{tt.expectedAccessMode}))
+			g.Expect(pvc.Spec.Resources.Requests.Storage().String()).To(Equal(tt.expectedSize))
+			g.Expect(*pvc.Spec.VolumeMode).To(Equal(tt.expectedVolumeMode))
+		})
+	}
 }
 
 func TestCreateCSIResources(t *testing.T) {
@@ -145,9 +208,9 @@ func TestDeleteCSIResources(t *testing.T) {
 		{
 			name: "all resources successfully deleted",
 			k8sClient: k8sfake.NewClientset(
-				pvcWithLabels("clusterhealthmonitor-azuredisk-pvc-timestampstr", syntheticPodNamespace, pvcLabels, time.Now()),
-				pvcWithLabels("clusterhealthmonitor-azurefile-pvc-timestampstr", syntheticPodNamespace, pvcLabels, time.Now()),
-				pvcWithLabels("clusterhealthmonitor-azureblob-pvc-timestampstr", syntheticPodNamespace, pvcLabels, time.Now()),
+				pvcWithLabels("clusterhealthmonitor-csi-pvc-timestampstr-0", syntheticPodNamespace, pvcLabels, time.Now()),
+				pvcWithLabels("clusterhealthmonitor-csi-pvc-timestampstr-1", syntheticPodNamespace, pvcLabels, time.Now()),
+				pvcWithLabels("clusterhealthmonitor-csi-pvc-timestampstr-2", syntheticPodNamespace, pvcLabels, time.Now()),
 			),
 			enabledCSIs: []config.CSIType{config.CSITypeAzureDisk, config.CSITypeAzureFile, config.CSITypeAzureBlob},
 			validateFunc: func(g *WithT, err error, k8sClient *k8sfake.Clientset) {
@@ -161,8 +224,8 @@ func TestDeleteCSIResources(t *testing.T) {
 			name:        "resources successfully deleted with some resources not found",
 			enabledCSIs: []config.CSIType{config.CSITypeAzureDisk, config.CSITypeAzureFile, config.CSITypeAzureBlob},
 			k8sClient: k8sfake.NewClientset(
-				pvcWithLabels("clusterhealthmonitor-azuredisk-pvc-timestampstr", syntheticPodNamespace, pvcLabels, time.Now()),
-				pvcWithLabels("clusterhealthmonitor-azurefile-pvc-timestampstr", syntheticPodNamespace, pvcLabels, time.Now()),
+				pvcWithLabels("clusterhealthmonitor-csi-pvc-timestampstr-0", syntheticPodNamespace, pvcLabels, time.Now()),
+				pvcWithLabels("clusterhealthmonitor-csi-pvc-timestampstr-1", syntheticPodNamespace, pvcLabels, time.Now()),
 				// AzureBlob PVC intentionally missing to trigger not found
 			),
 			validateFunc: func(g *WithT, err error, k8sClient *k8sfake.Clientset) {
@@ -177,8 +240,8 @@ func TestDeleteCSIResources(t *testing.T) {
 			enabledCSIs: []config.CSIType{config.CSITypeAzureFile, config.CSITypeAzureBlob},
 			k8sClient: func() *k8sfake.Clientset {
 				client := k8sfake.NewClientset(
-					pvcWithLabels("clusterhealthmonitor-azurefile-pvc-timestampstr", syntheticPodNamespace, pvcLabels, time.Now()),
-					pvcWithLabels("clusterhealthmonitor-azureblob-pvc-timestampstr", syntheticPodNamespace, pvcLabels, time.Now()),
+					pvcWithLabels("clusterhealthmonitor-csi-pvc-timestampstr-0", syntheticPodNamespace, pvcLabels, time.Now()),
+					pvcWithLabels("clusterhealthmonitor-csi-pvc-timestampstr-1", syntheticPodNamespace, pvcLabels, time.Now()),
 				)
 				client.PrependReactor("delete", "persistentvolumeclaims", func(action k8stesting.Action) (handled bool, ret runtime.Object, err error) {
 					return true, nil, errors.New("unexpected error occurred while deleting persistent volume claim")
@@ -187,7 +250,7 @@ func TestDeleteCSIResources(t *testing.T) {
 			}(),
 			validateFunc: func(g *WithT, err error, k8sClient *k8sfake.Clientset) {
 				g.Expect(err).To(HaveOccurred())
-				g.Expect(err.Error()).To(ContainSubstring("failed to delete Azure File PVC"))
+				g.Expect(err.Error()).To(ContainSubstring(`failed to delete PVC of StorageClass "azurefile-csi"`))
 				g.Expect(err.Error()).To(ContainSubstring("unexpected error occurred while deleting persistent volume claim"))
 			},
 		},
@@ -0,0 +1,342 @@
+package podstartup
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/Azure/cluster-health-monitor/pkg/config"
+)
+
+// Operations on the volumes of the synthetic pod recorded in the storage operation duration histogram.
+const (
+	storageOperationProvisioning = "provisioning"
+	storageOperationAttach       = "attach"
+	storageOperationMount        = "mount"
+)
+
+// Reasons of the PVC and pod events used to measure the storage operations.
+const (
+	eventReasonProvisioning          = "Provisioning"
+	eventReasonProvisioningSucceeded = "ProvisioningSucceeded"
+	eventReasonWaitForFirstConsumer  = "WaitForFirstConsumer"
+	eventReasonFailedMount           = "FailedMount"
+)
+
+// How long to look into the volumes of a synthetic pod that did not start before the checker timed out.
+var storageDiagnosisTimeout = 5 * time.Second
+
+// storageOperationErrCodes maps each storage operation to the error code of the result when it exceeded its threshold or did not complete.
+var storageOperationErrCodes = map[string]string{
+	storageOperationProvisioning: ErrCodePVCProvisioningTimeout,
+	storageOperationAttach:       ErrCodeVolumeAttachTimeout,
+	storageOperationMount:        ErrCodeVolumeMountTimeout,
+}
+
+// storageOperation is the duration of an operation on the volume of a storage probe.
+type storageOperation struct {
+	name string
+	// volumeName is the name of the pod volume of the storage probe, which tells probes of the same storage class apart.
+	volumeName   string
+	storageClass string
+	duration     time.Duration
+	// detail is additional information about the operation, e.g. mount failures, reported if it exceeded its threshold.
+	detail string
+}
+
+// csiClaim is the PVC of a storage probe mounted in the synthetic pod.
+type csiClaim struct {
+	csi        config.CSIConfig
+	volumeName string
+	claimName  string
+}
+
+// csiClaims returns the PVCs of the storage probes mounted in the pod.
+func (c *PodStartupChecker) csiClaims(pod *corev1.Pod) []csiClaim {
+	claimNames := make(map[string]string)
+	for _, volume := range pod.Spec.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			claimNames[volume.Name] = volume.PersistentVolumeClaim.ClaimName
+		}
+	}
+	var claims []csiClaim
+	for i, csi := range c.config.EnabledCSIs {
+		if claimName, ok := claimNames[csiVolumeName(i)]; ok {
+			claims = append(claims, csiClaim{csi: csi, volumeName: csiVolumeName(i), claimName: claimName})
+		}
+	}
+	return claims
+}
+
+// getStorageOperations measures the operations on the volumes of a pod with a running container. For each storage probe, they are:
+//   - provisioning: from the Provisioning event of the PVC, or its creation if the event is missing, until its ProvisioningSucceeded event.
+//     Not measured if the PVC was bound to an existing volume.
+//   - attach: from the creation of the VolumeAttachment of the volume on the pod's node until the SuccessfulAttachVolume event of the pod
+//     for the volume. Not measured for volumes that are not attached.
+//   - mount: from the volume being attached, or the pod being scheduled for volumes that are not attached, until the pod sandbox is ready.
+//     The kubelet does not report when mounting ends, so this includes creating the pod sandbox.
+//
+// The durations are derived from the timestamps of the objects and events, which are precise to the second.
+func (c *PodStartupChecker) getStorageOperations(ctx context.Context, pod *corev1.Pod) ([]storageOperation, error) {
+	claims := c.csiClaims(pod)
+	if len(claims) == 0 {
+		return nil, nil
+	}
+	podEvents, err := c.listEvents(ctx, "pod", pod.Name)
+	if err != nil {
+		return nil, err
+	}
+	attachments, err := c.k8sClientset.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list volume attachments: %w", err)
+	}
+
+	var operations []storageOperation
+	for _, claim := range claims {
+		pvc, err := c.k8sClientset.CoreV1().PersistentVolumeClaims(c.config.SyntheticPodNamespace).Get(ctx, claim.claimName, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get PVC %s: %w", claim.claimName, err)
+		}
+		pvcEvents, err := c.listEvents(ctx, "PVC", pvc.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		if duration, ok := provisioningDuration(pvc, pvcEvents); ok {
+			operations = append(operations, storageOperation{
+				name:         storageOperationProvisioning,
+				volumeName:   claim.volumeName,
+				storageClass: claim.csi.StorageClass,
+				duration:     duration,
+			})
+		}
+		mountStart := conditionTime(pod, corev1.PodScheduled)
+		if attachment := volumeAttachment(attachments.Items, pvc.Spec.VolumeName, pod.Spec.NodeName); attachment != nil {
+			if attached := attachedTime(podEvents, pvc.Spec.VolumeName); !attached.IsZero() {
+				operations = append(operations, storageOperation{
+					name:         storageOperationAttach,
+					volumeName:   claim.volumeName,
+					storageClass: claim.csi.StorageClass,
+					duration:     max(attached.Sub(attachment.CreationTimestamp.Time), 0),
+				})
+				mountStart = attached
+			}
+		}
+		if mountEnd := conditionTime(pod, corev1.PodReadyToStartContainers); !mountStart.IsZero() && !mountEnd.IsZero() {
+			operation := storageOperation{
+				name:         storageOperationMount,
+				volumeName:   claim.volumeName,
+				storageClass: claim.csi.StorageClass,
+				duration:     max(mountEnd.Sub(mountStart), 0),
+			}
+			if failures, last := mountFailures(podEvents, pvc.Spec.VolumeName, claim.volumeName); failures > 0 {
+				operation.detail = fmt.Sprintf("%d %s events, last: %s", failures, eventReasonFailedMount, last)
+			}
+			operations = append(operations, operation)
+		}
+	}
+	return operations, nil
+}
+
+// stalledStorageError returns a *storageOperationError for the first volume of the pod whose PVC is not provisioned, that is not attached or
+// that failed to mount, or nil if none is. It is called after the checker timed out waiting for the pod, so it gets the objects with its own
+// timeout.
+func (c *PodStartupChecker) stalledStorageError(ctx context.Context, pod *corev1.Pod) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), storageDiagnosisTimeout)
+	defer cancel()
+
+	if current, err := c.k8sClientset.CoreV1().Pods(c.config.SyntheticPodNamespace).Get(ctx, pod.Name, metav1.GetOptions{}); err == nil {
+		pod = current
+	}
+	podEvents, err := c.listEvents(ctx, "pod", pod.Name)
+	if err != nil {
+		return nil
+	}
+	attachments, err := c.k8sClientset.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil
+	}
+
+	for _, claim := range c.csiClaims(pod) {
+		pvc, err := c.k8sClientset.CoreV1().PersistentVolumeClaims(c.config.SyntheticPodNamespace).Get(ctx, claim.claimName, metav1.GetOptions{})
+		if err != nil {
+			continue
+		}
+		if pvc.Status.Phase != corev1.ClaimBound {
+			pvcEvents, err := c.listEvents(ctx, "PVC", pvc.Name)
+			if err != nil {
+				continue
+			}
+			last := latestEvent(pvcEvents)
+			// A PVC waiting for its first consumer is not provisioned until the pod is scheduled, which the startup phases report.
+			if last != nil && last.Reason == eventReasonWaitForFirstConsumer {
+				continue
+			}
+			message := fmt.Sprintf("PVC %s is %s", pvc.Name, pvc.Status.Phase)
+			if last != nil {
+				message = fmt.Sprintf("%s, last event %s: %s", message, last.Reason, last.Message)
+			}
+			return &storageOperationError{operation: storageOperationProvisioning, storageClass: claim.csi.StorageClass, message: message}
+		}
+		if attachment := volumeAttachment(attachments.Items, pvc.Spec.VolumeName, pod.Spec.NodeName); attachment != nil && !attachment.Status.Attached {
+			message := fmt.Sprintf("volume %s is not attached to node %s", pvc.Spec.VolumeName, attachment.Spec.NodeName)
+			if attachErr := attachment.Status.AttachError; attachErr != nil {
+				message = fmt.Sprintf("%s: %s", message, attachErr.Message)
+			}
+			return &storageOperationError{operation: storageOperationAttach, storageClass: claim.csi.StorageClass, message: message}
+		}
+		if failures, last := mountFailures(podEvents, pvc.Spec.VolumeName, claim.volumeName); failures > 0 {
+			return &storageOperationError{
+				operation:    storageOperationMount,
+				storageClass: claim.csi.StorageClass,
+				message:      fmt.Sprintf("%d %s events, last: %s", failures, eventReasonFailedMount, last),
+			}
+		}
+	}
+	return nil
+}
+
+// slowStorageOperation returns the first storage operation that took at least the threshold of its storage probe and the threshold, or
+// false if none did.
+func (c *PodStartupChecker) slowStorageOperation(operations []storageOperation) (storageOperation, time.Duration, bool) {
+	for _, operation := range operations {
+		for i, csi := range c.config.EnabledCSIs {
+			if csiVolumeName(i) != operation.volumeName {
+				continue
+			}
+			threshold := storageOperationThreshold(csi, operation.name)
+			if threshold > 0 && operation.duration >= threshold {
+				return operation, threshold, true
+			}
+		}
+	}
+	return storageOperation{}, 0, false
+}
+
+// storageOperationThreshold returns the threshold of the storage probe for the operation, or 0 if it is not checked.
+func storageOperationThreshold(csi config.CSIConfig, operation string) time.Duration {
+	switch operation {
+	case storageOperationProvisioning:
+		return csi.ProvisioningThreshold
+	case storageOperationAttach:
+		return csi.AttachThreshold
+	case storageOperationMount:
+		return csi.MountThreshold
+	}
+	return 0
+}
+
+// listEvents returns the events of the object with the given name in SyntheticPodNamespace. The names of the synthetic pods and their PVCs
+// do not collide, so the kind is only used in the error.
+func (c *PodStartupChecker) listEvents(ctx context.Context, kind, name string) ([]corev1.Event, error) {
+	events, err := c.k8sClientset.CoreV1().Events(c.config.SyntheticPodNamespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.name=%s", name),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events for %s %s: %w", kind, name, err)
+	}
+	var matching []corev1.Event
+	for _, event := range events.Items {
+		if event.InvolvedObject.Name == name {
+			matching = append(matching, event)
+		}
+	}
+	return matching, nil
+}
+
+// provisioningDuration returns the duration of provisioning the PVC from its events, or false if the PVC was not provisioned.
+func provisioningDuration(pvc *corev1.PersistentVolumeClaim, events []corev1.Event) (time.Duration, bool) {
+	start := pvc.CreationTimestamp.Time
+	var end time.Time
+	startedByEvent := false
+	for _, event := range events {
+		switch event.Reason {
+		case eventReasonProvisioning:
+			if t := eventFirstTime(&event); !startedByEvent || t.Before(start) {
+				start = t
+				startedByEvent = true
+			}
+		case eventReasonProvisioningSucceeded:
+			end = eventTime(&event)
+		}
+	}
+	if end.IsZero() {
+		return 0, false
+	}
+	return max(end.Sub(start), 0), true
+}
+
+// volumeAttachment returns the VolumeAttachment of the persistent volume on the node, or on any node if the pod is not scheduled, or nil if
+// the volume has none.
+func volumeAttachment(attachments []storagev1.VolumeAttachment, volumeName, nodeName string) *storagev1.VolumeAttachment {
+	if volumeName == "" {
+		return nil
+	}
+	for i := range attachments {
+		source := attachments[i].Spec.Source.PersistentVolumeName
+		if source != nil && *source == volumeName && (nodeName == "" || attachments[i].Spec.NodeName == nodeName) {
+			return &attachments[i]
+		}
+	}
+	return nil
+}
+
+// attachedTime returns the time of the SuccessfulAttachVolume event of the persistent volume, or the zero time if there is none. The event
+// message is expected to be in a format like: "AttachVolume.Attach succeeded for volume \"pvc-0d5b\"".
+func attachedTime(podEvents []corev1.Event, volumeName string) time.Time {
+	for _, event := range podEvents {
+		if event.Reason == eventReasonSuccessfulAttachVolume && strings.Contains(event.Message, fmt.Sprintf("%q", volumeName)) {
+			return eventTime(&event)
+		}
+	}
+	return time.Time{}
+}
+
+// mountFailures returns the number of FailedMount events of the pod that mention the persistent volume or the pod volume, and the message
+// of the last one.
+func mountFailures(podEvents []corev1.Event, volumeName, podVolumeName string) (int32, string) {
+	podVolume := regexp.MustCompile(`\b` + regexp.QuoteMeta(podVolumeName) + `\b`)
+	var failures int32
+	var last *corev1.Event
+	for i, event := range podEvents {
+		if event.Reason != eventReasonFailedMount {
+			continue
+		}
+		if !(volumeName != "" && strings.Contains(event.Message, fmt.Sprintf("%q", volumeName))) && !podVolume.MatchString(event.Message) {
+			continue
+		}
+		failures += max(event.Count, 1)
+		if last == nil || eventTime(&event).After(eventTime(last)) {
+			last = &podEvents[i]
+		}
+	}
+	if last == nil {
+		return 0, ""
+	}
+	return failures, last.Message
+}
+
+// latestEvent returns the most recently observed of the events, or nil if there are none.
+func latestEvent(events []corev1.Event) *corev1.Event {
+	var latest *corev1.Event
+	for i := range events {
+		if latest == nil || eventTime(&events[i]).After(eventTime(latest)) {
+			latest = &events[i]
+		}
+	}
+	return latest
+}
+
+// eventFirstTime returns the time at which the event was first observed.
+func eventFirstTime(event *corev1.Event) time.Time {
+	if !event.FirstTimestamp.IsZero() {
+		return event.FirstTimestamp.Time
+	}
+	return eventTime(event)
+}
@@ -0,0 +1,267 @@
+package podstartup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/cluster-health-monitor/pkg/config"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	storageTestNamespace = "test-namespace"
+	storageTestPodName   = "pod1"
+	storageTestPVCName   = "clusterhealthmonitor-csi-pvc-1-0"
+	storageTestPVName    = "pvc-0d5b"
+	storageTestNodeName  = "node1"
+)
+
+func storageTestPod(conditions ...corev1.PodCondition) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: storageTestPodName, Namespace: storageTestNamespace},
+		Spec: corev1.PodSpec{
+			NodeName: storageTestNodeName,
+			Volumes: []corev1.Volume{{
+				Name: csiVolumeName(0),
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: storageTestPVCName},
+				},
+			}},
+		},
+		Status: corev1.PodStatus{Conditions: conditions},
+	}
+}
+
+func storageTestPVC(created time.Time, phase corev1.PersistentVolumeClaimPhase, volumeName string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: storageTestPVCName, Namespace: storageTestNamespace, CreationTimestamp: metav1.NewTime(created)},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: volumeName},
+		Status:     corev1.PersistentVolumeClaimStatus{Phase: phase},
+	}
+}
+
+func storageTestAttachment(created time.Time, attached bool, attachErr string) *storagev1.VolumeAttachment {
+	pvName := storageTestPVName
+	attachment := &storagev1.VolumeAttachment{
+		ObjectMeta: metav1.ObjectMeta{Name: "csi-0d5b", CreationTimestamp: metav1.NewTime(created)},
+		Spec: storagev1.VolumeAttachmentSpec{
+			Attacher: "disk.csi.azure.com",
+			NodeName: storageTestNodeName,
+			Source:   storagev1.VolumeAttachmentSource{PersistentVolumeName: &pvName},
+		},
+		Status: storagev1.VolumeAttachmentStatus{Attached: attached},
+	}
+	if attachErr != "" {
+		attachment.Status.AttachError = &storagev1.VolumeError{Message: attachErr}
+	}
+	return attachment
+}
+
+func TestPodStartupChecker_getStorageOperations(t *testing.T) {
+	created := time.Now()
+	attachedMessage := `AttachVolume.Attach succeeded for volume "pvc-0d5b"`
+
+	tests := []struct {
+		name               string
+		pod                *corev1.Pod
+		objects            []runtime.Object
+		expectedOperations []storageOperation
+		expectedErr        string
+	}{
+		{
+			name: "provisioned, attached and mounted volume",
+			pod: storageTestPod(
+				podCondition(corev1.PodScheduled, created.Add(1*time.Second)),
+				podCondition(corev1.PodReadyToStartContainers, created.Add(12*time.Second)),
+			),
+			objects: []runtime.Object{
+				storageTestPVC(created, corev1.ClaimBound, storageTestPVName),
+				storageTestAttachment(created.Add(5*time.Second), true, ""),
+				podEvent(storageTestNamespace, storageTestPVCName, "provisioning", "Provisioning", "External provisioner is provisioning volume", 1, created.Add(1*time.Second)),
+				podEvent(storageTestNamespace, storageTestPVCName, "provisioned", "ProvisioningSucceeded", "Successfully provisioned volume pvc-0d5b", 1, created.Add(4*time.Second)),
+				podEvent(storageTestNamespace, storageTestPodName, "attached", "SuccessfulAttachVolume", attachedMessage, 1, created.Add(8*time.Second)),
+				podEvent(storageTestNamespace, storageTestPodName, "mount", "FailedMount", "MountVolume.MountDevice failed for volume \"pvc-0d5b\": timed out", 2, created.Add(10*time.Second)),
+				podEvent(storageTestNamespace, storageTestPodName, "other", "FailedMount", "MountVolume.SetUp failed for volume \"kube-api-access\"", 1, created.Add(11*time.Second)),
+			},
+			expectedOperations: []storageOperation{
+				{name: storageOperationProvisioning, volumeName: "csi-volume-0", storageClass: "managed-csi", duration: 3 * time.Second},
+				{name: storageOperationAttach, volumeName: "csi-volume-0", storageClass: "managed-csi", duration: 3 * time.Second},
+				{
+					name:         storageOperationMount,
+					volumeName:   "csi-volume-0",
+					storageClass: "managed-csi",
+					duration:     4 * time.Second,
+					detail:       "2 FailedMount events, last: MountVolume.MountDevice failed for volume \"pvc-0d5b\": timed out",
+				},
+			},
+		},
+		{
+			name: "statically provisioned volume that is not attached",
+			pod: storageTestPod(
+				podCondition(corev1.PodScheduled, created.Add(1*time.Second)),
+				podCondition(corev1.PodReadyToStartContainers, created.Add(3*time.Second)),
+			),
+			objects: []runtime.Object{
+				storageTestPVC(created, corev1.ClaimBound, storageTestPVName),
+			},
+			expectedOperations: []storageOperation{
+				{name: storageOperationMount, volumeName: "csi-volume-0", storageClass: "managed-csi", duration: 2 * time.Second},
+			},
+		},
+		{
+			name: "pod without csi volumes",
+			pod:  &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: storageTestPodName, Namespace: storageTestNamespace}},
+		},
+		{
+			name:        "PVC not found",
+			pod:         storageTestPod(),
+			expectedErr: "failed to get PVC clusterhealthmonitor-csi-pvc-1-0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			checker := &PodStartupChecker{
+				k8sClientset: k8sfake.NewClientset(tt.objects...),
+				config: &config.PodStartupConfig{
+					SyntheticPodNamespace: storageTestNamespace,
+					EnabledCSIs:           []config.CSIConfig{{StorageClass: "managed-csi"}},
+				},
+			}
+
+			operations, err := checker.getStorageOperations(context.Background(), tt.pod)
+			if tt.expectedErr != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tt.expectedErr))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(operations).To(Equal(tt.expectedOperations))
+		})
+	}
+}
+
+func TestPodStartupChecker_stalledStorageError(t *testing.T) {
+	created := time.Now()
+
+	tests := []struct {
+		name         string
+		objects      []runtime.Object
+		expectedCode string
+		expectedErr  string
+	}{
+		{
+			name: "PVC not provisioned",
+			objects: []runtime.Object{
+				storageTestPVC(created, corev1.ClaimPending, ""),
+				podEvent(storageTestNamespace, storageTestPVCName, "failed", "ProvisioningFailed", "quota exceeded", 3, created.Add(2*time.Second)),
+			},
+			expectedCode: ErrCodePVCProvisioningTimeout,
+			expectedErr:  "provisioning of the managed-csi volume did not complete: PVC clusterhealthmonitor-csi-pvc-1-0 is Pending, last event ProvisioningFailed: quota exceeded",
+		},
+		{
+			name: "PVC waiting for the pod to be scheduled",
+			objects: []runtime.Object{
+				storageTestPVC(created, corev1.ClaimPending, ""),
+				podEvent(storageTestNamespace, storageTestPVCName, "waiting", "WaitForFirstConsumer", "waiting for first consumer to be created before binding", 1, created.Add(1*time.Second)),
+			},
+		},
+		{
+			name: "volume not attached",
+			objects: []runtime.Object{
+				storageTestPVC(created, corev1.ClaimBound, storageTestPVName),
+				storageTestAttachment(created.Add(5*time.Second), false, "rpc error: attach timed out"),
+			},
+			expectedCode: ErrCodeVolumeAttachTimeout,
+			expectedErr:  "attach of the managed-csi volume did not complete: volume pvc-0d5b is not attached to node node1: rpc error: attach timed out",
+		},
+		{
+			name: "volume failed to mount",
+			objects: []runtime.Object{
+				storageTestPVC(created, corev1.ClaimBound, storageTestPVName),
+				storageTestAttachment(created.Add(5*time.Second), true, ""),
+				podEvent(storageTestNamespace, storageTestPodName, "mount", "FailedMount", "Unable to attach or mount volumes: unmounted volumes=[csi-volume-0]", 4, created.Add(10*time.Second)),
+			},
+			expectedCode: ErrCodeVolumeMountTimeout,
+			expectedErr:  "mount of the managed-csi volume did not complete: 4 FailedMount events, last: Unable to attach or mount volumes: unmounted volumes=[csi-volume-0]",
+		},
+		{
+			name: "volume ready",
+			objects: []runtime.Object{
+				storageTestPVC(created, corev1.ClaimBound, storageTestPVName),
+				storageTestAttachment(created.Add(5*time.Second), true, ""),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			checker := &PodStartupChecker{
+				k8sClientset: k8sfake.NewClientset(tt.objects...),
+				config: &config.PodStartupConfig{
+					SyntheticPodNamespace: storageTestNamespace,
+					EnabledCSIs:           []config.CSIConfig{{StorageClass: "managed-csi"}},
+				},
+			}
+
+			// The context of the checker run has already expired when the volumes are diagnosed.
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			err := checker.stalledStorageError(ctx, storageTestPod())
+			if tt.expectedCode == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			var storageErr *storageOperationError
+			g.Expect(errors.As(err, &storageErr)).To(BeTrue())
+			g.Expect(storageErr.code()).To(Equal(tt.expectedCode))
+			g.Expect(err).To(MatchError(tt.expectedErr))
+		})
+	}
+}
+
+func TestPodStartupChecker_slowStorageOperation(t *testing.T) {
+	g := NewWithT(t)
+	operations := []storageOperation{
+		{name: storageOperationProvisioning, volumeName: "csi-volume-0", storageClass: "azurefile-csi", duration: 20 * time.Second},
+		{name: storageOperationProvisioning, volumeName: "csi-volume-1", storageClass: "managed-csi", duration: 5 * time.Second},
+		{name: storageOperationAttach, volumeName: "csi-volume-1", storageClass: "managed-csi", duration: 15 * time.Second},
+		{name: storageOperationProvisioning, volumeName: "csi-volume-2", storageClass: "managed-csi", duration: 15 * time.Second},
+	}
+	chk := &PodStartupChecker{config: &config.PodStartupConfig{EnabledCSIs: []config.CSIConfig{
+		{StorageClass: "azurefile-csi"},
+		{StorageClass: "managed-csi", ProvisioningThreshold: 10 * time.Second},
+		{StorageClass: "managed-csi", ProvisioningThreshold: 20 * time.Second},
+	}}}
+
+	// Without a threshold for the operation, it is never slow. The threshold of a probe does not apply to another probe of the same
+	// storage class.
+	_, _, ok := chk.slowStorageOperation(operations)
+	g.Expect(ok).To(BeFalse())
+
+	chk.config.EnabledCSIs[1].AttachThreshold = 10 * time.Second
+	operation, threshold, ok := chk.slowStorageOperation(operations)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(operation.name).To(Equal(storageOperationAttach))
+	g.Expect(operation.volumeName).To(Equal("csi-volume-1"))
+	g.Expect(threshold).To(Equal(10 * time.Second))
+
+	chk.config.EnabledCSIs[1].AttachThreshold = 0
+	chk.config.EnabledCSIs[2].ProvisioningThreshold = 10 * time.Second
+	operation, threshold, ok = chk.slowStorageOperation(operations)
+	g.Expect(ok).To(BeTrue())
+	g.Expect(operation.name).To(Equal(storageOperationProvisioning))
+	g.Expect(operation.volumeName).To(Equal("csi-volume-2"))
+	g.Expect(threshold).To(Equal(10 * time.Second))
+}
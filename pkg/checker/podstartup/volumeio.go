@@ -0,0 +1,217 @@
+package podstartup
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// volumeIOContainerName is the name of the init container that writes to and reads back from the volumes of the synthetic pod.
+	volumeIOContainerName = "volume-io"
+
+	// Operations of the volume I/O recorded in the volume I/O duration histogram.
+	volumeIOOperationWrite = "write"
+	volumeIOOperationRead  = "read"
+
+	// Statuses of a volume in the termination message of the volume I/O container.
+	volumeIOStatusOK          = "ok"
+	volumeIOStatusWriteFailed = "write-failed"
+	volumeIOStatusReadFailed  = "read-failed"
+)
+
+// volumeIOScript writes FILE_SIZE random bytes to each of the VOLUMES, given as space-separated <storage class>=<path> pairs, fsyncs
+// them, reads them back and compares their checksum. The bytes are written to a file in the directory at the path, or to the start of the
+// device at the path of a Block volume. It reports one line per volume in RESULT_FILE, the termination message of the container:
+// "<storage class> ok <write nanoseconds> <read nanoseconds>", or "<storage class> write-failed|read-failed <message>" for the volume
+// that failed, after which it exits with an error.
+const volumeIOScript = `set -u
+: > "$RESULT_FILE"
+fail() {
+  echo "$1 $2 $3" >> "$RESULT_FILE"
+  exit 1
+}
+head -c "$FILE_SIZE" /dev/urandom > /tmp/volume-io-data || fail - write-failed "cannot generate test data"
+expected=$(cksum < /tmp/volume-io-data)
+for volume in $VOLUMES; do
+  name=${volume%%=*}
+  target=${volume#*=}
+  [ -b "$target" ] || target=$target/cluster-health-monitor-volume-io
+  start=$(date +%s%N)
+  dd if=/tmp/volume-io-data of="$target" bs=1M conv=fsync status=none 2> /tmp/volume-io-error || fail "$name" write-failed "$(tail -n 1 /tmp/volume-io-error)"
+  written=$(date +%s%N)
+  actual=$(dd if="$target" bs=1M count="$FILE_SIZE" iflag=count_bytes status=none 2> /tmp/volume-io-error | cksum)
+  read=$(date +%s%N)
+  [ -s /tmp/volume-io-error ] && fail "$name" read-failed "$(tail -n 1 /tmp/volume-io-error)"
+  [ "$actual" = "$expected" ] || fail "$name" read-failed "data read back does not match the data written"
+  [ -b "$target" ] || rm -f "$target"
+  echo "$name ok $((written - start)) $((read - written))" >> "$RESULT_FILE"
+done
+`
+
+// volumeIOResult is the duration of writing and reading back the data of the volume of a storage class.
+type volumeIOResult struct {
+	storageClass string
+	write        time.Duration
+	read         time.Duration
+}
+
+// volumeIOContainer returns the init container that verifies the I/O of the volumes of EnabledCSIs, attached with volumeMounts and
+// volumeDevices.
+func (c *PodStartupChecker) volumeIOContainer(volumeMounts []corev1.VolumeMount, volumeDevices []corev1.VolumeDevice) corev1.Container {
+	var volumes []string
+	for _, csi := range c.config.EnabledCSIs {
+		volumes = append(volumes, fmt.Sprintf("%s=%s", csi.StorageClass, csi.VolumePath()))
+	}
+	return corev1.Container{
+		Name:            volumeIOContainerName,
+		Image:           c.config.SyntheticPodImage(),
+		ImagePullPolicy: corev1.PullPolicy(c.config.ImagePullPolicy),
+		Command:         []string{"/bin/sh", "-c", volumeIOScript},
+		Env: []corev1.EnvVar{
+			{Name: "VOLUMES", Value: strings.Join(volumes, " ")},
+			{Name: "FILE_SIZE", Value: strconv.FormatInt(c.config.VolumeIO.FileSizeBytes(), 10)},
+			{Name: "RESULT_FILE", Value: corev1.TerminationMessagePathDefault},
+		},
+		VolumeMounts:  volumeMounts,
+		VolumeDevices: volumeDevices,
+	}
+}
+
+// volumeIOError is returned when the volume I/O of the synthetic pod failed or did not complete.
+type volumeIOError struct {
+	code         string
+	storageClass string
+	message      string
+}
+
+func (e *volumeIOError) Error() string {
+	if e.storageClass == "" {
+		return fmt.Sprintf("volume I/O failed: %s", e.message)
+	}
+	return fmt.Sprintf("volume I/O failed on %s volume: %s", e.storageClass, e.message)
+}
+
+// volumeIOContainerStatus returns the status of the volume I/O container of the pod, or nil if the pod does not have one.
+func volumeIOContainerStatus(pod *corev1.Pod) *corev1.ContainerStatus {
+	for i := range pod.Status.InitContainerStatuses {
+		if pod.Status.InitContainerStatuses[i].Name == volumeIOContainerName {
+			return &pod.Status.InitContainerStatuses[i]
+		}
+	}
+	return nil
+}
+
+// volumeIOFailure returns a *volumeIOError if the volume I/O container of the pod failed, i.e. it terminated with an error, or nil
+// otherwise. The container is restarted after failing, so its previous termination is checked too.
+func volumeIOFailure(pod *corev1.Pod) error {
+	status := volumeIOContainerStatus(pod)
+	if status == nil {
+		return nil
+	}
+	terminated := status.State.Terminated
+	if terminated == nil || terminated.ExitCode == 0 {
+		terminated = status.LastTerminationState.Terminated
+	}
+	if terminated == nil || terminated.ExitCode == 0 {
+		return nil
+	}
+	_, err := parseVolumeIOResults(terminated.Message)
+	if err == nil {
+		err = &volumeIOError{code: ErrCodeVolumeWriteFailed, message: fmt.Sprintf("container %s exited with code %d: %s",
+			volumeIOContainerName, terminated.ExitCode, terminated.Reason)}
+	}
+	return err
+}
+
+// volumeIOStalledError returns a *volumeIOError if the volume I/O container of the pod is still running, e.g. because the I/O hangs, or
+// nil otherwise.
+func volumeIOStalledError(pod *corev1.Pod) error {
+	status := volumeIOContainerStatus(pod)
+	if status == nil || status.State.Running == nil {
+		return nil
+	}
+	return &volumeIOError{code: ErrCodeVolumeIOSlow, message: "writing to and reading back from the volumes did not complete"}
+}
+
+// parseVolumeIOResults parses the termination message of the volume I/O container. It returns a *volumeIOError for the volume that
+// failed, if any.
+func parseVolumeIOResults(message string) ([]volumeIOResult, error) {
+	var results []volumeIOResult
+	for _, line := range strings.Split(strings.TrimSpace(message), "\n") {
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[1] {
+		case volumeIOStatusOK:
+			durations := strings.Fields(strings.Join(fields[2:], " "))
+			if len(durations) != 2 {
+				return nil, fmt.Errorf("unexpected volume I/O result: %s", line)
+			}
+			write, writeErr := strconv.ParseInt(durations[0], 10, 64)
+			read, readErr := strconv.ParseInt(durations[1], 10, 64)
+			if writeErr != nil || readErr != nil {
+				return nil, fmt.Errorf("unexpected volume I/O result: %s", line)
+			}
+			results = append(results, volumeIOResult{storageClass: fields[0], write: time.Duration(write), read: time.Duration(read)})
+		case volumeIOStatusWriteFailed, volumeIOStatusReadFailed:
+			ioErr := &volumeIOError{code: ErrCodeVolumeWriteFailed, message: strings.Join(fields[2:], " ")}
+			if fields[1] == volumeIOStatusReadFailed {
+				ioErr.code = ErrCodeVolumeReadFailed
+			}
+			if fields[0] != "-" {
+				ioErr.storageClass = fields[0]
+			}
+			return nil, ioErr
+		}
+	}
+	return results, nil
+}
+
+// getVolumeIOResults returns the volume I/O results of the synthetic pod whose volume I/O container completed.
+func (c *PodStartupChecker) getVolumeIOResults(ctx context.Context, podName string) ([]volumeIOResult, error) {
+	pod, err := c.k8sClientset.CoreV1().Pods(c.config.SyntheticPodNamespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error getting pod %s: %w", podName, err)
+	}
+	status := volumeIOContainerStatus(pod)
+	if status == nil || status.State.Terminated == nil {
+		return nil, fmt.Errorf("container %s of pod %s has not completed", volumeIOContainerName, podName)
+	}
+	return parseVolumeIOResults(status.State.Terminated.Message)
+}
+
+// slowVolumeIO returns an unhealthy result message if writing or reading back the data of a volume took at least the threshold, or an
+// empty string otherwise.
+func (c *PodStartupChecker) slowVolumeIO(results []volumeIOResult) string {
+	threshold := c.config.VolumeIO.Threshold
+	if threshold == 0 {
+		return ""
+	}
+	size := resource.NewQuantity(c.config.VolumeIO.FileSizeBytes(), resource.BinarySI)
+	for _, result := range results {
+		if result.write >= threshold {
+			return fmt.Sprintf("writing %s to the %s volume took %s, exceeding the threshold of %s", size, result.storageClass, result.write, threshold)
+		}
+		if result.read >= threshold {
+			return fmt.Sprintf("reading %s back from the %s volume took %s, exceeding the threshold of %s", size, result.storageClass, result.read, threshold)
+		}
+	}
+	return ""
+}
+
+// volumeIODuration returns the total duration of the volume I/O.
+func volumeIODuration(results []volumeIOResult) time.Duration {
+	var total time.Duration
+	for _, result := range results {
+		total += result.write + result.read
+	}
+	return total
+}
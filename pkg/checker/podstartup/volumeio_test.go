@@ -0,0 +1,209 @@
+package podstartup
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Azure/cluster-health-monitor/pkg/config"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestVolumeIOScript(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	tests := []struct {
+		name string
+		// volumes returns the VOLUMES of the script, given a temporary directory.
+		volumes     func(dir string) string
+		validateRes func(g *WithT, results []volumeIOResult, err error, scriptErr error)
+	}{
+		{
+			name: "all volumes written and read back",
+			volumes: func(dir string) string {
+				return "azurefile-csi=" + dir + " managed-csi=" + dir
+			},
+			validateRes: func(g *WithT, results []volumeIOResult, err error, scriptErr error) {
+				g.Expect(scriptErr).ToNot(HaveOccurred())
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(results).To(HaveLen(2))
+				g.Expect(results[0].storageClass).To(Equal("azurefile-csi"))
+				g.Expect(results[1].storageClass).To(Equal("managed-csi"))
+				for _, result := range results {
+					g.Expect(result.write).To(BeNumerically(">", 0))
+					g.Expect(result.read).To(BeNumerically(">", 0))
+				}
+			},
+		},
+		{
+			name: "volume cannot be written",
+			volumes: func(dir string) string {
+				return "azurefile-csi=" + dir + " blob-fuse=" + filepath.Join(dir, "missing")
+			},
+			validateRes: func(g *WithT, results []volumeIOResult, err error, scriptErr error) {
+				g.Expect(scriptErr).To(HaveOccurred())
+				var ioErr *volumeIOError
+				g.Expect(errors.As(err, &ioErr)).To(BeTrue())
+				g.Expect(ioErr.code).To(Equal(ErrCodeVolumeWriteFailed))
+				g.Expect(ioErr.storageClass).To(Equal("blob-fuse"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			dir := t.TempDir()
+			resultFile := filepath.Join(dir, "result")
+
+			cmd := exec.Command("sh", "-c", volumeIOScript)
+			cmd.Env = append(os.Environ(), "VOLUMES="+tt.volumes(dir), "FILE_SIZE=65536", "RESULT_FILE="+resultFile)
+			scriptErr := cmd.Run()
+
+			message, err := os.ReadFile(resultFile)
+			g.Expect(err).ToNot(HaveOccurred())
+			results, err := parseVolumeIOResults(string(message))
+			tt.validateRes(g, results, err, scriptErr)
+		})
+	}
+}
+
+func TestParseVolumeIOResults(t *testing.T) {
+	tests := []struct {
+		name            string
+		message         string
+		expectedResults []volumeIOResult
+		expectedCode    string
+		expectedErr     string
+	}{
+		{
+			name:    "all volumes ok",
+			message: "azurefile-csi ok 2000000 1000000\nmanaged-csi ok 3000000 500000\n",
+			expectedResults: []volumeIOResult{
+				{storageClass: "azurefile-csi", write: 2 * time.Millisecond, read: 1 * time.Millisecond},
+				{storageClass: "managed-csi", write: 3 * time.Millisecond, read: 500 * time.Microsecond},
+			},
+		},
+		{
+			name:         "read failed",
+			message:      "azurefile-csi ok 2000000 1000000\nmanaged-csi read-failed data read back does not match the data written",
+			expectedCode: ErrCodeVolumeReadFailed,
+			expectedErr:  "volume I/O failed on managed-csi volume: data read back does not match the data written",
+		},
+		{
+			name:         "test data not generated",
+			message:      "- write-failed cannot generate test data",
+			expectedCode: ErrCodeVolumeWriteFailed,
+			expectedErr:  "volume I/O failed: cannot generate test data",
+		},
+		{
+			name:        "malformed durations",
+			message:     "azurefile-csi ok 2000000",
+			expectedErr: "unexpected volume I/O result: azurefile-csi ok 2000000",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			results, err := parseVolumeIOResults(tt.message)
+			if tt.expectedErr == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(results).To(Equal(tt.expectedResults))
+				return
+			}
+			g.Expect(err).To(MatchError(tt.expectedErr))
+			var ioErr *volumeIOError
+			g.Expect(errors.As(err, &ioErr)).To(Equal(tt.expectedCode != ""))
+			if ioErr != nil {
+				g.Expect(ioErr.code).To(Equal(tt.expectedCode))
+			}
+		})
+	}
+}
+
+func TestVolumeIOFailure(t *testing.T) {
+	podWithStatus := func(status corev1.ContainerStatus) *corev1.Pod {
+		status.Name = volumeIOContainerName
+		return &corev1.Pod{Status: corev1.PodStatus{InitContainerStatuses: []corev1.ContainerStatus{status}}}
+	}
+	terminated := func(exitCode int32, message string) corev1.ContainerState {
+		return corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: exitCode, Reason: "Error", Message: message}}
+	}
+
+	tests := []struct {
+		name         string
+		pod          *corev1.Pod
+		expectedCode string
+	}{
+		{
+			name: "no volume I/O container",
+			pod:  &corev1.Pod{},
+		},
+		{
+			name: "completed",
+			pod:  podWithStatus(corev1.ContainerStatus{State: terminated(0, "azurefile-csi ok 1 1")}),
+		},
+		{
+			name:         "failed",
+			pod:          podWithStatus(corev1.ContainerStatus{State: terminated(1, "azurefile-csi read-failed short read")}),
+			expectedCode: ErrCodeVolumeReadFailed,
+		},
+		{
+			name: "restarted after failing",
+			pod: podWithStatus(corev1.ContainerStatus{
+				State:                corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				LastTerminationState: terminated(1, "managed-csi write-failed No space left on device"),
+			}),
+			expectedCode: ErrCodeVolumeWriteFailed,
+		},
+		{
+			name:         "failed without a termination message",
+			pod:          podWithStatus(corev1.ContainerStatus{State: terminated(137, "")}),
+			expectedCode: ErrCodeVolumeWriteFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := volumeIOFailure(tt.pod)
+			if tt.expectedCode == "" {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			var ioErr *volumeIOError
+			g.Expect(errors.As(err, &ioErr)).To(BeTrue())
+			g.Expect(ioErr.code).To(Equal(tt.expectedCode))
+		})
+	}
+
+	g := NewWithT(t)
+	g.Expect(volumeIOStalledError(&corev1.Pod{})).ToNot(HaveOccurred())
+	stalled := volumeIOStalledError(podWithStatus(corev1.ContainerStatus{State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}}))
+	var ioErr *volumeIOError
+	g.Expect(errors.As(stalled, &ioErr)).To(BeTrue())
+	g.Expect(ioErr.code).To(Equal(ErrCodeVolumeIOSlow))
+}
+
+func TestPodStartupChecker_slowVolumeIO(t *testing.T) {
+	g := NewWithT(t)
+	results := []volumeIOResult{
+		{storageClass: "azurefile-csi", write: 500 * time.Millisecond, read: 100 * time.Millisecond},
+		{storageClass: "managed-csi", write: 200 * time.Millisecond, read: 2 * time.Second},
+	}
+	chk := &PodStartupChecker{config: &config.PodStartupConfig{VolumeIO: &config.VolumeIOConfig{}}}
+
+	// Without a threshold, the volume I/O is never slow.
+	g.Expect(chk.slowVolumeIO(results)).To(BeEmpty())
+
+	chk.config.VolumeIO.Threshold = 1 * time.Second
+	g.Expect(chk.slowVolumeIO(results)).To(Equal("reading 1Mi back from the managed-csi volume took 2s, exceeding the threshold of 1s"))
+	g.Expect(volumeIODuration(results)).To(Equal(2800 * time.Millisecond))
+}
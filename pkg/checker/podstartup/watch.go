@@ -0,0 +1,199 @@
+package podstartup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/klog/v2"
+)
+
+// podStartupTracker records the startup transitions of a synthetic pod and its events as they arrive from a watch.
+type podStartupTracker struct {
+	// created is the local time right before the pod was created.
+	created time.Time
+	// transitions holds the local time at which each startup transition of the pod was first observed.
+	transitions map[corev1.PodConditionType]time.Time
+	// running is the local time at which the container was first observed running.
+	running time.Time
+	// missedTransitions is true if a transition happened while the pod was not watched, e.g. before the watch was established.
+	missedTransitions bool
+	// events holds the latest version of each event of the pod, keyed by event name.
+	events map[string]corev1.Event
+	// eventArrivals holds the local time at which each event was first observed, keyed by event name.
+	eventArrivals map[string]time.Time
+	// eventsWatched is true while the events of the pod are watched without interruption.
+	eventsWatched bool
+	// lastPod is the latest observed state of the pod.
+	lastPod *corev1.Pod
+}
+
+// trackedConditions are the pod conditions that mark the boundaries of the startup phases.
+var trackedConditions = []corev1.PodConditionType{corev1.PodScheduled, corev1.PodReadyToStartContainers, corev1.PodInitialized}
+
+func newPodStartupTracker(created time.Time) *podStartupTracker {
+	return &podStartupTracker{
+		created:       created,
+		transitions:   make(map[corev1.PodConditionType]time.Time),
+		events:        make(map[string]corev1.Event),
+		eventArrivals: make(map[string]time.Time),
+	}
+}
+
+// observePod records the transitions of the pod that were not observed before. If the pod was not received from the watch, the time of the
+// transitions is unknown and they are recorded as missed.
+func (t *podStartupTracker) observePod(pod *corev1.Pod, at time.Time, fromWatch bool) {
+	t.lastPod = pod
+	for _, conditionType := range trackedConditions {
+		if _, ok := t.transitions[conditionType]; ok || conditionTime(pod, conditionType).IsZero() {
+			continue
+		}
+		if !fromWatch {
+			t.missedTransitions = true
+		}
+		t.transitions[conditionType] = at
+	}
+	if t.running.IsZero() && !containerRunningTime(pod).IsZero() {
+		if !fromWatch {
+			t.missedTransitions = true
+		}
+		t.running = at
+	}
+}
+
+// observeEvent records the latest version of the event and when it first arrived.
+func (t *podStartupTracker) observeEvent(event *corev1.Event, at time.Time) {
+	t.events[event.Name] = *event
+	if _, ok := t.eventArrivals[event.Name]; !ok {
+		t.eventArrivals[event.Name] = at
+	}
+}
+
+// watchedStartup returns true if the tracker observed every transition of the pod up to its container running, and its events.
+func (t *podStartupTracker) watchedStartup() bool {
+	return !t.running.IsZero() && !t.missedTransitions && t.eventsWatched
+}
+
+// creationToContainerRunningDuration returns the locally measured duration between the pod creation and the container running.
+func (t *podStartupTracker) creationToContainerRunningDuration() time.Duration {
+	return t.running.Sub(t.created)
+}
+
+// watchedEvents returns the watched events of the pod if they include its image pull event. The events are reported asynchronously, so
+// they are only used once the image pull event, which precedes the container start, arrived.
+func (t *podStartupTracker) watchedEvents() ([]corev1.Event, bool) {
+	if !t.eventsWatched {
+		return nil, false
+	}
+	events := make([]corev1.Event, 0, len(t.events))
+	pulled := false
+	for _, event := range t.events {
+		events = append(events, event)
+		pulled = pulled || event.Reason == eventReasonPulled
+	}
+	return events, pulled
+}
+
+// timeline returns the startup timeline from the local times at which the transitions and events arrived.
+func (t *podStartupTracker) timeline() startupTimeline {
+	timeline := startupTimeline{
+		created:      t.created,
+		scheduled:    t.transitions[corev1.PodScheduled],
+		sandboxReady: t.transitions[corev1.PodReadyToStartContainers],
+		initialized:  t.transitions[corev1.PodInitialized],
+		running:      t.running,
+		precision:    time.Millisecond,
+	}
+	for name, event := range t.events {
+		if event.Reason == eventReasonSuccessfulAttachVolume && t.eventArrivals[name].After(timeline.attached) {
+			timeline.attached = t.eventArrivals[name]
+		}
+		timeline.addSandboxFailure(&event)
+	}
+	return timeline
+}
+
+// waitForContainerRunning waits until the container of the created synthetic pod is running and returns the duration between the pod
+// creation and the container running. It watches the pod and its events and records their transitions in the tracker as they arrive. If the
+// pod watch cannot be established or breaks, it falls back to polling the pod. The errors are the same as those of
+// pollPodCreationToContainerRunningDuration.
+func (c *PodStartupChecker) waitForContainerRunning(ctx context.Context, pod *corev1.Pod, tracker *podStartupTracker) (time.Duration, error) {
+	pods := c.k8sClientset.CoreV1().Pods(c.config.SyntheticPodNamespace)
+	podWatch, err := pods.Watch(ctx, metav1.ListOptions{
+		FieldSelector:   fmt.Sprintf("metadata.name=%s", pod.Name),
+		ResourceVersion: pod.ResourceVersion,
+	})
+	if err != nil {
+		klog.ErrorS(err, "Failed to watch synthetic pod, falling back to polling", "name", pod.Name)
+		return c.pollPodCreationToContainerRunningDuration(ctx, pod.Name)
+	}
+	defer podWatch.Stop()
+
+	var eventCh <-chan watch.Event
+	eventWatch, err := c.k8sClientset.CoreV1().Events(c.config.SyntheticPodNamespace).Watch(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.name=%s", pod.Name),
+	})
+	if err != nil {
+		// Without the watched events, the image pull duration and startup phases are taken from listing the events afterwards.
+		klog.ErrorS(err, "Failed to watch synthetic pod events", "name", pod.Name)
+	} else {
+		defer eventWatch.Stop()
+		eventCh = eventWatch.ResultChan()
+		tracker.eventsWatched = true
+	}
+
+	// The pod may have changed before the watch was established, and the watch does not replay changes with the fake clientset.
+	if current, err := pods.Get(ctx, pod.Name, metav1.GetOptions{}); err == nil {
+		tracker.observePod(current, time.Now(), false)
+		if err := volumeIOFailure(current); err != nil {
+			return 0, err
+		}
+		if duration, ok := creationToContainerRunningDuration(current); ok {
+			return duration, nil
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return 0, podStartupError(ctx.Err(), tracker.lastPod)
+		case event, ok := <-podWatch.ResultChan():
+			if !ok || event.Type == watch.Error {
+				if ctx.Err() != nil {
+					return 0, podStartupError(ctx.Err(), tracker.lastPod)
+				}
+				klog.InfoS("Synthetic pod watch broke, falling back to polling", "name", pod.Name)
+				return c.pollPodCreationToContainerRunningDuration(ctx, pod.Name)
+			}
+			current, ok := event.Object.(*corev1.Pod)
+			if !ok || current.Name != pod.Name {
+				continue
+			}
+			tracker.observePod(current, time.Now(), true)
+			if err := volumeIOFailure(current); err != nil {
+				return 0, err
+			}
+			duration, ok := creationToContainerRunningDuration(current)
+			if !ok {
+				continue
+			}
+			if tracker.watchedStartup() {
+				return tracker.creationToContainerRunningDuration(), nil
+			}
+			return duration, nil
+		case event, ok := <-eventCh:
+			if !ok || event.Type == watch.Error {
+				klog.InfoS("Synthetic pod event watch broke", "name", pod.Name)
+				eventCh = nil
+				tracker.eventsWatched = false
+				continue
+			}
+			if e, ok := event.Object.(*corev1.Event); ok && e.InvolvedObject.Name == pod.Name {
+				tracker.observeEvent(e, time.Now())
+			}
+		}
+	}
+}
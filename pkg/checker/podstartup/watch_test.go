@@ -0,0 +1,207 @@
+package podstartup
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/cluster-health-monitor/pkg/config"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestPodStartupChecker_waitForContainerRunning(t *testing.T) {
+	namespace := "test-namespace"
+	podName := "pod1"
+	created := time.Now()
+
+	defaultPollingInterval := pollingInterval
+	pollingInterval = 10 * time.Millisecond
+	defer func() { pollingInterval = defaultPollingInterval }()
+
+	pendingPod := func() *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: podName, Namespace: namespace, CreationTimestamp: metav1.NewTime(created)},
+		}
+	}
+	scheduledPod := func() *corev1.Pod {
+		pod := pendingPod()
+		pod.Status.Conditions = []corev1.PodCondition{podCondition(corev1.PodScheduled, created.Add(1*time.Second))}
+		return pod
+	}
+	runningPod := func() *corev1.Pod {
+		pod := scheduledPod()
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			State: corev1.ContainerState{
+				Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(created.Add(3 * time.Second))},
+			},
+		}}
+		return pod
+	}
+	backOffPod := func() *corev1.Pod {
+		pod := scheduledPod()
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"},
+			},
+		}}
+		return pod
+	}
+
+	tests := []struct {
+		name string
+		// podWatchErr fails the pod watch. Otherwise, sendPodChanges sends the changes of the pod to the watch and closes it if it returns
+		// true.
+		podWatchErr    error
+		sendPodChanges func(podWatcher, eventWatcher *watch.FakeWatcher) bool
+		validateRes    func(g *WithT, tracker *podStartupTracker, duration time.Duration, err error)
+	}{
+		{
+			name: "transitions observed through the watch",
+			sendPodChanges: func(podWatcher, eventWatcher *watch.FakeWatcher) bool {
+				eventWatcher.Add(imageAlreadyPresentEvent(namespace, podName))
+				podWatcher.Modify(scheduledPod())
+				podWatcher.Modify(runningPod())
+				return false
+			},
+			validateRes: func(g *WithT, tracker *podStartupTracker, duration time.Duration, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(tracker.watchedStartup()).To(BeTrue())
+				g.Expect(tracker.transitions).To(HaveKey(corev1.PodScheduled))
+				g.Expect(tracker.running).ToNot(BeTemporally("<", tracker.transitions[corev1.PodScheduled]))
+				g.Expect(duration).To(Equal(tracker.running.Sub(created)))
+
+				events, ok := tracker.watchedEvents()
+				g.Expect(ok).To(BeTrue())
+				g.Expect(events).To(HaveLen(1))
+			},
+		},
+		{
+			name: "falls back to polling when the watch breaks",
+			sendPodChanges: func(podWatcher, eventWatcher *watch.FakeWatcher) bool {
+				return true
+			},
+			validateRes: func(g *WithT, tracker *podStartupTracker, duration time.Duration, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(tracker.watchedStartup()).To(BeFalse())
+				g.Expect(duration).To(Equal(3 * time.Second))
+			},
+		},
+		{
+			name:        "falls back to polling when the watch cannot be established",
+			podWatchErr: errors.New("watch not supported"),
+			validateRes: func(g *WithT, tracker *podStartupTracker, duration time.Duration, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(tracker.watchedStartup()).To(BeFalse())
+				g.Expect(duration).To(Equal(3 * time.Second))
+			},
+		},
+		{
+			name: "image pull back-off observed through the watch",
+			sendPodChanges: func(podWatcher, eventWatcher *watch.FakeWatcher) bool {
+				podWatcher.Modify(backOffPod())
+				return false
+			},
+			validateRes: func(g *WithT, tracker *podStartupTracker, duration time.Duration, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+				var pullErr *imagePullError
+				g.Expect(errors.As(err, &pullErr)).To(BeTrue())
+				g.Expect(pullErr.code()).To(Equal(ErrCodeImagePullBackOff))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			client := k8sfake.NewClientset()
+			// The pod is pending when the watch is established and running when it is polled afterwards.
+			gets := 0
+			client.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				gets++
+				if gets == 1 {
+					return true, pendingPod(), nil
+				}
+				return true, runningPod(), nil
+			})
+			podWatcher := watch.NewFake()
+			eventWatcher := watch.NewFake()
+			client.PrependWatchReactor("pods", func(action k8stesting.Action) (bool, watch.Interface, error) {
+				if tt.podWatchErr != nil {
+					return true, nil, tt.podWatchErr
+				}
+				return true, podWatcher, nil
+			})
+			client.PrependWatchReactor("events", func(action k8stesting.Action) (bool, watch.Interface, error) {
+				return true, eventWatcher, nil
+			})
+			if tt.sendPodChanges != nil {
+				go func() {
+					if tt.sendPodChanges(podWatcher, eventWatcher) {
+						podWatcher.Stop()
+					}
+				}()
+			}
+
+			checker := &PodStartupChecker{
+				k8sClientset: client,
+				config:       &config.PodStartupConfig{SyntheticPodNamespace: namespace},
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			tracker := newPodStartupTracker(created)
+			duration, err := checker.waitForContainerRunning(ctx, pendingPod(), tracker)
+			tt.validateRes(g, tracker, duration, err)
+		})
+	}
+}
+
+func TestPodStartupTracker_timeline(t *testing.T) {
+	g := NewWithT(t)
+	created := time.Now()
+	tracker := newPodStartupTracker(created)
+	tracker.eventsWatched = true
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1"}}
+	tracker.observePod(pod.DeepCopy(), created.Add(100*time.Millisecond), false)
+
+	pod.Status.Conditions = []corev1.PodCondition{
+		podCondition(corev1.PodScheduled, created),
+		podCondition(corev1.PodInitialized, created),
+	}
+	tracker.observePod(pod.DeepCopy(), created.Add(250*time.Millisecond), true)
+	tracker.observeEvent(podEvent("ns", "pod1", "attach", "SuccessfulAttachVolume", "attached", 1, created), created.Add(1250*time.Millisecond))
+
+	pod.Status.Conditions = append(pod.Status.Conditions, podCondition(corev1.PodReadyToStartContainers, created))
+	tracker.observePod(pod.DeepCopy(), created.Add(1500*time.Millisecond), true)
+
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(created)}},
+	}}
+	tracker.observePod(pod.DeepCopy(), created.Add(2500*time.Millisecond), true)
+
+	g.Expect(tracker.watchedStartup()).To(BeTrue())
+	g.Expect(tracker.creationToContainerRunningDuration()).To(Equal(2500 * time.Millisecond))
+	g.Expect(tracker.timeline().phases(400 * time.Millisecond)).To(Equal([]startupPhase{
+		{name: phaseScheduling, duration: 250 * time.Millisecond},
+		{name: phaseVolumeAttach, duration: 1000 * time.Millisecond},
+		{name: phaseSandbox, duration: 250 * time.Millisecond},
+		{name: phaseInitialization, duration: 0},
+		{name: phaseContainerStart, duration: 600 * time.Millisecond},
+	}))
+
+	// A transition seen outside the watch has an unknown time, so the startup is not considered watched.
+	missed := newPodStartupTracker(created)
+	missed.eventsWatched = true
+	missed.observePod(pod.DeepCopy(), created.Add(100*time.Millisecond), false)
+	g.Expect(missed.watchedStartup()).To(BeFalse())
+}
@@ -27,7 +27,8 @@ type Detail struct {
 	// Code is a string that represents the error code of the unhealthy check result.
 	Code string
 
-	// Message is a string that provides a human-readable message about the unhealthy result.
+	// Message is a string that provides a human-readable message about the unhealthy result. Healthy results may also have a message
+	// with details about the check.
 	Message string
 
 	// Pod is the name of the pod associated with this result, if applicable.
@@ -1,7 +1,14 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"time"
+
+	"gopkg.in/yaml.v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 type CheckerType string
@@ -12,6 +19,8 @@ const (
 	CheckTypeAPIServer     CheckerType = "APIServer"
 	CheckTypeMetricsServer CheckerType = "MetricsServer"
 	CheckTypeAzurePolicy   CheckerType = "AzurePolicy"
+
+	CheckTypeNodeProvisioning CheckerType = "NodeProvisioning"
 )
 
 // Config represents the configuration for the health checkers.
@@ -33,10 +42,27 @@ type CheckerConfig struct {
 	// Each checker type must be accompanied by its specific configuration if it requires additional parameters.
 	Type CheckerType `yaml:"type"`
 
-	// Required.
+	// Required unless Schedule is set.
 	// The interval at which the checker should run. The string format see https://pkg.go.dev/time#ParseDuration
-	// It must be greater than 0.
-	Interval time.Duration `yaml:"interval"`
+	// It must be greater than 0. It must not be set together with Schedule.
+	Interval time.Duration `yaml:"interval,omitempty"`
+
+	// Optional.
+	// A cron expression in the standard five field format, e.g. "0 2 * * *", or a descriptor such as "@hourly", at which the checker
+	// should run instead of running every Interval. Times are interpreted in the time zone of the container, usually UTC, unless the
+	// expression starts with CRON_TZ=<zone>.
+	Schedule string `yaml:"schedule,omitempty"`
+
+	// Optional.
+	// The delay before the first run of the checker. Without it the first run happens one Interval after the checker starts, or at the
+	// first time matching Schedule. The string format see https://pkg.go.dev/time#ParseDuration
+	// It must be 0 or greater.
+	InitialDelay time.Duration `yaml:"initialDelay,omitempty"`
+
+	// Optional.
+	// Delays each run by a random duration of up to this percentage of the time between two runs, so that checkers do not all run at
+	// the same instant. It must be between 0 and 100.
+	JitterPercent int `yaml:"jitterPercent,omitempty"`
 
 	// Required.
 	// The timeout for the checker, used to determine how long to wait for a response before considering the check failed.
@@ -44,6 +70,16 @@ type CheckerConfig struct {
 	// It must be greater than 0.
 	Timeout time.Duration `yaml:"timeout"`
 
+	// Optional.
+	// The number of consecutive failed runs after which the effective status of a healthy checker becomes unhealthy, like the
+	// failureThreshold of Kubernetes probes. Defaults to 1. It must be 0 or greater, where 0 means the default.
+	FailureThreshold int `yaml:"failureThreshold,omitempty"`
+
+	// Optional.
+	// The number of consecutive healthy runs after which the effective status of a failing checker becomes healthy again, like the
+	// successThreshold of Kubernetes probes. Defaults to 1. It must be 0 or greater, where 0 means the default.
+	SuccessThreshold int `yaml:"successThreshold,omitempty"`
+
 	// Optional.
 	// The configuration for the DNS checker, this field is required if Type is CheckTypeDNS.
 	DNSConfig *DNSConfig `yaml:"dnsConfig,omitempty"`
@@ -55,6 +91,10 @@ type CheckerConfig struct {
 	// Optional.
 	// The configuration for the API server checker, this field is required if Type is CheckTypeAPIServer.
 	APIServerConfig *APIServerConfig `yaml:"apiServerConfig,omitempty"`
+
+	// Optional.
+	// The configuration for the node provisioning checker, this field is required if Type is CheckTypeNodeProvisioning.
+	NodeProvisioningConfig *NodeProvisioningConfig `yaml:"nodeProvisioningConfig,omitempty"`
 }
 
 type DNSConfig struct {
@@ -66,15 +106,78 @@ type DNSConfig struct {
 	// It must be greater than 0.
 	QueryTimeout time.Duration `yaml:"queryTimeout"`
 	// Required.
-	// DNS check mode: core DNS, per-pod core DNS, or local DNS.
+	// DNS check mode: core DNS, per-pod core DNS, local DNS, custom nameservers, or resolution through the resolv.conf of the
+	// monitor pod. With ResolvConf, the domain is expanded through the search list like glibc does with the ndots option, so it is
+	// usually a short name such as "kubernetes.default".
 	Target DNSCheckTarget `yaml:"target,omitempty"`
+	// Optional.
+	// The nameservers to query, as IP addresses with an optional port, e.g. "168.63.129.16" or "10.0.0.53:5353". The port defaults to 53.
+	// This field is required if Target is Custom and must not be set for other targets.
+	Nameservers []string `yaml:"nameservers,omitempty"`
+	// Optional.
+	// The namespace of the CoreDNS service, used when Target is CoreDNS or CoreDNSPerPod. Defaults to kube-system.
+	// The default manifests only grant read access to services and endpointslices in kube-system.
+	ServiceNamespace string `yaml:"serviceNamespace,omitempty"`
+	// Optional.
+	// The name of the CoreDNS service, used when Target is CoreDNS or CoreDNSPerPod. Defaults to kube-dns.
+	ServiceName string `yaml:"serviceName,omitempty"`
+	// Optional.
+	// The DNS record type to query, one of A, AAAA, SRV, CNAME or PTR. Defaults to A.
+	// For PTR queries, the domain can also be an IP address, which is converted to its reverse lookup name.
+	RecordType DNSRecordType `yaml:"recordType,omitempty"`
+	// Optional.
+	// The answers that must all be present in the response. They are IP addresses for A and AAAA records, and domain names for SRV
+	// (the target), CNAME and PTR records.
+	ExpectedAnswers []string `yaml:"expectedAnswers,omitempty"`
+	// Optional.
+	// The minimum number of answers of the record type the response must contain. If unset or 0, at least one answer is required.
+	// It must be 0 or greater.
+	MinAnswers int `yaml:"minAnswers,omitempty"`
+	// Optional.
+	// The transport protocol of the queries, one of UDP, UDPOnly, TCP or UDPAndTCP. Defaults to UDP.
+	// With UDP, a truncated response is retried over TCP like a stub resolver would, unless edns0BufferSize is set to check large UDP
+	// responses. With UDPOnly, queries are only sent over UDP and a truncated response is reported as a failure.
+	// With UDPAndTCP, every query is sent over UDP and then over TCP, and a truncated UDP response is retried over TCP like a stub
+	// resolver would, so that blocked TCP and a broken TCP fallback are reported with their own error codes.
+	Protocol DNSProtocol `yaml:"protocol,omitempty"`
+	// Optional.
+	// The UDP buffer size in bytes advertised with EDNS0. If unset or 0, queries are sent without EDNS0 and responses over UDP are
+	// limited to 512 bytes. It must be 0 or between 512 and 65535.
+	// Together with a domain that returns large answers, it checks that large UDP responses are not dropped, e.g. by a path MTU
+	// blackhole.
+	EDNS0BufferSize int `yaml:"edns0BufferSize,omitempty"`
+	// Optional.
+	// Whether to compare the answers of the CoreDNS pods, which can only be set if Target is CoreDNSPerPod. Pods whose answers differ from
+	// the answers of most pods, e.g. during a Corefile rollout, get an unhealthy per-pod result, and an overall result names them.
+	CheckAnswerConsistency bool `yaml:"checkAnswerConsistency,omitempty"`
 }
 type DNSCheckTarget string
 
+type DNSRecordType string
+
+type DNSProtocol string
+
+const (
+	DNSProtocolUDP       DNSProtocol = "UDP"
+	DNSProtocolUDPOnly   DNSProtocol = "UDPOnly"
+	DNSProtocolTCP       DNSProtocol = "TCP"
+	DNSProtocolUDPAndTCP DNSProtocol = "UDPAndTCP"
+)
+
+const (
+	DNSRecordTypeA     DNSRecordType = "A"
+	DNSRecordTypeAAAA  DNSRecordType = "AAAA"
+	DNSRecordTypeSRV   DNSRecordType = "SRV"
+	DNSRecordTypeCNAME DNSRecordType = "CNAME"
+	DNSRecordTypePTR   DNSRecordType = "PTR"
+)
+
 const (
 	DNSCheckTargetCoreDNS       DNSCheckTarget = "CoreDNS"
 	DNSCheckTargetCoreDNSPerPod DNSCheckTarget = "CoreDNSPerPod"
 	DNSCheckTargetLocalDNS      DNSCheckTarget = "LocalDNS"
+	DNSCheckTargetCustom        DNSCheckTarget = "Custom"
+	DNSCheckTargetResolvConf    DNSCheckTarget = "ResolvConf"
 )
 
 type PodStartupConfig struct {
@@ -114,25 +217,370 @@ type PodStartupConfig struct {
 
 	// Optional.
 	// This field is meant to be enabled only on AKS Automatic clusters. If set to true, the PodStartupChecker will trigger node provisioning
-	// and deploy synthetic pods to the new node.
+	// and deploy synthetic pods to the new node. The node provisioning itself is only bounded by the checker timeout; use a checker of type
+	// NodeProvisioning to measure its stages.
 	EnableNodeProvisioningTest bool `yaml:"enableNodeProvisioningTest,omitempty"`
 
 	// Optional.
-	// EnabledCSIs configurations for the PodStartupChecker. Presence of an item implies the EnabledCSIs type is enabled. The checker will create synthetic
-	// pods with PVCs of the specified EnabledCSIs types, and fail if the pods cannot start successfully with the attached EnabledCSIs volumes.
+	// The storage probes of the PodStartupChecker. For each item, the checker creates a PVC of the item's StorageClass and mounts it in the
+	// synthetic pod, and fails if the pod cannot start successfully with the attached volumes.
 	EnabledCSIs []CSIConfig `yaml:"enabledCSIs,omitempty"`
+
+	// Optional.
+	// VolumeIO verifies that the volumes of EnabledCSIs can be written and read back, not only mounted. An init container of the synthetic
+	// pod writes a file to each volume, fsyncs it and reads it back. This requires a shell and GNU coreutils in Image. The duration of the
+	// volume I/O is not counted in the pod startup duration.
+	VolumeIO *VolumeIOConfig `yaml:"volumeIO,omitempty"`
+
+	// Optional.
+	// The container image of the synthetic pods. It must serve TCP connections on Port. Defaults to DefaultSyntheticPodImage. Setting an
+	// image from a private registry also probes that registry on every run.
+	Image string `yaml:"image,omitempty"`
+
+	// Optional.
+	// The names of the secrets in SyntheticPodNamespace used to pull Image.
+	ImagePullSecrets []string `yaml:"imagePullSecrets,omitempty"`
+
+	// Optional.
+	// The TCP port that Image listens on, used for the connectivity check to the synthetic pods. Defaults to DefaultSyntheticPodPort.
+	Port int `yaml:"port,omitempty"`
+
+	// Optional.
+	// The image pull policy of the synthetic pods: Always, IfNotPresent or Never. Defaults to the Kubernetes default for Image. Use Always
+	// together with ImagePullThreshold to measure pulls from the registry on every run.
+	ImagePullPolicy string `yaml:"imagePullPolicy,omitempty"`
+
+	// Optional.
+	// The maximum image pull duration (including waiting) of the synthetic pods for which the checker will return healthy status. Exceeding
+	// it causes the checker to return unhealthy status with the ImagePullDurationExceeded error code. The image pull is not checked if it is
+	// 0 or the image was already present on the node.
+	ImagePullThreshold time.Duration `yaml:"imagePullThreshold,omitempty"`
+
+	// Optional.
+	// Placement fans the synthetic pods out across groups of nodes, e.g. node pools or zones. If set, the checker starts one synthetic pod
+	// in each group on every run instead of a single one on a system node, and records a result per group. The checker timeout must allow
+	// for all groups to be probed at the configured concurrency.
+	Placement *PlacementConfig `yaml:"placement,omitempty"`
+
+	// Optional.
+	// PodTemplate is merged onto the generated synthetic pods, e.g. to schedule them on clusters without the AKS node labels.
+	PodTemplate *SyntheticPodTemplate `yaml:"podTemplate,omitempty"`
+
+	// Optional.
+	// NodePool customizes the Karpenter NodePool created when EnableNodeProvisioningTest is true.
+	NodePool *NodePoolConfig `yaml:"nodePool,omitempty"`
+}
+
+// SyntheticPodTemplate holds the fields of the synthetic pods that can be overridden. The fields use the Kubernetes API field names, e.g.
+// tolerations[].tolerationSeconds, and are merged onto the generated pod as follows:
+//   - NodeSelector is added to the generated node selector, overriding the values of the same keys.
+//   - Affinity replaces the generated affinity, which requires linux system-mode nodes of an AKS cluster.
+//   - Tolerations are added to the generated tolerations.
+//   - Resources, PriorityClassName, RuntimeClassName and SecurityContext are set on the pod, or its containers for Resources.
+type SyntheticPodTemplate struct {
+	NodeSelector      map[string]string            `json:"nodeSelector,omitempty"`
+	Affinity          *corev1.Affinity             `json:"affinity,omitempty"`
+	Tolerations       []corev1.Toleration          `json:"tolerations,omitempty"`
+	Resources         *corev1.ResourceRequirements `json:"resources,omitempty"`
+	PriorityClassName string                       `json:"priorityClassName,omitempty"`
+	RuntimeClassName  string                       `json:"runtimeClassName,omitempty"`
+	SecurityContext   *corev1.PodSecurityContext   `json:"securityContext,omitempty"`
+}
+
+// UnmarshalYAML decodes the template through JSON, so that the Kubernetes API types use their JSON field names. Unknown fields are rejected
+// to catch misspelled overrides.
+func (t *SyntheticPodTemplate) UnmarshalYAML(value *yaml.Node) error {
+	var raw any
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return fmt.Errorf("failed to convert pod template to json: %w", err)
+	}
+	// The alias type does not have the UnmarshalYAML method, so decoding into it does not recurse.
+	type template SyntheticPodTemplate
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode((*template)(t)); err != nil {
+		return fmt.Errorf("invalid pod template: %w", err)
+	}
+	return nil
+}
+
+type NodeProvisioningConfig struct {
+	// Required.
+	// The namespace in which the pods that trigger the node provisioning are created.
+	Namespace string `yaml:"namespace"`
+
+	// Required.
+	// The Kubernetes label key used to identify the NodePools and pods created by the checker. It is also applied as a taint to the nodes
+	// provisioned for the checker, so that no other pods are scheduled on them.
+	LabelKey string `yaml:"labelKey"`
+
+	// Required.
+	// The maximum number of NodePools created by the checker that can exist at any one time. If the limit has been reached, the checker
+	// will not create any more NodePools until some of the existing ones are deleted. Instead, it will fail the run with an error.
+	// Reaching this limit effectively disables the checker.
+	MaxNodePools int `yaml:"maxNodePools"`
+
+	// Optional.
+	// The container image of the pods that trigger the node provisioning. The pods do not have to start, so the image is only pulled once
+	// the node is ready. Defaults to DefaultSyntheticPodImage.
+	Image string `yaml:"image,omitempty"`
+
+	// Optional.
+	// NodePool customizes the Karpenter NodePool created by the checker.
+	NodePool *NodePoolConfig `yaml:"nodePool,omitempty"`
+}
+
+// TriggerPodImage returns the container image of the pods that trigger the node provisioning.
+func (c *NodeProvisioningConfig) TriggerPodImage() string {
+	if c.Image == "" {
+		return DefaultSyntheticPodImage
+	}
+	return c.Image
 }
 
+type NodePoolConfig struct {
+	// Optional.
+	// The node class of the nodes provisioned by the NodePool. Defaults to the AKSNodeClass named default.
+	NodeClassRef *NodeClassRef `yaml:"nodeClassRef,omitempty"`
+
+	// Optional.
+	// The requirements of the nodes provisioned by the NodePool. They replace the default requirement of the amd64 architecture.
+	Requirements []NodeRequirement `yaml:"requirements,omitempty"`
+}
+
+type NodeClassRef struct {
+	// Required.
+	// The API group of the node class, e.g. karpenter.azure.com.
+	Group string `yaml:"group"`
+
+	// Required.
+	// The kind of the node class, e.g. AKSNodeClass.
+	Kind string `yaml:"kind"`
+
+	// Required.
+	// The name of the node class.
+	Name string `yaml:"name"`
+}
+
+type NodeRequirement struct {
+	// Required.
+	// The node label key the requirement applies to.
+	Key string `yaml:"key"`
+
+	// Required.
+	// The operator of the requirement: In, NotIn, Exists, DoesNotExist, Gt or Lt.
+	Operator string `yaml:"operator"`
+
+	// Optional.
+	// The values of the requirement. Required for In, NotIn, Gt and Lt, not allowed for Exists and DoesNotExist.
+	Values []string `yaml:"values,omitempty"`
+}
+
+type PlacementConfig struct {
+	// Required.
+	// How the nodes are grouped: by node pool, by zone, or by the values of an arbitrary node label.
+	By PlacementBy `yaml:"by"`
+
+	// Required if By is NodeLabel, not allowed otherwise.
+	// The key of the node label whose values group the nodes.
+	NodeLabelKey string `yaml:"nodeLabelKey,omitempty"`
+
+	// Optional.
+	// The maximum number of synthetic pods started at the same time. Defaults to 1. It must not exceed MaxSyntheticPods, and is further
+	// limited by the number of synthetic pods that can still be created without exceeding MaxSyntheticPods.
+	MaxConcurrency int `yaml:"maxConcurrency,omitempty"`
+}
+
+type PlacementBy string
+
+const (
+	PlacementByNodePool  PlacementBy = "NodePool"
+	PlacementByZone      PlacementBy = "Zone"
+	PlacementByNodeLabel PlacementBy = "NodeLabel"
+)
+
+// NodeLabel returns the key of the node label whose values group the nodes.
+func (c *PlacementConfig) NodeLabel() string {
+	switch c.By {
+	case PlacementByNodePool:
+		return "kubernetes.azure.com/agentpool"
+	case PlacementByZone:
+		return "topology.kubernetes.io/zone"
+	default:
+		return c.NodeLabelKey
+	}
+}
+
+// Concurrency returns the maximum number of synthetic pods started at the same time.
+func (c *PlacementConfig) Concurrency() int {
+	if c.MaxConcurrency == 0 {
+		return 1
+	}
+	return c.MaxConcurrency
+}
+
+const (
+	// DefaultSyntheticPodImage is the container image of the synthetic pods if PodStartupConfig.Image is not set.
+	DefaultSyntheticPodImage = "mcr.microsoft.com/azurelinux/base/nginx:1.25.4-4-azl3.0.20250702"
+
+	// DefaultSyntheticPodPort is the TCP port of the synthetic pods if PodStartupConfig.Port is not set.
+	DefaultSyntheticPodPort = 80
+)
+
+// SyntheticPodImage returns the container image of the synthetic pods.
+func (c *PodStartupConfig) SyntheticPodImage() string {
+	if c.Image == "" {
+		return DefaultSyntheticPodImage
+	}
+	return c.Image
+}
+
+// SyntheticPodPort returns the TCP port of the synthetic pods.
+func (c *PodStartupConfig) SyntheticPodPort() int {
+	if c.Port == 0 {
+		return DefaultSyntheticPodPort
+	}
+	return c.Port
+}
+
+// CSIConfig describes a storage probe: a PVC of StorageClass that is mounted in the synthetic pods.
 type CSIConfig struct {
-	// Required when an item is present.
-	// Type of CSI storage to attach.
-	Type CSIType `yaml:"type"`
+	// Optional.
+	// A preset for one of the Azure CSI drivers, which sets the default AccessMode of the PVC. Without a preset, the probe works with the
+	// StorageClass of any CSI driver, e.g. local-path, NFS or Azure Container Storage.
+	Type CSIType `yaml:"type,omitempty"`
 
 	// Required when an item is present.
-	// Name of the StorageClass to use for this CSI type.
+	// Name of the StorageClass of the PVC. It identifies the probe in the volume I/O results and metrics, so it must be unique.
 	StorageClass string `yaml:"storageClass"`
+
+	// Optional.
+	// The access mode of the PVC, one of ReadWriteOnce, ReadWriteOncePod, ReadWriteMany and ReadOnlyMany. Defaults to the access mode of
+	// the preset Type, or ReadWriteOnce without a preset.
+	AccessMode string `yaml:"accessMode,omitempty"`
+
+	// Optional.
+	// The requested storage of the PVC, as a Kubernetes quantity, e.g. 10Gi. Defaults to DefaultCSISize.
+	Size string `yaml:"size,omitempty"`
+
+	// Optional.
+	// The volume mode of the PVC, either Filesystem or Block. Defaults to Filesystem. A Block volume is attached to the synthetic pod as a
+	// raw device at MountPath instead of being mounted.
+	VolumeMode string `yaml:"volumeMode,omitempty"`
+
+	// Optional.
+	// The absolute path at which the volume is mounted in the synthetic pod, or the path of the device of a Block volume. Defaults to
+	// /mnt/<StorageClass>, or /dev/<StorageClass> for a Block volume. It must be unique.
+	MountPath string `yaml:"mountPath,omitempty"`
+
+	// Optional.
+	// The maximum duration of provisioning the PVC, from the start of the provisioning until the PVC is provisioned, for which the checker
+	// will return healthy status. Exceeding it causes the checker to return unhealthy status with the PVCProvisioningTimeout error code. The
+	// duration is not checked if it is 0.
+	ProvisioningThreshold time.Duration `yaml:"provisioningThreshold,omitempty"`
+
+	// Optional.
+	// The maximum duration of attaching the volume to the node of the synthetic pod, from the creation of its VolumeAttachment until it is
+	// attached, for which the checker will return healthy status. Exceeding it causes the checker to return unhealthy status with the
+	// VolumeAttachTimeout error code. The duration is not checked if it is 0, and not measured for volumes that are not attached, e.g. NFS.
+	AttachThreshold time.Duration `yaml:"attachThreshold,omitempty"`
+
+	// Optional.
+	// The maximum duration of mounting the volume, from the volume being attached, or the synthetic pod being scheduled for volumes that are
+	// not attached, until the pod sandbox is ready, for which the checker will return healthy status. Exceeding it causes the checker to
+	// return unhealthy status with the VolumeMountTimeout error code. The duration is not checked if it is 0.
+	MountThreshold time.Duration `yaml:"mountThreshold,omitempty"`
+}
+
+// DefaultCSISize is the requested storage of the PVC of a storage probe if CSIConfig.Size is not set.
+const DefaultCSISize = "5Gi"
+
+// csiPreset holds the defaults of a CSIConfig with a preset Type.
+type csiPreset struct {
+	accessMode corev1.PersistentVolumeAccessMode
+	// block is true if the CSI driver supports the Block volume mode.
+	block bool
+}
+
+var csiPresets = map[CSIType]csiPreset{
+	CSITypeAzureDisk: {accessMode: corev1.ReadWriteOnce, block: true},
+	CSITypeAzureFile: {accessMode: corev1.ReadWriteMany},
+	CSITypeAzureBlob: {accessMode: corev1.ReadWriteMany},
+}
+
+// PVCAccessMode returns the access mode of the PVC of the probe.
+func (c CSIConfig) PVCAccessMode() corev1.PersistentVolumeAccessMode {
+	if c.AccessMode != "" {
+		return corev1.PersistentVolumeAccessMode(c.AccessMode)
+	}
+	if preset, ok := csiPresets[c.Type]; ok {
+		return preset.accessMode
+	}
+	return corev1.ReadWriteOnce
+}
+
+// PVCSize returns the requested storage of the PVC of the probe. It must only be called on a validated config.
+func (c CSIConfig) PVCSize() resource.Quantity {
+	if c.Size == "" {
+		return resource.MustParse(DefaultCSISize)
+	}
+	return resource.MustParse(c.Size)
+}
+
+// PVCVolumeMode returns the volume mode of the PVC of the probe.
+func (c CSIConfig) PVCVolumeMode() corev1.PersistentVolumeMode {
+	if c.VolumeMode == "" {
+		return corev1.PersistentVolumeFilesystem
+	}
+	return corev1.PersistentVolumeMode(c.VolumeMode)
+}
+
+// VolumePath returns the path at which the volume of the probe is mounted, or the path of its device for a Block volume.
+func (c CSIConfig) VolumePath() string {
+	switch {
+	case c.MountPath != "":
+		return c.MountPath
+	case c.PVCVolumeMode() == corev1.PersistentVolumeBlock:
+		return "/dev/" + c.StorageClass
+	default:
+		return "/mnt/" + c.StorageClass
+	}
+}
+
+type VolumeIOConfig struct {
+	// Optional.
+	// The size of the file written to and read back from each volume, as a Kubernetes quantity, e.g. 512Ki. Defaults to
+	// DefaultVolumeIOFileSize, and must not exceed MaxVolumeIOFileSize.
+	FileSize string `yaml:"fileSize,omitempty"`
+
+	// Optional.
+	// The maximum duration of writing or reading back the file of a volume for which the checker will return healthy status. Exceeding it
+	// causes the checker to return unhealthy status with the VolumeIOSlow error code. The duration is not checked if it is 0.
+	Threshold time.Duration `yaml:"threshold,omitempty"`
+}
+
+const (
+	// DefaultVolumeIOFileSize is the size of the file written to each volume if VolumeIOConfig.FileSize is not set.
+	DefaultVolumeIOFileSize = "1Mi"
+
+	// MaxVolumeIOFileSize is the maximum size of the file written to each volume. The file must also fit in the PVC of each probe.
+	MaxVolumeIOFileSize = "1Gi"
+)
+
+// FileSizeBytes returns the size in bytes of the file written to each volume. It must only be called on a validated config.
+func (c *VolumeIOConfig) FileSizeBytes() int64 {
+	size := resource.MustParse(DefaultVolumeIOFileSize)
+	if c.FileSize != "" {
+		size = resource.MustParse(c.FileSize)
+	}
+	return size.Value()
 }
 
+// CSIType is a preset of CSIConfig for one of the Azure CSI drivers.
 type CSIType string
 
 const (
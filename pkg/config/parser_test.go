@@ -44,3 +44,66 @@ func TestParseFromFile_NotExist(t *testing.T) {
 	_, err := ParseFromFile("/tmp/does-not-exist.yaml")
 	g.Expect(err).To(HaveOccurred())
 }
+
+func TestParseFromYAML_PodTemplate(t *testing.T) {
+	g := NewWithT(t)
+	yamlData := []byte(`
+checkers:
+  - name: podstartup
+    type: PodStartup
+    interval: 30s
+    timeout: 20s
+    podStartupConfig:
+      syntheticPodNamespace: kube-system
+      syntheticPodLabelKey: cluster-health-monitor/checker-synthetic
+      syntheticPodStartupTimeout: 5s
+      maxSyntheticPods: 5
+      tcpTimeout: 1s
+      tcpMaxRetries: 3
+      tcpRetryInterval: 1s
+      podTemplate:
+        nodeSelector:
+          node-role.kubernetes.io/worker: ""
+        tolerations:
+          - key: dedicated
+            operator: Exists
+            effect: NoExecute
+            tolerationSeconds: 30
+        resources:
+          requests:
+            cpu: 10m
+            memory: 16Mi
+        priorityClassName: system-cluster-critical
+        runtimeClassName: kata
+`)
+	cfg, err := ParseFromYAML(yamlData)
+	g.Expect(err).ToNot(HaveOccurred())
+	template := cfg.Checkers[0].PodStartupConfig.PodTemplate
+	g.Expect(template).ToNot(BeNil())
+	g.Expect(template.NodeSelector).To(HaveKeyWithValue("node-role.kubernetes.io/worker", ""))
+	g.Expect(template.Tolerations).To(HaveLen(1))
+	g.Expect(*template.Tolerations[0].TolerationSeconds).To(Equal(int64(30)))
+	g.Expect(template.Resources.Requests.Cpu().String()).To(Equal("10m"))
+	g.Expect(template.Resources.Requests.Memory().String()).To(Equal("16Mi"))
+	g.Expect(template.PriorityClassName).To(Equal("system-cluster-critical"))
+	g.Expect(template.RuntimeClassName).To(Equal("kata"))
+}
+
+func TestParseFromYAML_PodTemplateUnknownField(t *testing.T) {
+	g := NewWithT(t)
+	yamlData := []byte(`
+checkers:
+  - name: podstartup
+    type: PodStartup
+    timeout: 20s
+    podStartupConfig:
+      podTemplate:
+        tolerations:
+          - key: dedicated
+            tolerationSecond: 30
+`)
+	_, err := ParseFromYAML(yamlData)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("invalid pod template"))
+	g.Expect(err.Error()).To(ContainSubstring("tolerationSecond"))
+}
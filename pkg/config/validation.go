@@ -1,10 +1,19 @@
 package config
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"math"
+	"net"
+	"path"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/robfig/cron/v3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	apivalidation "k8s.io/apimachinery/pkg/api/validation"
 	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 )
@@ -38,16 +47,42 @@ func (c *CheckerConfig) validate() error {
 	var errs []error
 	if c.Name == "" {
 		errs = append(errs, fmt.Errorf("checker config missing 'name'"))
+	} else if len(c.Name) > 253 {
+		// The name is published in the ClusterHealthStatus resource, which limits it to 253 characters.
+		errs = append(errs, fmt.Errorf("checker config invalid 'name': must be no more than 253 characters"))
 	}
 	if c.Type == "" {
 		errs = append(errs, fmt.Errorf("checker config missing 'type'"))
 	}
-	if c.Interval <= 0 {
-		errs = append(errs, fmt.Errorf("checker config invalid 'interval': %s", c.Interval))
+	if c.Schedule == "" {
+		if c.Interval <= 0 {
+			errs = append(errs, fmt.Errorf("checker config invalid 'interval': %s", c.Interval))
+		}
+	} else {
+		if c.Interval != 0 {
+			errs = append(errs, fmt.Errorf("checker config 'interval' and 'schedule' are mutually exclusive"))
+		}
+		if schedule, err := cron.ParseStandard(c.Schedule); err != nil {
+			errs = append(errs, fmt.Errorf("checker config invalid 'schedule': value='%s', error='%w'", c.Schedule, err))
+		} else if schedule.Next(time.Now()).IsZero() {
+			errs = append(errs, fmt.Errorf("checker config invalid 'schedule': value='%s', error='schedule never matches'", c.Schedule))
+		}
+	}
+	if c.InitialDelay < 0 {
+		errs = append(errs, fmt.Errorf("checker config invalid 'initialDelay': %s, must be 0 or greater", c.InitialDelay))
+	}
+	if c.JitterPercent < 0 || c.JitterPercent > 100 {
+		errs = append(errs, fmt.Errorf("checker config invalid 'jitterPercent': %d, must be between 0 and 100", c.JitterPercent))
 	}
 	if c.Timeout <= 0 {
 		errs = append(errs, fmt.Errorf("checker config invalid 'timeout': %s", c.Timeout))
 	}
+	if c.FailureThreshold < 0 {
+		errs = append(errs, fmt.Errorf("checker config invalid 'failureThreshold': %d, must be 0 or greater", c.FailureThreshold))
+	}
+	if c.SuccessThreshold < 0 {
+		errs = append(errs, fmt.Errorf("checker config invalid 'successThreshold': %d, must be 0 or greater", c.SuccessThreshold))
+	}
 
 	switch c.Type {
 	case CheckTypeDNS:
@@ -62,6 +97,10 @@ func (c *CheckerConfig) validate() error {
 		if err := c.APIServerConfig.validate(c.Timeout); err != nil {
 			errs = append(errs, fmt.Errorf("checker config %q APIServerConfig validation failed: %w", c.Name, err))
 		}
+	case CheckTypeNodeProvisioning:
+		if err := c.NodeProvisioningConfig.validate(c.Name); err != nil {
+			errs = append(errs, fmt.Errorf("checker config %q NodeProvisioningConfig validation failed: %w", c.Name, err))
+		}
 	case CheckTypeAzurePolicy:
 		// There is no specific validation for AzurePolicyConfig as it does not have additional fields.
 	case CheckTypeMetricsServer:
@@ -86,13 +125,68 @@ func (c *DNSConfig) validate(checkerConfigTimeout time.Duration) error {
 		errs = append(errs, fmt.Errorf("queryTimeout must be greater than 0"))
 	}
 	switch c.Target {
-	case DNSCheckTargetCoreDNS, DNSCheckTargetLocalDNS, DNSCheckTargetCoreDNSPerPod:
+	case DNSCheckTargetCoreDNS, DNSCheckTargetLocalDNS, DNSCheckTargetCoreDNSPerPod, DNSCheckTargetCustom, DNSCheckTargetResolvConf:
 		// Valid check types for DNSChecker.
 	case "":
 		errs = append(errs, fmt.Errorf("target is required for DNSChecker"))
 	default:
 		errs = append(errs, fmt.Errorf("target %s is not valid for DNSChecker", c.Target))
 	}
+	if c.Target == DNSCheckTargetCustom && len(c.Nameservers) == 0 {
+		errs = append(errs, fmt.Errorf("nameservers are required for target %s", DNSCheckTargetCustom))
+	}
+	if c.Target != DNSCheckTargetCustom && len(c.Nameservers) > 0 {
+		errs = append(errs, fmt.Errorf("nameservers can only be set for target %s", DNSCheckTargetCustom))
+	}
+	for _, nameserver := range c.Nameservers {
+		if _, err := NameserverAddress(nameserver); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if c.Target != DNSCheckTargetCoreDNS && c.Target != DNSCheckTargetCoreDNSPerPod && (c.ServiceNamespace != "" || c.ServiceName != "") {
+		errs = append(errs, fmt.Errorf("serviceNamespace and serviceName can only be set for targets %s and %s", DNSCheckTargetCoreDNS,
+			DNSCheckTargetCoreDNSPerPod))
+	}
+	if c.ServiceNamespace != "" {
+		for _, nsErr := range utilvalidation.IsDNS1123Label(c.ServiceNamespace) {
+			errs = append(errs, fmt.Errorf("invalid serviceNamespace %q: %s", c.ServiceNamespace, nsErr))
+		}
+	}
+	if c.ServiceName != "" {
+		for _, svcErr := range utilvalidation.IsDNS1123Label(c.ServiceName) {
+			errs = append(errs, fmt.Errorf("invalid serviceName %q: %s", c.ServiceName, svcErr))
+		}
+	}
+	switch c.RecordType {
+	case "", DNSRecordTypeA, DNSRecordTypeAAAA, DNSRecordTypeSRV, DNSRecordTypeCNAME, DNSRecordTypePTR:
+		// Valid record types for DNSChecker.
+	default:
+		errs = append(errs, fmt.Errorf("recordType %s is not valid for DNSChecker", c.RecordType))
+	}
+	recordType := c.RecordType
+	if recordType == "" {
+		recordType = DNSRecordTypeA
+	}
+	for _, answer := range c.ExpectedAnswers {
+		if (recordType == DNSRecordTypeA || recordType == DNSRecordTypeAAAA) && net.ParseIP(answer) == nil {
+			errs = append(errs, fmt.Errorf("expectedAnswers must be IP addresses for record type %s: %q", recordType, answer))
+		}
+	}
+	if c.MinAnswers < 0 {
+		errs = append(errs, fmt.Errorf("minAnswers must be 0 or greater"))
+	}
+	switch c.Protocol {
+	case "", DNSProtocolUDP, DNSProtocolUDPOnly, DNSProtocolTCP, DNSProtocolUDPAndTCP:
+		// Valid protocols for DNSChecker.
+	default:
+		errs = append(errs, fmt.Errorf("protocol %s is not valid for DNSChecker", c.Protocol))
+	}
+	if c.EDNS0BufferSize != 0 && (c.EDNS0BufferSize < 512 || c.EDNS0BufferSize > 65535) {
+		errs = append(errs, fmt.Errorf("edns0BufferSize must be 0 or between 512 and 65535"))
+	}
+	if c.CheckAnswerConsistency && c.Target != DNSCheckTargetCoreDNSPerPod {
+		errs = append(errs, fmt.Errorf("checkAnswerConsistency can only be set for target %s", DNSCheckTargetCoreDNSPerPod))
+	}
 
 	if checkerConfigTimeout <= c.QueryTimeout {
 		errs = append(errs, fmt.Errorf("checker timeout must be greater than DNS query timeout: checker timeout='%s', DNS query timeout='%s'",
@@ -102,6 +196,22 @@ func (c *DNSConfig) validate(checkerConfigTimeout time.Duration) error {
 	return errors.Join(errs...)
 }
 
+// NameserverAddress returns the host:port address of a nameserver given as an IP address with an optional port. The port defaults to 53.
+func NameserverAddress(nameserver string) (string, error) {
+	host, port, err := net.SplitHostPort(nameserver)
+	if err != nil {
+		// No port, or an IPv6 address without brackets.
+		host, port = nameserver, "53"
+	}
+	if net.ParseIP(host) == nil {
+		return "", fmt.Errorf("nameserver %q must be an IP address with an optional port", nameserver)
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return "", fmt.Errorf("nameserver %q has an invalid port", nameserver)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
 func (c *PodStartupConfig) validate(checkerConfigTimeout time.Duration) error {
 	if c == nil {
 		return fmt.Errorf("pod startup checker config is required")
@@ -159,16 +269,10 @@ func (c *PodStartupConfig) validate(checkerConfigTimeout time.Duration) error {
 	}
 
 	seenStorageClasses := make(map[string]struct{})
+	seenVolumePaths := make(map[string]struct{})
 	for i, csi := range c.EnabledCSIs {
-		switch csi.Type {
-		case CSITypeAzureFile, CSITypeAzureDisk, CSITypeAzureBlob:
-			// valid CSI type
-		default:
-			errs = append(errs, fmt.Errorf("invalid csi type at index %d: value='%s'", i, csi.Type))
-		}
-
-		for _, scErr := range utilvalidation.IsDNS1123Subdomain(csi.StorageClass) {
-			errs = append(errs, fmt.Errorf("invalid csi storage class name at index %d: value='%s', error='%s'", i, csi.StorageClass, scErr))
+		if err := csi.validate(i); err != nil {
+			errs = append(errs, err)
 		}
 
 		if _, exists := seenStorageClasses[csi.StorageClass]; exists {
@@ -176,8 +280,287 @@ func (c *PodStartupConfig) validate(checkerConfigTimeout time.Duration) error {
 		} else {
 			seenStorageClasses[csi.StorageClass] = struct{}{}
 		}
+
+		if _, exists := seenVolumePaths[csi.VolumePath()]; exists {
+			errs = append(errs, fmt.Errorf("duplicate csi mount path at index %d: value='%s'", i, csi.VolumePath()))
+		} else {
+			seenVolumePaths[csi.VolumePath()] = struct{}{}
+		}
+	}
+
+	if c.VolumeIO != nil {
+		if err := c.VolumeIO.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid volume io: %w", err))
+		}
+		if len(c.EnabledCSIs) == 0 {
+			errs = append(errs, fmt.Errorf("volume io requires enabled csi"))
+		}
+		fileSize, fileSizeErr := resource.ParseQuantity(cmp.Or(c.VolumeIO.FileSize, DefaultVolumeIOFileSize))
+		for i, csi := range c.EnabledCSIs {
+			if csi.PVCAccessMode() == corev1.ReadOnlyMany {
+				errs = append(errs, fmt.Errorf("volume io cannot write to the ReadOnlyMany csi at index %d", i))
+			}
+			pvcSize, pvcSizeErr := resource.ParseQuantity(cmp.Or(csi.Size, DefaultCSISize))
+			if fileSizeErr == nil && pvcSizeErr == nil && fileSize.Cmp(pvcSize) > 0 {
+				errs = append(errs, fmt.Errorf("volume io file size exceeds the size of the csi at index %d: value='%s'", i, pvcSize.String()))
+			}
+		}
+	}
+
+	if strings.ContainsAny(c.Image, " \t\r\n") {
+		errs = append(errs, fmt.Errorf("invalid image: value='%s', must not contain whitespace", c.Image))
+	}
+	for i, secret := range c.ImagePullSecrets {
+		for _, secretErr := range utilvalidation.IsDNS1123Subdomain(secret) {
+			errs = append(errs, fmt.Errorf("invalid image pull secret name at index %d: value='%s', error='%s'", i, secret, secretErr))
+		}
+	}
+	if c.Port < 0 || c.Port > 65535 {
+		errs = append(errs, fmt.Errorf("invalid port: value=%d, must be between 1 and 65535", c.Port))
+	}
+	switch c.ImagePullPolicy {
+	case "", string(corev1.PullAlways), string(corev1.PullIfNotPresent), string(corev1.PullNever):
+		// valid image pull policy
+	default:
+		errs = append(errs, fmt.Errorf("invalid image pull policy: value='%s'", c.ImagePullPolicy))
+	}
+	if c.ImagePullThreshold < 0 {
+		errs = append(errs, fmt.Errorf("image pull threshold must be 0 or greater: value='%s'", c.ImagePullThreshold))
+	}
+
+	if c.PodTemplate != nil {
+		if err := c.PodTemplate.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid pod template: %w", err))
+		}
+	}
+	if c.NodePool != nil {
+		if err := c.NodePool.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid node pool: %w", err))
+		}
+		if !c.EnableNodeProvisioningTest {
+			errs = append(errs, fmt.Errorf("node pool can only be set when the node provisioning test is enabled"))
+		}
+	}
+
+	if c.Placement != nil {
+		if err := c.Placement.validate(c.MaxSyntheticPods); err != nil {
+			errs = append(errs, fmt.Errorf("invalid placement: %w", err))
+		}
+		// The CSI volumes and the NodePool of a run are shared by its synthetic pods, which does not work across node pools and zones.
+		if len(c.EnabledCSIs) > 0 {
+			errs = append(errs, fmt.Errorf("placement cannot be used with enabled csi"))
+		}
+		if c.EnableNodeProvisioningTest {
+			errs = append(errs, fmt.Errorf("placement cannot be used with the node provisioning test"))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate validates the storage probe at the given index of EnabledCSIs.
+func (c CSIConfig) validate(index int) error {
+	var errs []error
+	preset, isPreset := csiPresets[c.Type]
+	if c.Type != "" && !isPreset {
+		errs = append(errs, fmt.Errorf("invalid csi type at index %d: value='%s'", index, c.Type))
+	}
+	for _, scErr := range utilvalidation.IsDNS1123Subdomain(c.StorageClass) {
+		errs = append(errs, fmt.Errorf("invalid csi storage class name at index %d: value='%s', error='%s'", index, c.StorageClass, scErr))
+	}
+	switch corev1.PersistentVolumeAccessMode(c.AccessMode) {
+	case "", corev1.ReadWriteOnce, corev1.ReadWriteOncePod, corev1.ReadWriteMany, corev1.ReadOnlyMany:
+		// valid access mode
+	default:
+		errs = append(errs, fmt.Errorf("invalid csi access mode at index %d: value='%s'", index, c.AccessMode))
+	}
+	if c.Size != "" {
+		size, err := resource.ParseQuantity(c.Size)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid csi size at index %d: value='%s', error='%s'", index, c.Size, err))
+		} else if size.Sign() <= 0 {
+			errs = append(errs, fmt.Errorf("invalid csi size at index %d: value='%s', must be greater than 0", index, c.Size))
+		}
+	}
+	switch corev1.PersistentVolumeMode(c.VolumeMode) {
+	case "", corev1.PersistentVolumeFilesystem:
+		// valid volume mode
+	case corev1.PersistentVolumeBlock:
+		if isPreset && !preset.block {
+			errs = append(errs, fmt.Errorf("csi volume mode Block is not supported by type %s at index %d", c.Type, index))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid csi volume mode at index %d: value='%s'", index, c.VolumeMode))
+	}
+	if c.MountPath != "" && (!path.IsAbs(c.MountPath) || path.Clean(c.MountPath) != c.MountPath || c.MountPath == "/") {
+		errs = append(errs, fmt.Errorf("invalid csi mount path at index %d: value='%s', must be a clean absolute path other than /", index, c.MountPath))
+	}
+	if c.ProvisioningThreshold < 0 {
+		errs = append(errs, fmt.Errorf("csi provisioning threshold must be 0 or greater at index %d: value='%s'", index, c.ProvisioningThreshold))
+	}
+	if c.AttachThreshold < 0 {
+		errs = append(errs, fmt.Errorf("csi attach threshold must be 0 or greater at index %d: value='%s'", index, c.AttachThreshold))
+	}
+	if c.MountThreshold < 0 {
+		errs = append(errs, fmt.Errorf("csi mount threshold must be 0 or greater at index %d: value='%s'", index, c.MountThreshold))
+	}
+	return errors.Join(errs...)
+}
+
+func (c *VolumeIOConfig) validate() error {
+	var errs []error
+	if c.FileSize != "" {
+		size, err := resource.ParseQuantity(c.FileSize)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid file size: value='%s', error='%s'", c.FileSize, err))
+		} else if size.Sign() <= 0 || size.Cmp(resource.MustParse(MaxVolumeIOFileSize)) > 0 {
+			errs = append(errs, fmt.Errorf("invalid file size: value='%s', must be greater than 0 and at most %s", c.FileSize, MaxVolumeIOFileSize))
+		}
+	}
+	if c.Threshold < 0 {
+		errs = append(errs, fmt.Errorf("threshold must be 0 or greater: value='%s'", c.Threshold))
+	}
+	return errors.Join(errs...)
+}
+
+func (t *SyntheticPodTemplate) validate() error {
+	var errs []error
+	for key, value := range t.NodeSelector {
+		for _, keyErr := range utilvalidation.IsQualifiedName(key) {
+			errs = append(errs, fmt.Errorf("invalid node selector key: value='%s', error='%s'", key, keyErr))
+		}
+		for _, valueErr := range utilvalidation.IsValidLabelValue(value) {
+			errs = append(errs, fmt.Errorf("invalid node selector value for key %s: value='%s', error='%s'", key, value, valueErr))
+		}
+	}
+	for i, toleration := range t.Tolerations {
+		if toleration.Key != "" {
+			for _, keyErr := range utilvalidation.IsQualifiedName(toleration.Key) {
+				errs = append(errs, fmt.Errorf("invalid toleration key at index %d: value='%s', error='%s'", i, toleration.Key, keyErr))
+			}
+		}
+		switch toleration.Operator {
+		case "", corev1.TolerationOpEqual:
+			// valid toleration operator
+		case corev1.TolerationOpExists:
+			if toleration.Value != "" {
+				errs = append(errs, fmt.Errorf("toleration value must be empty when operator is Exists at index %d: value='%s'", i, toleration.Value))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("invalid toleration operator at index %d: value='%s'", i, toleration.Operator))
+		}
+		switch toleration.Effect {
+		case "", corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute:
+			// valid toleration effect
+		default:
+			errs = append(errs, fmt.Errorf("invalid toleration effect at index %d: value='%s'", i, toleration.Effect))
+		}
+	}
+	if t.PriorityClassName != "" {
+		for _, nameErr := range utilvalidation.IsDNS1123Subdomain(t.PriorityClassName) {
+			errs = append(errs, fmt.Errorf("invalid priority class name: value='%s', error='%s'", t.PriorityClassName, nameErr))
+		}
+	}
+	if t.RuntimeClassName != "" {
+		for _, nameErr := range utilvalidation.IsDNS1123Subdomain(t.RuntimeClassName) {
+			errs = append(errs, fmt.Errorf("invalid runtime class name: value='%s', error='%s'", t.RuntimeClassName, nameErr))
+		}
 	}
+	return errors.Join(errs...)
+}
 
+func (c *NodeProvisioningConfig) validate(checkerName string) error {
+	if c == nil {
+		return fmt.Errorf("node provisioning checker config is required")
+	}
+
+	var errs []error
+	// The checker name is the value of the checker label and the taint of the NodePools, and names the NodePools and trigger pods
+	// together with a timestamp suffix.
+	for _, valueErr := range utilvalidation.IsValidLabelValue(checkerName) {
+		errs = append(errs, fmt.Errorf("invalid checker name for labels and taints: value='%s', error='%s'", checkerName, valueErr))
+	}
+	for _, nameErr := range utilvalidation.IsDNS1123Subdomain(fmt.Sprintf("%s-nodepool-%d", strings.ToLower(checkerName), int64(math.MaxInt64))) {
+		errs = append(errs, fmt.Errorf("invalid checker name for NodePool and pod names: value='%s', error='%s'", checkerName, nameErr))
+	}
+	for _, nsErr := range apivalidation.ValidateNamespaceName(c.Namespace, false) {
+		errs = append(errs, fmt.Errorf("invalid namespace: value='%s', error='%s'", c.Namespace, nsErr))
+	}
+	for _, labelErr := range utilvalidation.IsQualifiedName(c.LabelKey) {
+		errs = append(errs, fmt.Errorf("invalid label key: value='%s', error='%s'", c.LabelKey, labelErr))
+	}
+	if c.MaxNodePools <= 0 {
+		errs = append(errs, fmt.Errorf("invalid max node pools: value=%d, must be greater than 0", c.MaxNodePools))
+	}
+	if c.NodePool != nil {
+		if err := c.NodePool.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid node pool: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *NodePoolConfig) validate() error {
+	var errs []error
+	if ref := c.NodeClassRef; ref != nil {
+		for _, groupErr := range utilvalidation.IsDNS1123Subdomain(ref.Group) {
+			errs = append(errs, fmt.Errorf("invalid node class group: value='%s', error='%s'", ref.Group, groupErr))
+		}
+		if ref.Kind == "" {
+			errs = append(errs, fmt.Errorf("node class kind is required"))
+		}
+		for _, nameErr := range utilvalidation.IsDNS1123Subdomain(ref.Name) {
+			errs = append(errs, fmt.Errorf("invalid node class name: value='%s', error='%s'", ref.Name, nameErr))
+		}
+	}
+	for i, requirement := range c.Requirements {
+		for _, keyErr := range utilvalidation.IsQualifiedName(requirement.Key) {
+			errs = append(errs, fmt.Errorf("invalid requirement key at index %d: value='%s', error='%s'", i, requirement.Key, keyErr))
+		}
+		switch corev1.NodeSelectorOperator(requirement.Operator) {
+		case corev1.NodeSelectorOpIn, corev1.NodeSelectorOpNotIn:
+			if len(requirement.Values) == 0 {
+				errs = append(errs, fmt.Errorf("requirement values are required for operator %s at index %d", requirement.Operator, i))
+			}
+		case corev1.NodeSelectorOpExists, corev1.NodeSelectorOpDoesNotExist:
+			if len(requirement.Values) > 0 {
+				errs = append(errs, fmt.Errorf("requirement values are not allowed for operator %s at index %d", requirement.Operator, i))
+			}
+		case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+			if len(requirement.Values) != 1 {
+				errs = append(errs, fmt.Errorf("requirement must have a single value for operator %s at index %d", requirement.Operator, i))
+			} else if _, err := strconv.Atoi(requirement.Values[0]); err != nil {
+				errs = append(errs, fmt.Errorf("requirement value must be an integer for operator %s at index %d: value='%s'",
+					requirement.Operator, i, requirement.Values[0]))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("invalid requirement operator at index %d: value='%s'", i, requirement.Operator))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *PlacementConfig) validate(maxSyntheticPods int) error {
+	var errs []error
+	switch c.By {
+	case PlacementByNodePool, PlacementByZone:
+		if c.NodeLabelKey != "" {
+			errs = append(errs, fmt.Errorf("nodeLabelKey can only be set when placing by %s", PlacementByNodeLabel))
+		}
+	case PlacementByNodeLabel:
+		for _, labelErr := range utilvalidation.IsQualifiedName(c.NodeLabelKey) {
+			errs = append(errs, fmt.Errorf("invalid node label key: value='%s', error='%s'", c.NodeLabelKey, labelErr))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("invalid placement by: value='%s'", c.By))
+	}
+	if c.MaxConcurrency < 0 {
+		errs = append(errs, fmt.Errorf("max concurrency must be 0 or greater: value=%d", c.MaxConcurrency))
+	}
+	if c.Concurrency() > maxSyntheticPods {
+		errs = append(errs, fmt.Errorf("max concurrency must not exceed max synthetic pods: value=%d, max synthetic pods=%d",
+			c.MaxConcurrency, maxSyntheticPods))
+	}
 	return errors.Join(errs...)
 }
 
@@ -1,10 +1,12 @@
 package config
 
 import (
+	"strings"
 	"testing"
 	"time"
 
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestConfigValidate_Valid(t *testing.T) {
@@ -71,6 +73,14 @@ func TestCheckerConfigValidate_MissingFields(t *testing.T) {
 	g.Expect(err.Error()).To(ContainSubstring("invalid 'timeout'"))
 }
 
+func TestCheckerConfigValidate_NameTooLong(t *testing.T) {
+	g := NewWithT(t)
+	chk := CheckerConfig{Name: strings.Repeat("a", 254), Type: CheckTypeDNS, Interval: 1, Timeout: 1}
+	err := chk.validate()
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("invalid 'name'"))
+}
+
 func TestCheckerConfigValidate_UnsupportedType(t *testing.T) {
 	g := NewWithT(t)
 	chk := CheckerConfig{Name: "foo", Type: "badtype", Interval: 1, Timeout: 1}
@@ -79,6 +89,137 @@ func TestCheckerConfigValidate_UnsupportedType(t *testing.T) {
 	g.Expect(err.Error()).To(ContainSubstring("unsupported type"))
 }
 
+func TestCheckerConfigValidate_OptionalFields(t *testing.T) {
+	tests := []struct {
+		name         string
+		mutateConfig func(cfg *CheckerConfig)
+		validateRes  func(g *WithT, err error)
+	}{
+		{
+			name: "valid interval with initial delay and jitter",
+			mutateConfig: func(cfg *CheckerConfig) {
+				cfg.InitialDelay = 5 * time.Second
+				cfg.JitterPercent = 20
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "valid cron schedule",
+			mutateConfig: func(cfg *CheckerConfig) {
+				cfg.Interval = 0
+				cfg.Schedule = "CRON_TZ=UTC 0 2 * * *"
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "valid cron descriptor",
+			mutateConfig: func(cfg *CheckerConfig) {
+				cfg.Interval = 0
+				cfg.Schedule = "@hourly"
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "interval and schedule both set",
+			mutateConfig: func(cfg *CheckerConfig) {
+				cfg.Schedule = "@hourly"
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("'interval' and 'schedule' are mutually exclusive"))
+			},
+		},
+		{
+			name: "invalid cron schedule",
+			mutateConfig: func(cfg *CheckerConfig) {
+				cfg.Interval = 0
+				cfg.Schedule = "every day"
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("invalid 'schedule'"))
+			},
+		},
+		{
+			name: "cron schedule that never matches",
+			mutateConfig: func(cfg *CheckerConfig) {
+				cfg.Interval = 0
+				cfg.Schedule = "0 0 30 2 *"
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("schedule never matches"))
+			},
+		},
+		{
+			name: "negative initial delay",
+			mutateConfig: func(cfg *CheckerConfig) {
+				cfg.InitialDelay = -1 * time.Second
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("invalid 'initialDelay'"))
+			},
+		},
+		{
+			name: "valid failure and success thresholds",
+			mutateConfig: func(cfg *CheckerConfig) {
+				cfg.FailureThreshold = 3
+				cfg.SuccessThreshold = 2
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "negative failure and success thresholds",
+			mutateConfig: func(cfg *CheckerConfig) {
+				cfg.FailureThreshold = -1
+				cfg.SuccessThreshold = -1
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("invalid 'failureThreshold'"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid 'successThreshold'"))
+			},
+		},
+		{
+			name: "jitter percent out of range",
+			mutateConfig: func(cfg *CheckerConfig) {
+				cfg.JitterPercent = 101
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("invalid 'jitterPercent'"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			chkCfg := &CheckerConfig{
+				Name:     "test",
+				Type:     CheckTypeMetricsServer,
+				Timeout:  10 * time.Second,
+				Interval: 30 * time.Second,
+			}
+			tt.mutateConfig(chkCfg)
+
+			err := chkCfg.validate()
+			tt.validateRes(g, err)
+		})
+	}
+}
+
 func TestPodStartupConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -271,6 +412,206 @@ func TestPodStartupConfig_Validate(t *testing.T) {
 				g.Expect(err).ToNot(HaveOccurred())
 			},
 		},
+		{
+			name: "valid custom image, pull secrets, port, pull policy and pull threshold",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.Image = "registry.example.com/nginx:latest"
+				cfg.PodStartupConfig.ImagePullSecrets = []string{"registry-secret"}
+				cfg.PodStartupConfig.Port = 8080
+				cfg.PodStartupConfig.ImagePullPolicy = "Always"
+				cfg.PodStartupConfig.ImagePullThreshold = 10 * time.Second
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "invalid image, pull secret, port, pull policy and pull threshold",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.Image = "registry.example.com/nginx latest"
+				cfg.PodStartupConfig.ImagePullSecrets = []string{"Registry_Secret"}
+				cfg.PodStartupConfig.Port = 70000
+				cfg.PodStartupConfig.ImagePullPolicy = "Sometimes"
+				cfg.PodStartupConfig.ImagePullThreshold = -1 * time.Second
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("invalid image"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid image pull secret name at index 0"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid port"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid image pull policy"))
+				g.Expect(err.Error()).To(ContainSubstring("image pull threshold must be 0 or greater"))
+			},
+		},
+		{
+			name: "valid placement by node label",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.Placement = &PlacementConfig{
+					By:             PlacementByNodeLabel,
+					NodeLabelKey:   "example.com/rack",
+					MaxConcurrency: 3,
+				}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "invalid placement",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.Placement = &PlacementConfig{
+					By:             PlacementByZone,
+					NodeLabelKey:   "example.com/rack",
+					MaxConcurrency: 4,
+				}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("nodeLabelKey can only be set when placing by NodeLabel"))
+				g.Expect(err.Error()).To(ContainSubstring("max concurrency must not exceed max synthetic pods"))
+			},
+		},
+		{
+			name: "invalid placement by and node label key",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.Placement = &PlacementConfig{By: "Rack", MaxConcurrency: -1}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("invalid placement by"))
+				g.Expect(err.Error()).To(ContainSubstring("max concurrency must be 0 or greater"))
+			},
+		},
+		{
+			name: "placement with csi and node provisioning test is not allowed",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.Placement = &PlacementConfig{By: PlacementByNodePool}
+				cfg.PodStartupConfig.EnabledCSIs = []CSIConfig{{Type: CSITypeAzureFile}}
+				cfg.PodStartupConfig.EnableNodeProvisioningTest = true
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("placement cannot be used with enabled csi"))
+				g.Expect(err.Error()).To(ContainSubstring("placement cannot be used with the node provisioning test"))
+			},
+		},
+		{
+			name: "valid pod template and node pool",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.PodTemplate = &SyntheticPodTemplate{
+					NodeSelector:      map[string]string{"node-role.kubernetes.io/worker": ""},
+					Tolerations:       []corev1.Toleration{{Key: "dedicated", Operator: corev1.TolerationOpExists}},
+					PriorityClassName: "system-cluster-critical",
+					RuntimeClassName:  "kata",
+				}
+				cfg.PodStartupConfig.EnableNodeProvisioningTest = true
+				cfg.PodStartupConfig.NodePool = &NodePoolConfig{
+					NodeClassRef: &NodeClassRef{Group: "karpenter.k8s.aws", Kind: "EC2NodeClass", Name: "default"},
+					Requirements: []NodeRequirement{
+						{Key: "kubernetes.io/arch", Operator: "In", Values: []string{"arm64"}},
+						{Key: "karpenter.k8s.aws/instance-cpu", Operator: "Gt", Values: []string{"2"}},
+					},
+				}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "invalid pod template",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.PodTemplate = &SyntheticPodTemplate{
+					NodeSelector: map[string]string{"invalid key": "invalid value!"},
+					Tolerations: []corev1.Toleration{
+						{Key: "dedicated", Operator: corev1.TolerationOpExists, Value: "true"},
+						{Key: "dedicated", Operator: "Matches", Effect: "NoRun"},
+					},
+					PriorityClassName: "Critical",
+					RuntimeClassName:  "kata_runtime",
+				}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("invalid pod template"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid node selector key"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid node selector value for key invalid key"))
+				g.Expect(err.Error()).To(ContainSubstring("toleration value must be empty when operator is Exists at index 0"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid toleration operator at index 1"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid toleration effect at index 1"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid priority class name"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid runtime class name"))
+			},
+		},
+		{
+			name: "invalid node pool",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.NodePool = &NodePoolConfig{
+					NodeClassRef: &NodeClassRef{Group: "karpenter.azure.com"},
+					Requirements: []NodeRequirement{
+						{Key: "kubernetes.io/arch", Operator: "In"},
+						{Key: "kubernetes.io/arch", Operator: "Exists", Values: []string{"amd64"}},
+						{Key: "karpenter.azure.com/sku-cpu", Operator: "Lt", Values: []string{"many"}},
+						{Key: "kubernetes.io/arch", Operator: "Equals"},
+					},
+				}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("node class kind is required"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid node class name"))
+				g.Expect(err.Error()).To(ContainSubstring("requirement values are required for operator In at index 0"))
+				g.Expect(err.Error()).To(ContainSubstring("requirement values are not allowed for operator Exists at index 1"))
+				g.Expect(err.Error()).To(ContainSubstring("requirement value must be an integer for operator Lt at index 2"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid requirement operator at index 3"))
+				g.Expect(err.Error()).To(ContainSubstring("node pool can only be set when the node provisioning test is enabled"))
+			},
+		},
+		{
+			name: "valid volume io",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.EnabledCSIs = []CSIConfig{{Type: CSITypeAzureDisk, StorageClass: "managed-csi"}}
+				cfg.PodStartupConfig.VolumeIO = &VolumeIOConfig{FileSize: "16Mi", Threshold: 5 * time.Second}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "invalid volume io",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.VolumeIO = &VolumeIOConfig{FileSize: "2Gi", Threshold: -1 * time.Second}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("invalid volume io"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid file size: value='2Gi', must be greater than 0 and at most 1Gi"))
+				g.Expect(err.Error()).To(ContainSubstring("threshold must be 0 or greater"))
+				g.Expect(err.Error()).To(ContainSubstring("volume io requires enabled csi"))
+			},
+		},
+		{
+			name: "volume io file size is not a quantity",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.EnabledCSIs = []CSIConfig{{Type: CSITypeAzureDisk, StorageClass: "managed-csi"}}
+				cfg.PodStartupConfig.VolumeIO = &VolumeIOConfig{FileSize: "one megabyte"}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("invalid file size: value='one megabyte'"))
+			},
+		},
 		{
 			name: "csi field present but empty is not allowed",
 			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
@@ -349,6 +690,85 @@ func TestPodStartupConfig_Validate(t *testing.T) {
 				g.Expect(err.Error()).To(ContainSubstring("invalid csi storage class name"))
 			},
 		},
+		{
+			name: "valid generic csi probes",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.EnabledCSIs = []CSIConfig{
+					{StorageClass: "local-path", AccessMode: "ReadWriteOncePod", Size: "1Gi", MountPath: "/data"},
+					{StorageClass: "nfs-client", AccessMode: "ReadWriteMany"},
+					{Type: CSITypeAzureDisk, StorageClass: "managed-csi", VolumeMode: "Block"},
+				}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).NotTo(HaveOccurred())
+			},
+		},
+		{
+			name: "invalid generic csi probes",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.EnabledCSIs = []CSIConfig{
+					{StorageClass: "local-path", AccessMode: "ReadWriteAll", Size: "0", VolumeMode: "Raw"},
+					{StorageClass: "nfs-client", Size: "lots", MountPath: "data/"},
+					{Type: CSITypeAzureFile, StorageClass: "azurefile-csi", VolumeMode: "Block"},
+					{StorageClass: "other", MountPath: "/mnt/local-path"},
+				}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("invalid csi access mode at index 0: value='ReadWriteAll'"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid csi size at index 0: value='0', must be greater than 0"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid csi volume mode at index 0: value='Raw'"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid csi size at index 1: value='lots'"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid csi mount path at index 1: value='data/'"))
+				g.Expect(err.Error()).To(ContainSubstring("csi volume mode Block is not supported by type azureFile at index 2"))
+				g.Expect(err.Error()).To(ContainSubstring("duplicate csi mount path at index 3: value='/mnt/local-path'"))
+			},
+		},
+		{
+			name: "volume io does not fit in or cannot write to csi",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.EnabledCSIs = []CSIConfig{
+					{StorageClass: "local-path", Size: "256Mi"},
+					{StorageClass: "nfs-client", AccessMode: "ReadOnlyMany"},
+				}
+				cfg.PodStartupConfig.VolumeIO = &VolumeIOConfig{FileSize: "512Mi"}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("volume io file size exceeds the size of the csi at index 0: value='256Mi'"))
+				g.Expect(err.Error()).To(ContainSubstring("volume io cannot write to the ReadOnlyMany csi at index 1"))
+			},
+		},
+		{
+			name: "valid csi storage operation thresholds",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.EnabledCSIs = []CSIConfig{
+					{StorageClass: "managed-csi", ProvisioningThreshold: 30 * time.Second, AttachThreshold: 20 * time.Second, MountThreshold: 10 * time.Second},
+				}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "negative csi storage operation thresholds",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.PodStartupConfig.EnabledCSIs = []CSIConfig{
+					{StorageClass: "managed-csi", ProvisioningThreshold: -1 * time.Second, AttachThreshold: -2 * time.Second, MountThreshold: -3 * time.Second},
+				}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("csi provisioning threshold must be 0 or greater at index 0: value='-1s'"))
+				g.Expect(err.Error()).To(ContainSubstring("csi attach threshold must be 0 or greater at index 0: value='-2s'"))
+				g.Expect(err.Error()).To(ContainSubstring("csi mount threshold must be 0 or greater at index 0: value='-3s'"))
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -517,6 +937,119 @@ func TestAPIServerConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestNodeProvisioningConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		mutateConfig func(cfg *CheckerConfig) *CheckerConfig
+		validateRes  func(g *WithT, err error)
+	}{
+		{
+			name: "valid config",
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "nil node provisioning config",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.NodeProvisioningConfig = nil
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("node provisioning checker config is required"))
+			},
+		},
+		{
+			name: "invalid namespace, label key and max node pools",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.NodeProvisioningConfig.Namespace = ""
+				cfg.NodeProvisioningConfig.LabelKey = "invalid key"
+				cfg.NodeProvisioningConfig.MaxNodePools = 0
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("invalid namespace"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid label key: value='invalid key'"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid max node pools: value=0, must be greater than 0"))
+			},
+		},
+		{
+			name: "mixed case checker name",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.Name = "NodeProvisioning.Default"
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "checker name too long for a label value",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.Name = strings.Repeat("a", 64)
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("invalid checker name for labels and taints"))
+			},
+		},
+		{
+			name: "checker name that cannot name a NodePool",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.Name = "node_provisioning"
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).ToNot(ContainSubstring("invalid checker name for labels and taints"))
+				g.Expect(err.Error()).To(ContainSubstring("invalid checker name for NodePool and pod names: value='node_provisioning'"))
+			},
+		},
+		{
+			name: "invalid node pool",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.NodeProvisioningConfig.NodePool = &NodePoolConfig{
+					Requirements: []NodeRequirement{{Key: "kubernetes.io/arch", Operator: "Equals", Values: []string{"amd64"}}},
+				}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("invalid node pool: invalid requirement operator at index 0: value='Equals'"))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			chkCfg := &CheckerConfig{
+				Name:     "test",
+				Type:     CheckTypeNodeProvisioning,
+				Timeout:  10 * time.Minute,
+				Interval: 30 * time.Minute,
+				NodeProvisioningConfig: &NodeProvisioningConfig{
+					Namespace:    "kube-system",
+					LabelKey:     "cluster-health-monitor.azure.com/checker-node-provisioning",
+					MaxNodePools: 2,
+				},
+			}
+
+			if tt.mutateConfig != nil {
+				chkCfg = tt.mutateConfig(chkCfg)
+			}
+
+			err := chkCfg.validate()
+			tt.validateRes(g, err)
+		})
+	}
+}
+
 func TestDNSConfig_Validate(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -619,6 +1152,210 @@ func TestDNSConfig_Validate(t *testing.T) {
 				g.Expect(err.Error()).To(ContainSubstring("target invalidTarget is not valid for DNSChecker"))
 			},
 		},
+		{
+			name: "valid record type and expected answers",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.RecordType = DNSRecordTypeSRV
+				cfg.DNSConfig.ExpectedAnswers = []string{"kube-dns.kube-system.svc.cluster.local."}
+				cfg.DNSConfig.MinAnswers = 2
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "DNS config record type is invalid",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.RecordType = "MX"
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("recordType MX is not valid for DNSChecker"))
+			},
+		},
+		{
+			name: "expected answer is not an IP address for A records",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.ExpectedAnswers = []string{"example.com"}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(`expectedAnswers must be IP addresses for record type A: "example.com"`))
+			},
+		},
+		{
+			name: "negative minAnswers",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.MinAnswers = -1
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("minAnswers must be 0 or greater"))
+			},
+		},
+		{
+			name: "valid custom nameservers",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.Target = DNSCheckTargetCustom
+				cfg.DNSConfig.Nameservers = []string{"168.63.129.16", "10.0.0.53:5353", "fd00::10", "[fd00::10]:53"}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "custom target without nameservers",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.Target = DNSCheckTargetCustom
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("nameservers are required for target Custom"))
+			},
+		},
+		{
+			name: "nameservers with CoreDNS target",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.Nameservers = []string{"168.63.129.16"}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("nameservers can only be set for target Custom"))
+			},
+		},
+		{
+			name: "invalid nameservers",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.Target = DNSCheckTargetCustom
+				cfg.DNSConfig.Nameservers = []string{"dns.example.com", "10.0.0.53:0", "10.0.0.53:dns"}
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(`nameserver "dns.example.com" must be an IP address with an optional port`))
+				g.Expect(err.Error()).To(ContainSubstring(`nameserver "10.0.0.53:0" has an invalid port`))
+				g.Expect(err.Error()).To(ContainSubstring(`nameserver "10.0.0.53:dns" has an invalid port`))
+			},
+		},
+		{
+			name: "valid CoreDNS service",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.ServiceNamespace = "dns-system"
+				cfg.DNSConfig.ServiceName = "coredns"
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "invalid CoreDNS service",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.ServiceNamespace = "DNS_System"
+				cfg.DNSConfig.ServiceName = "core.dns"
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(`invalid serviceNamespace "DNS_System"`))
+				g.Expect(err.Error()).To(ContainSubstring(`invalid serviceName "core.dns"`))
+			},
+		},
+		{
+			name: "CoreDNS service with LocalDNS target",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.Target = DNSCheckTargetLocalDNS
+				cfg.DNSConfig.ServiceName = "coredns"
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("serviceNamespace and serviceName can only be set for targets CoreDNS and CoreDNSPerPod"))
+			},
+		},
+		{
+			name: "valid protocol and EDNS0 buffer size",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.Protocol = DNSProtocolUDPAndTCP
+				cfg.DNSConfig.EDNS0BufferSize = 4096
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "valid UDP-only protocol",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.Protocol = DNSProtocolUDPOnly
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "invalid protocol",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.Protocol = "QUIC"
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("protocol QUIC is not valid for DNSChecker"))
+			},
+		},
+		{
+			name: "EDNS0 buffer size too small",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.EDNS0BufferSize = 100
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("edns0BufferSize must be 0 or between 512 and 65535"))
+			},
+		},
+		{
+			name: "valid ResolvConf target",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.Target = DNSCheckTargetResolvConf
+				cfg.DNSConfig.Domain = "kubernetes.default"
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "answer consistency with CoreDNSPerPod target",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.Target = DNSCheckTargetCoreDNSPerPod
+				cfg.DNSConfig.CheckAnswerConsistency = true
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+			},
+		},
+		{
+			name: "answer consistency with CoreDNS target",
+			mutateConfig: func(cfg *CheckerConfig) *CheckerConfig {
+				cfg.DNSConfig.CheckAnswerConsistency = true
+				return cfg
+			},
+			validateRes: func(g *WithT, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring("checkAnswerConsistency can only be set for target CoreDNSPerPod"))
+			},
+		},
 	}
 
 	for _, tt := range tests {
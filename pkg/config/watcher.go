@@ -0,0 +1,136 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/klog/v2"
+)
+
+// Watcher periodically re-reads a configuration file and reports every change of its content. Polling is used instead of file system
+// notifications because ConfigMap volumes are updated by atomically swapping a symlink, which inotify based watchers do not reliably
+// observe.
+type Watcher struct {
+	path     string
+	interval time.Duration
+	// maxRetryBackoff caps the delay between attempts to apply content that onChange failed to apply.
+	maxRetryBackoff time.Duration
+	// data is the content of the file as of the last load, regardless of whether that content was valid.
+	data []byte
+	// retryBackoff is the delay before the content is reported again because onChange failed to apply it. It is 0 if the content was
+	// applied.
+	retryBackoff time.Duration
+	// retryAt is when the content is reported again because onChange failed to apply it.
+	retryAt time.Time
+}
+
+// defaultMaxRetryBackoff is the default maximum delay between attempts to apply content that onChange failed to apply.
+const defaultMaxRetryBackoff = 5 * time.Minute
+
+// NewWatcher creates a Watcher for the configuration file at path which checks the file for changes every interval.
+func NewWatcher(path string, interval time.Duration) *Watcher {
+	return &Watcher{
+		path:            path,
+		interval:        interval,
+		maxRetryBackoff: defaultMaxRetryBackoff,
+	}
+}
+
+// Load reads and parses the configuration file. The loaded content becomes the baseline against which Watch detects changes.
+func (w *Watcher) Load() (*Config, error) {
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %q: %w", w.path, err)
+	}
+	w.data = data
+	return ParseFromYAML(data)
+}
+
+// Watch polls the configuration file until the context is cancelled. Each time the file content changes, onChange is called with the
+// newly parsed configuration, or with an error if the file cannot be read or the new configuration is invalid. onChange is called from
+// a single goroutine, so calls never overlap. If onChange returns an error, e.g. because the configuration could not be fully applied,
+// the same content is reported again after a backoff that starts at the poll interval and doubles up to a maximum, unless the content
+// changes in the meantime.
+func (w *Watcher) Watch(ctx context.Context, onChange func(cfg *Config, err error) error) error {
+	err := wait.PollUntilContextCancel(ctx, w.interval, false, func(ctx context.Context) (bool, error) {
+		data, err := os.ReadFile(w.path)
+		if err != nil {
+			// Transient read errors are expected while the kubelet swaps the ConfigMap symlink, so they are only logged.
+			klog.V(3).InfoS("Failed to read config file", "path", w.path, "error", err)
+			return false, nil
+		}
+		if bytes.Equal(data, w.data) {
+			if w.retryBackoff == 0 || time.Now().Before(w.retryAt) {
+				return false, nil
+			}
+		} else {
+			w.data = data
+			w.retryBackoff = 0
+		}
+
+		cfg, err := ParseFromYAML(data)
+		if err := onChange(cfg, err); err != nil {
+			w.retryBackoff = min(max(2*w.retryBackoff, w.interval), w.maxRetryBackoff)
+			w.retryAt = time.Now().Add(w.retryBackoff)
+			klog.V(3).InfoS("Failed to apply config file, retrying", "path", w.path, "backoff", w.retryBackoff, "error", err)
+			return false, nil
+		}
+		w.retryBackoff = 0
+		return false, nil
+	})
+	return err
+}
+
+// CheckerDiff describes how the checkers of two configurations differ. Checkers are matched by name.
+type CheckerDiff struct {
+	// Added holds checkers present only in the new configuration.
+	Added []CheckerConfig
+	// Removed holds checkers present only in the old configuration.
+	Removed []CheckerConfig
+	// Changed holds the new configuration of checkers present in both configurations with different settings.
+	Changed []CheckerConfig
+}
+
+// IsEmpty returns true if the two configurations have identical checkers.
+func (d CheckerDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// DiffCheckers compares the checkers of oldCfg and newCfg by name. A nil configuration is treated as having no checkers.
+func DiffCheckers(oldCfg, newCfg *Config) CheckerDiff {
+	oldCheckers := make(map[string]CheckerConfig)
+	if oldCfg != nil {
+		for _, chk := range oldCfg.Checkers {
+			oldCheckers[chk.Name] = chk
+		}
+	}
+
+	var diff CheckerDiff
+	newNames := make(map[string]struct{})
+	if newCfg != nil {
+		for _, chk := range newCfg.Checkers {
+			newNames[chk.Name] = struct{}{}
+			oldChk, exists := oldCheckers[chk.Name]
+			if !exists {
+				diff.Added = append(diff.Added, chk)
+				continue
+			}
+			if !reflect.DeepEqual(oldChk, chk) {
+				diff.Changed = append(diff.Changed, chk)
+			}
+		}
+	}
+	if oldCfg != nil {
+		for _, chk := range oldCfg.Checkers {
+			if _, exists := newNames[chk.Name]; !exists {
+				diff.Removed = append(diff.Removed, chk)
+			}
+		}
+	}
+	return diff
+}
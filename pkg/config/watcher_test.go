@@ -0,0 +1,265 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+const watcherTestConfig = `
+checkers:
+  - name: dns1
+    type: DNS
+    interval: 10s
+    timeout: 5s
+    dnsConfig:
+      domain: example.com
+      queryTimeout: 2s
+      target: CoreDNS
+`
+
+func TestWatcher_Watch(t *testing.T) {
+	testCases := []struct {
+		name     string
+		newData  string
+		validate func(g *WithT, cfg *Config, err error)
+	}{
+		{
+			name: "valid config change is reported",
+			newData: `
+checkers:
+  - name: dns1
+    type: DNS
+    interval: 20s
+    timeout: 5s
+    dnsConfig:
+      domain: example.com
+      queryTimeout: 2s
+      target: CoreDNS
+`,
+			validate: func(g *WithT, cfg *Config, err error) {
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(cfg.Checkers).To(HaveLen(1))
+				g.Expect(cfg.Checkers[0].Interval).To(Equal(20 * time.Second))
+			},
+		},
+		{
+			name:    "invalid config change is reported as error",
+			newData: `checkers: []`,
+			validate: func(g *WithT, cfg *Config, err error) {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(cfg).To(BeNil())
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+
+			path := filepath.Join(t.TempDir(), "config.yaml")
+			g.Expect(os.WriteFile(path, []byte(watcherTestConfig), 0600)).To(Succeed())
+
+			w := NewWatcher(path, 10*time.Millisecond)
+			cfg, err := w.Load()
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(cfg.Checkers).To(HaveLen(1))
+
+			type change struct {
+				cfg *Config
+				err error
+			}
+			changes := make(chan change, 10)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				_ = w.Watch(ctx, func(cfg *Config, err error) error {
+					changes <- change{cfg: cfg, err: err}
+					return nil
+				})
+			}()
+
+			// No change is reported while the file content is unchanged.
+			g.Consistently(changes, 50*time.Millisecond).ShouldNot(Receive())
+
+			g.Expect(os.WriteFile(path, []byte(tc.newData), 0600)).To(Succeed())
+			var got change
+			g.Eventually(changes, time.Second).Should(Receive(&got))
+			tc.validate(g, got.cfg, got.err)
+
+			// The same content is not reported twice.
+			g.Consistently(changes, 50*time.Millisecond).ShouldNot(Receive())
+		})
+	}
+}
+
+func TestWatcher_Watch_Retry(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	g.Expect(os.WriteFile(path, []byte(watcherTestConfig), 0600)).To(Succeed())
+
+	w := NewWatcher(path, 10*time.Millisecond)
+	_, err := w.Load()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	calls := make(chan *Config, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	failures := 2
+	go func() {
+		_ = w.Watch(ctx, func(cfg *Config, err error) error {
+			calls <- cfg
+			if failures > 0 {
+				failures--
+				return errors.New("failed to apply")
+			}
+			return nil
+		})
+	}()
+
+	g.Expect(os.WriteFile(path, []byte(strings.Replace(watcherTestConfig, "interval: 10s", "interval: 20s", 1)), 0600)).To(Succeed())
+
+	// The same content is reported again until it is applied.
+	for range 3 {
+		var cfg *Config
+		g.Eventually(calls, time.Second).Should(Receive(&cfg))
+		g.Expect(cfg.Checkers[0].Interval).To(Equal(20 * time.Second))
+	}
+	g.Consistently(calls, 50*time.Millisecond).ShouldNot(Receive())
+}
+
+func TestWatcher_Watch_RetryBackoff(t *testing.T) {
+	g := NewWithT(t)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	g.Expect(os.WriteFile(path, []byte(watcherTestConfig), 0600)).To(Succeed())
+
+	w := NewWatcher(path, 10*time.Millisecond)
+	w.maxRetryBackoff = 40 * time.Millisecond
+	_, err := w.Load()
+	g.Expect(err).ToNot(HaveOccurred())
+
+	calls := make(chan time.Time, 10)
+	applied := make(chan struct{}, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_ = w.Watch(ctx, func(cfg *Config, err error) error {
+			if cfg.Checkers[0].Interval == 20*time.Second {
+				calls <- time.Now()
+				return errors.New("failed to apply")
+			}
+			applied <- struct{}{}
+			return nil
+		})
+	}()
+
+	g.Expect(os.WriteFile(path, []byte(strings.Replace(watcherTestConfig, "interval: 10s", "interval: 20s", 1)), 0600)).To(Succeed())
+
+	// Content that fails to apply is reported again after a backoff that doubles up to the maximum.
+	var last time.Time
+	g.Eventually(calls, time.Second).Should(Receive(&last))
+	for _, backoff := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 40 * time.Millisecond, 40 * time.Millisecond} {
+		var next time.Time
+		g.Eventually(calls, time.Second).Should(Receive(&next))
+		g.Expect(next.Sub(last)).To(BeNumerically(">=", backoff))
+		last = next
+	}
+
+	// A change of the content is reported without waiting for the backoff, and stops the retries once it is applied.
+	g.Expect(os.WriteFile(path, []byte(strings.Replace(watcherTestConfig, "interval: 10s", "interval: 30s", 1)), 0600)).To(Succeed())
+	g.Eventually(applied, time.Second).Should(Receive())
+	for len(calls) > 0 {
+		<-calls
+	}
+	g.Consistently(calls, 100*time.Millisecond).ShouldNot(Receive())
+	g.Consistently(applied, 100*time.Millisecond).ShouldNot(Receive())
+}
+
+func TestWatcher_Load_NotExist(t *testing.T) {
+	g := NewWithT(t)
+	w := NewWatcher("/tmp/does-not-exist.yaml", time.Second)
+	_, err := w.Load()
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestDiffCheckers(t *testing.T) {
+	dns := func(name string, interval time.Duration) CheckerConfig {
+		return CheckerConfig{
+			Name:      name,
+			Type:      CheckTypeDNS,
+			Interval:  interval,
+			Timeout:   5 * time.Second,
+			DNSConfig: &DNSConfig{Domain: "example.com", QueryTimeout: 2 * time.Second, Target: DNSCheckTargetCoreDNS},
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		oldCfg   *Config
+		newCfg   *Config
+		validate func(g *WithT, diff CheckerDiff)
+	}{
+		{
+			name:   "identical configs",
+			oldCfg: &Config{Checkers: []CheckerConfig{dns("a", time.Second)}},
+			newCfg: &Config{Checkers: []CheckerConfig{dns("a", time.Second)}},
+			validate: func(g *WithT, diff CheckerDiff) {
+				g.Expect(diff.IsEmpty()).To(BeTrue())
+			},
+		},
+		{
+			name:   "nil old config",
+			oldCfg: nil,
+			newCfg: &Config{Checkers: []CheckerConfig{dns("a", time.Second)}},
+			validate: func(g *WithT, diff CheckerDiff) {
+				g.Expect(diff.Added).To(HaveLen(1))
+				g.Expect(diff.Removed).To(BeEmpty())
+				g.Expect(diff.Changed).To(BeEmpty())
+			},
+		},
+		{
+			name:   "added, removed and changed checkers",
+			oldCfg: &Config{Checkers: []CheckerConfig{dns("a", time.Second), dns("b", time.Second), dns("c", time.Second)}},
+			newCfg: &Config{Checkers: []CheckerConfig{dns("a", time.Second), dns("b", 2*time.Second), dns("d", time.Second)}},
+			validate: func(g *WithT, diff CheckerDiff) {
+				g.Expect(diff.IsEmpty()).To(BeFalse())
+				g.Expect(diff.Added).To(HaveLen(1))
+				g.Expect(diff.Added[0].Name).To(Equal("d"))
+				g.Expect(diff.Removed).To(HaveLen(1))
+				g.Expect(diff.Removed[0].Name).To(Equal("c"))
+				g.Expect(diff.Changed).To(HaveLen(1))
+				g.Expect(diff.Changed[0].Name).To(Equal("b"))
+				g.Expect(diff.Changed[0].Interval).To(Equal(2 * time.Second))
+			},
+		},
+		{
+			name:   "nested config change",
+			oldCfg: &Config{Checkers: []CheckerConfig{dns("a", time.Second)}},
+			newCfg: func() *Config {
+				chk := dns("a", time.Second)
+				chk.DNSConfig.Domain = "example.org"
+				return &Config{Checkers: []CheckerConfig{chk}}
+			}(),
+			validate: func(g *WithT, diff CheckerDiff) {
+				g.Expect(diff.Changed).To(HaveLen(1))
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			tc.validate(g, DiffCheckers(tc.oldCfg, tc.newCfg))
+		})
+	}
+}
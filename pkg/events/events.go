@@ -0,0 +1,122 @@
+// Package events publishes changes of the effective status of checkers as Kubernetes Events.
+package events
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/klog/v2"
+
+	"github.com/Azure/cluster-health-monitor/pkg/checker"
+	"github.com/Azure/cluster-health-monitor/pkg/metrics"
+)
+
+const (
+	// component is the source component of the events.
+	component = "cluster-health-monitor"
+
+	// Reasons of the events, one per effective status.
+	ReasonCheckerHealthy   = "CheckerHealthy"
+	ReasonCheckerUnhealthy = "CheckerUnhealthy"
+	ReasonCheckerUnknown   = "CheckerUnknown"
+
+	// Annotations added to the events so that event exporters can read the checker details without parsing the message.
+	AnnotationCheckerName = "cluster-health-monitor.azure.com/checker-name"
+	AnnotationCheckerType = "cluster-health-monitor.azure.com/checker-type"
+	AnnotationPod         = "cluster-health-monitor.azure.com/pod"
+	AnnotationNameserver  = "cluster-health-monitor.azure.com/nameserver"
+	AnnotationGroup       = "cluster-health-monitor.azure.com/group"
+	AnnotationErrorCode   = "cluster-health-monitor.azure.com/error-code"
+)
+
+// Recorder records checker transitions as events attached to a single object, typically the Deployment of cluster-health-monitor.
+type Recorder struct {
+	recorder record.EventRecorder
+	object   *corev1.ObjectReference
+}
+
+// NewRecorder creates a Recorder that attaches events to the given object.
+func NewRecorder(recorder record.EventRecorder, object *corev1.ObjectReference) *Recorder {
+	return &Recorder{
+		recorder: recorder,
+		object:   object,
+	}
+}
+
+// StartDeploymentRecorder creates a Recorder that attaches events to the named Deployment and starts sending the events to the API
+// server. Events are sent until the context is cancelled.
+func StartDeploymentRecorder(ctx context.Context, kubeClient kubernetes.Interface, namespace, name string) (*Recorder, error) {
+	deployment, err := kubeClient.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Deployment %s/%s: %w", namespace, name, err)
+	}
+
+	// The broadcaster shuts down by itself when the context is cancelled.
+	broadcaster := record.NewBroadcaster(record.WithContext(ctx))
+	broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: kubeClient.CoreV1().Events(namespace)})
+
+	recorder := broadcaster.NewRecorder(scheme.Scheme, corev1.EventSource{Component: component})
+	return NewRecorder(recorder, deploymentReference(deployment)), nil
+}
+
+// RecordTransition records an event for a change of the effective status of a checker. Transitions to healthy are recorded as normal
+// events, all other transitions as warnings. It can be registered with checker.OnTransition.
+func (r *Recorder) RecordTransition(t checker.Transition) {
+	eventType := corev1.EventTypeWarning
+	reason := ReasonCheckerUnknown
+	switch t.To {
+	case metrics.HealthyStatus:
+		eventType = corev1.EventTypeNormal
+		reason = ReasonCheckerHealthy
+	case metrics.UnhealthyStatus:
+		reason = ReasonCheckerUnhealthy
+	}
+
+	annotations := map[string]string{
+		AnnotationCheckerName: t.CheckerName,
+		AnnotationCheckerType: t.CheckerType,
+	}
+	subject := fmt.Sprintf("Checker %s of type %s", t.CheckerName, t.CheckerType)
+	if t.Pod != "" {
+		annotations[AnnotationPod] = t.Pod
+		subject = fmt.Sprintf("%s for pod %s", subject, t.Pod)
+	}
+	if t.Nameserver != "" {
+		annotations[AnnotationNameserver] = t.Nameserver
+		subject = fmt.Sprintf("%s for nameserver %s", subject, t.Nameserver)
+	}
+	if t.Group != "" {
+		annotations[AnnotationGroup] = t.Group
+		subject = fmt.Sprintf("%s for placement group %s", subject, t.Group)
+	}
+	message := fmt.Sprintf("%s changed from %s to %s", subject, t.From, t.To)
+	if t.To != metrics.HealthyStatus {
+		annotations[AnnotationErrorCode] = t.Code
+		message = fmt.Sprintf("%s with error code %s", message, t.Code)
+		if t.Message != "" {
+			message = fmt.Sprintf("%s: %s", message, t.Message)
+		}
+	}
+
+	r.recorder.AnnotatedEventf(r.object, annotations, eventType, reason, "%s", message)
+	klog.V(3).InfoS("Recorded checker transition event", "name", t.CheckerName, "type", t.CheckerType, "pod", t.Pod, "nameserver", t.Nameserver,
+		"group", t.Group, "reason", reason)
+}
+
+// deploymentReference returns a reference to the Deployment that events can be attached to.
+func deploymentReference(deployment *appsv1.Deployment) *corev1.ObjectReference {
+	return &corev1.ObjectReference{
+		APIVersion: "apps/v1",
+		Kind:       "Deployment",
+		Namespace:  deployment.Namespace,
+		Name:       deployment.Name,
+		UID:        deployment.UID,
+	}
+}
@@ -0,0 +1,120 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/Azure/cluster-health-monitor/pkg/checker"
+	"github.com/Azure/cluster-health-monitor/pkg/metrics"
+)
+
+func TestRecorder_RecordTransition(t *testing.T) {
+	t.Parallel()
+	object := &corev1.ObjectReference{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "kube-system", Name: "cluster-health-monitor"}
+
+	testCases := []struct {
+		name            string
+		transition      checker.Transition
+		expectedPrefix  string
+		expectedSubstrs []string
+	}{
+		{
+			name: "Transition to healthy",
+			transition: checker.Transition{
+				CheckerName: "dns", CheckerType: "dns", From: metrics.UnhealthyStatus, To: metrics.HealthyStatus,
+				Code: metrics.HealthyCode,
+			},
+			expectedPrefix: "Normal CheckerHealthy Checker dns of type dns changed from Unhealthy to Healthy",
+			expectedSubstrs: []string{
+				AnnotationCheckerName + ":dns",
+				AnnotationCheckerType + ":dns",
+			},
+		},
+		{
+			name: "Transition to unhealthy",
+			transition: checker.Transition{
+				CheckerName: "apiserver", CheckerType: "apiServer", From: metrics.HealthyStatus, To: metrics.UnhealthyStatus,
+				Code: "timeout", Message: "request timed out",
+			},
+			expectedPrefix: "Warning CheckerUnhealthy Checker apiserver of type apiServer changed from Healthy to Unhealthy " +
+				"with error code timeout: request timed out",
+			expectedSubstrs: []string{
+				"involvedObject{kind=Deployment,apiVersion=apps/v1}",
+				AnnotationErrorCode + ":timeout",
+			},
+		},
+		{
+			name: "Pod transition to unknown",
+			transition: checker.Transition{
+				CheckerName: "dns", CheckerType: "dns", Pod: "kube-system/coredns-1", From: metrics.HealthyStatus,
+				To: metrics.UnknownStatus, Code: metrics.UnknownCode,
+			},
+			expectedPrefix: "Warning CheckerUnknown Checker dns of type dns for pod kube-system/coredns-1 changed from Healthy to Unknown " +
+				"with error code Unknown",
+			expectedSubstrs: []string{
+				AnnotationPod + ":kube-system/coredns-1",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			fakeRecorder := record.NewFakeRecorder(1)
+			fakeRecorder.IncludeObject = true
+
+			NewRecorder(fakeRecorder, object).RecordTransition(tc.transition)
+
+			g.Expect(fakeRecorder.Events).To(HaveLen(1))
+			event := <-fakeRecorder.Events
+			g.Expect(event).To(HavePrefix(tc.expectedPrefix))
+			for _, substr := range tc.expectedSubstrs {
+				g.Expect(event).To(ContainSubstring(substr))
+			}
+		})
+	}
+}
+
+func TestStartDeploymentRecorder(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Missing deployment", func(t *testing.T) {
+		t.Parallel()
+		g := NewWithT(t)
+		_, err := StartDeploymentRecorder(context.Background(), fake.NewClientset(), "kube-system", "cluster-health-monitor")
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("Events are attached to the deployment", func(t *testing.T) {
+		t.Parallel()
+		g := NewWithT(t)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		client := fake.NewClientset(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "cluster-health-monitor", UID: "uid"},
+		})
+
+		recorder, err := StartDeploymentRecorder(ctx, client, "kube-system", "cluster-health-monitor")
+		g.Expect(err).ToNot(HaveOccurred())
+		recorder.RecordTransition(checker.Transition{
+			CheckerName: "dns", CheckerType: "dns", From: metrics.UnknownStatus, To: metrics.HealthyStatus,
+		})
+
+		g.Eventually(func(g Gomega) {
+			events, err := client.CoreV1().Events("kube-system").List(ctx, metav1.ListOptions{})
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(events.Items).To(HaveLen(1))
+			g.Expect(events.Items[0].Reason).To(Equal(ReasonCheckerHealthy))
+			g.Expect(events.Items[0].InvolvedObject.Name).To(Equal("cluster-health-monitor"))
+			g.Expect(events.Items[0].InvolvedObject.UID).To(BeEquivalentTo("uid"))
+		}).Should(Succeed())
+	})
+}
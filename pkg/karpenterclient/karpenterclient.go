@@ -0,0 +1,106 @@
+// Package karpenterclient gets, creates and deletes the Karpenter objects used by the checkers that provision nodes through a dynamic
+// client.
+package karpenterclient
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	karpenter "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+var (
+	NodePoolGVR = schema.GroupVersionResource{
+		Group:    "karpenter.sh",
+		Version:  "v1",
+		Resource: "nodepools",
+	}
+	NodeClaimGVR = schema.GroupVersionResource{
+		Group:    "karpenter.sh",
+		Version:  "v1",
+		Resource: "nodeclaims",
+	}
+
+	customResourceDefinitionsGVR = schema.GroupVersionResource{
+		Group:    "apiextensions.k8s.io",
+		Version:  "v1",
+		Resource: "customresourcedefinitions",
+	}
+)
+
+// IsNodePoolCRDPresent returns whether the Karpenter NodePool CRD is present in the cluster.
+func IsNodePoolCRDPresent(ctx context.Context, client dynamic.Interface) (bool, error) {
+	_, err := client.Resource(customResourceDefinitionsGVR).Get(ctx, "nodepools.karpenter.sh", metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil // CRD doesn't exist, but this is not an error condition
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// CreateNodePool creates the NodePool and returns it as created.
+func CreateNodePool(ctx context.Context, client dynamic.Interface, nodePool *karpenter.NodePool) (*karpenter.NodePool, error) {
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(nodePool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert NodePool: %w", err)
+	}
+	created, err := client.Resource(NodePoolGVR).Create(ctx, &unstructured.Unstructured{Object: object}, metav1.CreateOptions{})
+	if err != nil {
+		return nil, err
+	}
+	createdNodePool := &karpenter.NodePool{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(created.Object, createdNodePool); err != nil {
+		return nil, fmt.Errorf("failed to parse NodePool %s: %w", created.GetName(), err)
+	}
+	return createdNodePool, nil
+}
+
+// DeleteNodePool deletes the NodePool. Deleting a NodePool that does not exist is not an error.
+func DeleteNodePool(ctx context.Context, client dynamic.Interface, nodePoolName string) error {
+	err := client.Resource(NodePoolGVR).Delete(ctx, nodePoolName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// GetNodePool returns the NodePool, or nil if it does not exist.
+func GetNodePool(ctx context.Context, client dynamic.Interface, nodePoolName string) (*karpenter.NodePool, error) {
+	object, err := client.Resource(NodePoolGVR).Get(ctx, nodePoolName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get NodePool %s: %w", nodePoolName, err)
+	}
+	nodePool := &karpenter.NodePool{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, nodePool); err != nil {
+		return nil, fmt.Errorf("failed to parse NodePool %s: %w", nodePoolName, err)
+	}
+	return nodePool, nil
+}
+
+// ListNodeClaims returns the NodeClaims matching the label selector.
+func ListNodeClaims(ctx context.Context, client dynamic.Interface, labelSelector string) ([]karpenter.NodeClaim, error) {
+	list, err := client.Resource(NodeClaimGVR).List(ctx, metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list NodeClaims: %w", err)
+	}
+	nodeClaims := make([]karpenter.NodeClaim, 0, len(list.Items))
+	for _, item := range list.Items {
+		var nodeClaim karpenter.NodeClaim
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(item.Object, &nodeClaim); err != nil {
+			return nil, fmt.Errorf("failed to parse NodeClaim %s: %w", item.GetName(), err)
+		}
+		nodeClaims = append(nodeClaims, nodeClaim)
+	}
+	return nodeClaims, nil
+}
@@ -0,0 +1,168 @@
+package karpenterclient
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+	karpenter "sigs.k8s.io/karpenter/pkg/apis/v1"
+)
+
+func testNodePool(name string) *karpenter.NodePool {
+	return &karpenter.NodePool{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NodePool",
+			APIVersion: "karpenter.sh/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{"test-label": "test"},
+		},
+	}
+}
+
+func TestIsNodePoolCRDPresent(t *testing.T) {
+	nodePoolCRD := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata": map[string]interface{}{
+			"name": "nodepools.karpenter.sh",
+		},
+	}}
+
+	tests := []struct {
+		name            string
+		objects         []runtime.Object
+		getError        error
+		expectedPresent bool
+		expectedErr     string
+	}{
+		{
+			name:            "CRD present",
+			objects:         []runtime.Object{nodePoolCRD},
+			expectedPresent: true,
+		},
+		{
+			name:            "CRD not found",
+			expectedPresent: false,
+		},
+		{
+			name:        "get error",
+			getError:    errors.New("forbidden"),
+			expectedErr: "forbidden",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), tt.objects...)
+			if tt.getError != nil {
+				client.PrependReactor("get", "customresourcedefinitions", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.getError
+				})
+			}
+
+			present, err := IsNodePoolCRDPresent(context.Background(), client)
+			if tt.expectedErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.expectedErr)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(present).To(Equal(tt.expectedPresent))
+		})
+	}
+}
+
+func TestCreateNodePool(t *testing.T) {
+	tests := []struct {
+		name        string
+		createError error
+		expectedErr string
+	}{
+		{
+			name: "successful creation",
+		},
+		{
+			name:        "creation failure",
+			createError: errors.New("unexpected error occurred while creating node pool"),
+			expectedErr: "unexpected error occurred while creating node pool",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+			if tt.createError != nil {
+				client.PrependReactor("create", "nodepools", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.createError
+				})
+			}
+
+			created, err := CreateNodePool(context.Background(), client, testNodePool("test-nodepool"))
+			if tt.expectedErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.expectedErr)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(created.Name).To(Equal("test-nodepool"))
+			g.Expect(created.Labels).To(Equal(map[string]string{"test-label": "test"}))
+
+			nodePool, err := GetNodePool(context.Background(), client, "test-nodepool")
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(nodePool.Name).To(Equal("test-nodepool"))
+		})
+	}
+}
+
+func TestDeleteNodePool(t *testing.T) {
+	tests := []struct {
+		name        string
+		deleteError error
+		expectedErr string
+	}{
+		{
+			name: "successful deletion",
+		},
+		{
+			name:        "not found - skip without error",
+			deleteError: apierrors.NewNotFound(NodePoolGVR.GroupResource(), "test-nodepool"),
+		},
+		{
+			name:        "deletion failure",
+			deleteError: errors.New("unexpected error occurred while deleting node pool"),
+			expectedErr: "unexpected error occurred while deleting node pool",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(testNodePool("test-nodepool"))
+			g.Expect(err).ToNot(HaveOccurred())
+			client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), &unstructured.Unstructured{Object: object})
+			if tt.deleteError != nil {
+				client.PrependReactor("delete", "nodepools", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tt.deleteError
+				})
+			}
+
+			err = DeleteNodePool(context.Background(), client, "test-nodepool")
+			if tt.expectedErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.expectedErr)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			if tt.deleteError == nil {
+				nodePool, err := GetNodePool(context.Background(), client, "test-nodepool")
+				g.Expect(err).ToNot(HaveOccurred())
+				g.Expect(nodePool).To(BeNil())
+			}
+		})
+	}
+}
@@ -11,6 +11,10 @@ const (
 	// We set a default value for healthy and unknown result.
 	HealthyCode = HealthyStatus
 	UnknownCode = UnknownStatus
+
+	// Values of the result label of ConfigReloadCounter.
+	ConfigReloadSuccess = "Success"
+	ConfigReloadFailure = "Failure"
 )
 
 var (
@@ -31,4 +35,210 @@ var (
 		},
 		[]string{"checker_type", "checker_name", "pod_namespace", "pod_name", "status", "error_code"},
 	)
+
+	// NameserverHealthResultCounter is a Prometheus counter that tracks the results of the checks of custom DNS nameservers.
+	NameserverHealthResultCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cluster_health_monitor_nameserver_health_result_total",
+			Help: "Total number of per-nameserver health checks, labeled by status and code",
+		},
+		[]string{"checker_type", "checker_name", "nameserver", "status", "error_code"},
+	)
+
+	// PlacementGroupHealthResultCounter is a Prometheus counter that tracks the results of the checks of placement groups, e.g. the
+	// synthetic pod started in each node pool or zone by a PodStartup checker.
+	PlacementGroupHealthResultCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cluster_health_monitor_placement_group_health_result_total",
+			Help: "Total number of per-placement-group health checks, labeled by status and code",
+		},
+		[]string{"checker_type", "checker_name", "group", "status", "error_code"},
+	)
+
+	// ConfigReloadCounter is a Prometheus counter that tracks attempts to reload the configuration file.
+	ConfigReloadCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cluster_health_monitor_config_reload_total",
+			Help: "Total number of configuration reload attempts, labeled by result",
+		},
+		[]string{"result"},
+	)
+
+	// CheckerHungCounter is a Prometheus counter that tracks checker runs that did not return long after their timeout.
+	CheckerHungCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cluster_health_monitor_checker_hung_total",
+			Help: "Total number of checker runs that did not return within a grace period after their timeout",
+		},
+		[]string{"checker_type", "checker_name"},
+	)
+
+	// CheckerRunErrorCounter is a Prometheus counter that tracks errors that kept checker runs from producing some or all of their
+	// results, e.g. failures to discover the targets of per-target checkers.
+	CheckerRunErrorCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cluster_health_monitor_checker_run_error_total",
+			Help: "Total number of errors that kept checker runs from producing results, labeled by reason",
+		},
+		[]string{"checker_type", "checker_name", "reason"},
+	)
+
+	// CheckerLastStatusGauge is a Prometheus gauge that reports the status of the latest checker run. The series of the latest status is 1
+	// and the series of all other statuses are 0.
+	CheckerLastStatusGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_health_monitor_checker_last_status",
+			Help: "Status of the latest checker run, 1 for the current status and 0 for the others",
+		},
+		[]string{"checker_type", "checker_name", "status"},
+	)
+
+	// PodHealthLastStatusGauge is a Prometheus gauge that reports the status of the latest CoreDNS pod check. The series of the latest
+	// status is 1 and the series of all other statuses are 0.
+	PodHealthLastStatusGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_health_monitor_pod_health_last_status",
+			Help: "Status of the latest per-pod health check, 1 for the current status and 0 for the others",
+		},
+		[]string{"checker_type", "checker_name", "pod_namespace", "pod_name", "status"},
+	)
+
+	// NameserverHealthLastStatusGauge is a Prometheus gauge that reports the status of the latest check of a custom DNS nameserver. The
+	// series of the latest status is 1 and the series of all other statuses are 0.
+	NameserverHealthLastStatusGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_health_monitor_nameserver_health_last_status",
+			Help: "Status of the latest per-nameserver health check, 1 for the current status and 0 for the others",
+		},
+		[]string{"checker_type", "checker_name", "nameserver", "status"},
+	)
+
+	// PlacementGroupHealthLastStatusGauge is a Prometheus gauge that reports the status of the latest check of a placement group. The
+	// series of the latest status is 1 and the series of all other statuses are 0.
+	PlacementGroupHealthLastStatusGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_health_monitor_placement_group_health_last_status",
+			Help: "Status of the latest per-placement-group health check, 1 for the current status and 0 for the others",
+		},
+		[]string{"checker_type", "checker_name", "group", "status"},
+	)
+
+	// CheckerEffectiveStatusGauge is a Prometheus gauge that reports the effective status of a checker, which only changes after the
+	// configured number of consecutive failed or healthy runs. The series of the effective status is 1 and the series of all other
+	// statuses are 0.
+	CheckerEffectiveStatusGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_health_monitor_checker_effective_status",
+			Help: "Effective status of the checker after applying its thresholds, 1 for the current status and 0 for the others",
+		},
+		[]string{"checker_type", "checker_name", "status"},
+	)
+
+	// PodHealthEffectiveStatusGauge is a Prometheus gauge that reports the effective status of a pod checked by a CoreDNS per-pod checker,
+	// which only changes after the configured number of consecutive failed or healthy checks. The series of the effective status is 1 and
+	// the series of all other statuses are 0.
+	PodHealthEffectiveStatusGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_health_monitor_pod_health_effective_status",
+			Help: "Effective status of the per-pod health check after applying its thresholds, 1 for the current status and 0 for the others",
+		},
+		[]string{"checker_type", "checker_name", "pod_namespace", "pod_name", "status"},
+	)
+
+	// NameserverHealthEffectiveStatusGauge is a Prometheus gauge that reports the effective status of a nameserver checked by a custom
+	// DNS checker, which only changes after the configured number of consecutive failed or healthy checks. The series of the effective
+	// status is 1 and the series of all other statuses are 0.
+	NameserverHealthEffectiveStatusGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_health_monitor_nameserver_health_effective_status",
+			Help: "Effective status of the per-nameserver health check after applying its thresholds, 1 for the current status and 0 for the others",
+		},
+		[]string{"checker_type", "checker_name", "nameserver", "status"},
+	)
+
+	// PlacementGroupHealthEffectiveStatusGauge is a Prometheus gauge that reports the effective status of a placement group checked by a
+	// checker, which only changes after the configured number of consecutive failed or healthy checks. The series of the effective status
+	// is 1 and the series of all other statuses are 0.
+	PlacementGroupHealthEffectiveStatusGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_health_monitor_placement_group_health_effective_status",
+			Help: "Effective status of the per-placement-group health check after applying its thresholds, 1 for the current status and 0 for the others",
+		},
+		[]string{"checker_type", "checker_name", "group", "status"},
+	)
+
+	// CheckerLastRunTimestampGauge is a Prometheus gauge that reports when a result was last recorded for a checker.
+	CheckerLastRunTimestampGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "cluster_health_monitor_checker_last_run_timestamp_seconds",
+			Help: "Unix timestamp in seconds of the latest recorded checker result",
+		},
+		[]string{"checker_type", "checker_name"},
+	)
+
+	// CheckerDurationHistogram is a Prometheus histogram that tracks how long checker runs take.
+	CheckerDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "cluster_health_monitor_checker_duration_seconds",
+			Help:    "Duration of checker runs in seconds, labeled by status",
+			Buckets: durationBuckets,
+		},
+		[]string{"checker_type", "checker_name", "status"},
+	)
+
+	// CheckerPhaseDurationHistogram is a Prometheus histogram that tracks how long the individual phases of checker runs take, e.g. the
+	// create, get and delete calls of the APIServer checker.
+	CheckerPhaseDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "cluster_health_monitor_checker_phase_duration_seconds",
+			Help:    "Duration of checker run phases in seconds, labeled by phase",
+			Buckets: durationBuckets,
+		},
+		[]string{"checker_type", "checker_name", "phase"},
+	)
+
+	// PlacementGroupPhaseDurationHistogram is a Prometheus histogram that tracks how long the phases of checker runs take in each
+	// placement group, e.g. the pod startup of the synthetic pod started in each zone.
+	PlacementGroupPhaseDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "cluster_health_monitor_placement_group_phase_duration_seconds",
+			Help:    "Duration of checker run phases per placement group in seconds, labeled by phase",
+			Buckets: durationBuckets,
+		},
+		[]string{"checker_type", "checker_name", "group", "phase"},
+	)
+
+	// StorageOperationDurationHistogram is a Prometheus histogram that tracks how long provisioning the PVCs of the synthetic pods, attaching
+	// and mounting their volumes take, labeled by storage class, volume and operation. The volume tells storage probes of the same storage class
+	// apart.
+	StorageOperationDurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "cluster_health_monitor_storage_operation_duration_seconds",
+			Help:    "Duration of storage operations in seconds, labeled by storage class, volume and operation",
+			Buckets: durationBuckets,
+		},
+		[]string{"checker_type", "checker_name", "storage_class", "volume", "operation"},
+	)
+
+	// VolumeIODurationHistogram is a Prometheus histogram that tracks how long writing and reading back a file takes on the volumes of the
+	// synthetic pods, labeled by storage class and operation, i.e. write or read.
+	VolumeIODurationHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "cluster_health_monitor_volume_io_duration_seconds",
+			Help:    "Duration of volume I/O in seconds, labeled by storage class and operation",
+			Buckets: volumeIOBuckets,
+		},
+		[]string{"checker_type", "checker_name", "storage_class", "operation"},
+	)
 )
+
+// durationBuckets are the histogram buckets for checker durations. They range from 50ms to roughly 7 minutes so that both fast API and
+// DNS calls and slow synthetic pod startups are covered.
+var durationBuckets = prometheus.ExponentialBuckets(0.05, 2, 14)
+
+// volumeIOBuckets are the histogram buckets for volume I/O durations. They range from 1ms to roughly 30 seconds, as writing a small file to
+// a local disk takes milliseconds while a slow network volume can take seconds.
+var volumeIOBuckets = prometheus.ExponentialBuckets(0.001, 2, 16)
+
+// Statuses lists the values of the status label of the last status and effective status gauges.
+var Statuses = []string{HealthyStatus, UnhealthyStatus, UnknownStatus}
@@ -5,12 +5,13 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/Azure/cluster-health-monitor/pkg/status"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"k8s.io/klog/v2"
 )
 
-// Server holds Prometheus collectors and exposes them via HTTP.
+// Server holds Prometheus collectors and exposes them via HTTP. It also serves the latest checker results from the status store as JSON.
 type Server struct {
 	registry *prometheus.Registry
 	port     int
@@ -28,6 +29,82 @@ func NewServer(port int) (*Server, error) {
 		klog.ErrorS(err, "Failed to register CoreDNS pod result counter")
 		return nil, err
 	}
+	if err := reg.Register(NameserverHealthResultCounter); err != nil {
+		klog.ErrorS(err, "Failed to register nameserver result counter")
+		return nil, err
+	}
+	if err := reg.Register(PlacementGroupHealthResultCounter); err != nil {
+		klog.ErrorS(err, "Failed to register placement group result counter")
+		return nil, err
+	}
+	if err := reg.Register(ConfigReloadCounter); err != nil {
+		klog.ErrorS(err, "Failed to register config reload counter")
+		return nil, err
+	}
+	if err := reg.Register(CheckerHungCounter); err != nil {
+		klog.ErrorS(err, "Failed to register checker hung counter")
+		return nil, err
+	}
+	if err := reg.Register(CheckerRunErrorCounter); err != nil {
+		klog.ErrorS(err, "Failed to register checker run error counter")
+		return nil, err
+	}
+	if err := reg.Register(CheckerLastStatusGauge); err != nil {
+		klog.ErrorS(err, "Failed to register checker last status gauge")
+		return nil, err
+	}
+	if err := reg.Register(PodHealthLastStatusGauge); err != nil {
+		klog.ErrorS(err, "Failed to register CoreDNS pod last status gauge")
+		return nil, err
+	}
+	if err := reg.Register(NameserverHealthLastStatusGauge); err != nil {
+		klog.ErrorS(err, "Failed to register nameserver last status gauge")
+		return nil, err
+	}
+	if err := reg.Register(PlacementGroupHealthLastStatusGauge); err != nil {
+		klog.ErrorS(err, "Failed to register placement group last status gauge")
+		return nil, err
+	}
+	if err := reg.Register(CheckerEffectiveStatusGauge); err != nil {
+		klog.ErrorS(err, "Failed to register checker effective status gauge")
+		return nil, err
+	}
+	if err := reg.Register(PodHealthEffectiveStatusGauge); err != nil {
+		klog.ErrorS(err, "Failed to register CoreDNS pod effective status gauge")
+		return nil, err
+	}
+	if err := reg.Register(NameserverHealthEffectiveStatusGauge); err != nil {
+		klog.ErrorS(err, "Failed to register nameserver effective status gauge")
+		return nil, err
+	}
+	if err := reg.Register(PlacementGroupHealthEffectiveStatusGauge); err != nil {
+		klog.ErrorS(err, "Failed to register placement group effective status gauge")
+		return nil, err
+	}
+	if err := reg.Register(CheckerLastRunTimestampGauge); err != nil {
+		klog.ErrorS(err, "Failed to register checker last run timestamp gauge")
+		return nil, err
+	}
+	if err := reg.Register(CheckerDurationHistogram); err != nil {
+		klog.ErrorS(err, "Failed to register checker duration histogram")
+		return nil, err
+	}
+	if err := reg.Register(CheckerPhaseDurationHistogram); err != nil {
+		klog.ErrorS(err, "Failed to register checker phase duration histogram")
+		return nil, err
+	}
+	if err := reg.Register(PlacementGroupPhaseDurationHistogram); err != nil {
+		klog.ErrorS(err, "Failed to register placement group phase duration histogram")
+		return nil, err
+	}
+	if err := reg.Register(StorageOperationDurationHistogram); err != nil {
+		klog.ErrorS(err, "Failed to register storage operation duration histogram")
+		return nil, err
+	}
+	if err := reg.Register(VolumeIODurationHistogram); err != nil {
+		klog.ErrorS(err, "Failed to register volume io duration histogram")
+		return nil, err
+	}
 	return &Server{
 		registry: reg,
 		port:     port,
@@ -38,6 +115,8 @@ func NewServer(port int) (*Server, error) {
 func (m *Server) Run(ctx context.Context) error {
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{}))
+	mux.HandleFunc("GET /status", status.DefaultStore.ServeList)
+	mux.HandleFunc("GET /status/{name}", status.DefaultStore.ServeChecker)
 	addr := fmt.Sprintf("0.0.0.0:%d", m.port)
 	m.server = &http.Server{
 		Addr:    addr,
@@ -0,0 +1,6 @@
+package scheduler
+
+const (
+	// ErrCodeCheckerHung is the error code of the result recorded by the scheduler when a checker run does not return in time.
+	ErrCodeCheckerHung = "CheckerHung"
+)
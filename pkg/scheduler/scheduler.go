@@ -2,19 +2,38 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
+	"math/rand/v2"
+	"sync"
 	"time"
 
 	"github.com/Azure/cluster-health-monitor/pkg/checker"
-	"golang.org/x/sync/errgroup"
+	"github.com/Azure/cluster-health-monitor/pkg/metrics"
+	"github.com/Azure/cluster-health-monitor/pkg/status"
+	"github.com/robfig/cron/v3"
 	"k8s.io/klog/v2"
 )
 
+// defaultHungGracePeriod is how long a run may exceed its timeout before the checker is considered hung.
+const defaultHungGracePeriod = 30 * time.Second
+
 // CheckerSchedule defines the schedule for a health checker
 type CheckerSchedule struct {
-	// Interval defines how often the checker should run.
+	// Interval defines how often the checker should run. It is ignored if CronSchedule is set.
 	Interval time.Duration
+	// CronSchedule optionally defines the times at which the checker should run instead of running every Interval.
+	CronSchedule cron.Schedule
+	// InitialDelay optionally defines the delay before the first run. Without it the first run happens one Interval after the checker is
+	// started, or at the first time of CronSchedule.
+	InitialDelay time.Duration
+	// JitterPercent optionally delays each run by a random duration of up to this percentage of the time between two runs.
+	JitterPercent int
 	// Timeout defines how long to wait for the checker to complete before considering it failed.
 	Timeout time.Duration
+	// FailureThreshold and SuccessThreshold optionally define how many consecutive failed or successful results change the effective
+	// status of the checker. They default to 1.
+	FailureThreshold int
+	SuccessThreshold int
 	// Checker is the actual health checker that will be run according to the schedule.
 	Checker checker.Checker
 }
@@ -22,51 +41,270 @@ type CheckerSchedule struct {
 // NewScheduler creates a new Scheduler instance.
 func NewScheduler(chkSchedules []CheckerSchedule) *Scheduler {
 	return &Scheduler{
-		chkSchedules: chkSchedules,
+		chkSchedules:    chkSchedules,
+		running:         make(map[string]*runningChecker),
+		hungGracePeriod: defaultHungGracePeriod,
 	}
 }
 
-// Scheduler manages and runs a set of checkers periodically.
+// Scheduler manages and runs a set of checkers periodically. Checkers can be added and removed while the scheduler is running.
 type Scheduler struct {
+	mu sync.Mutex
+	// ctx is the context passed to Start. It is nil until the scheduler has been started.
+	ctx context.Context
+	// chkSchedules holds the schedules added before the scheduler was started.
 	chkSchedules []CheckerSchedule
+	// running holds the checkers that are currently scheduled, keyed by checker name.
+	running map[string]*runningChecker
+	// hungGracePeriod is how long a run may exceed its timeout before the checker is reported as hung.
+	hungGracePeriod time.Duration
+	wg              sync.WaitGroup
+}
+
+// runningChecker tracks the goroutine driving a single checker.
+type runningChecker struct {
+	checker checker.Checker
+	cancel  context.CancelFunc
+	done    chan struct{}
 }
 
-// Start starts all checkers according to their configured intervals and timeouts.
+// Start starts all checkers according to their configured intervals and timeouts. It blocks until the context is cancelled and all
+// checkers have stopped.
 func (r *Scheduler) Start(ctx context.Context) error {
-	var g errgroup.Group
+	r.mu.Lock()
+	r.ctx = ctx
 	for _, chkSch := range r.chkSchedules {
-		g.Go(func() error {
-			return r.scheduleChecker(ctx, chkSch)
-		})
+		if err := r.startLocked(chkSch); err != nil {
+			klog.ErrorS(err, "Failed to start checker", "name", chkSch.Checker.Name())
+		}
 	}
-	return g.Wait()
+	r.chkSchedules = nil
+	r.mu.Unlock()
+
+	<-ctx.Done()
+	r.wg.Wait()
+	return ctx.Err()
 }
 
-func (r *Scheduler) scheduleChecker(ctx context.Context, chkSch CheckerSchedule) error {
-	ticker := time.NewTicker(chkSch.Interval)
-	defer ticker.Stop()
+// AddChecker schedules a new checker. If the scheduler has not been started yet, the checker is started together with the others when
+// Start is called. It returns an error if a checker with the same name is already scheduled, or was removed while hung and its hung run
+// has not returned yet.
+func (r *Scheduler) AddChecker(chkSch CheckerSchedule) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.ctx == nil {
+		for _, existing := range r.chkSchedules {
+			if existing.Checker.Name() == chkSch.Checker.Name() {
+				return fmt.Errorf("checker %q is already scheduled", chkSch.Checker.Name())
+			}
+		}
+		r.chkSchedules = append(r.chkSchedules, chkSch)
+		return nil
+	}
+	return r.startLocked(chkSch)
+}
+
+// RemoveChecker stops the checker with the given name, waits for any in-flight run to return and drops its entry from the status store
+// and its last result gauges. A hung run is not waited for, but the results it records when it eventually returns are dropped. It
+// returns false if no checker with that name is scheduled.
+func (r *Scheduler) RemoveChecker(name string) bool {
+	r.mu.Lock()
+	if r.ctx == nil {
+		for i, chkSch := range r.chkSchedules {
+			if chkSch.Checker.Name() == name {
+				r.chkSchedules = append(r.chkSchedules[:i], r.chkSchedules[i+1:]...)
+				r.mu.Unlock()
+				return true
+			}
+		}
+		r.mu.Unlock()
+		return false
+	}
+
+	rc, ok := r.running[name]
+	if ok {
+		delete(r.running, name)
+	}
+	r.mu.Unlock()
+	if !ok {
+		return false
+	}
+
+	checker.Retire(rc.checker)
+	rc.cancel()
+	<-rc.done
+	status.DefaultStore.Delete(name)
+	checker.DeleteLastResult(name)
+	return true
+}
+
+// CheckerNames returns the names of all scheduled checkers.
+func (r *Scheduler) CheckerNames() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var names []string
+	for _, chkSch := range r.chkSchedules {
+		names = append(names, chkSch.Checker.Name())
+	}
+	for name := range r.running {
+		names = append(names, name)
+	}
+	return names
+}
+
+// startLocked starts the goroutine for a checker. The caller must hold r.mu and the scheduler must have been started.
+func (r *Scheduler) startLocked(chkSch CheckerSchedule) error {
+	name := chkSch.Checker.Name()
+	if _, exists := r.running[name]; exists {
+		return fmt.Errorf("checker %q is already scheduled", name)
+	}
+	if checker.IsRetired(name) {
+		return fmt.Errorf("checker %q was removed and its hung run has not returned yet", name)
+	}
+	checker.SetThresholds(name, chkSch.FailureThreshold, chkSch.SuccessThreshold)
 
+	ctx, cancel := context.WithCancel(r.ctx)
+	rc := &runningChecker{
+		checker: chkSch.Checker,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	r.running[name] = rc
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer close(rc.done)
+		_ = r.scheduleChecker(ctx, chkSch)
+	}()
+	return nil
+}
+
+func (r *Scheduler) scheduleChecker(ctx context.Context, chkSch CheckerSchedule) error {
 	checkerName := chkSch.Checker.Name()
 	checkerType := string(chkSch.Checker.Type())
 	klog.InfoS("Started checker scheduler",
 		"name", checkerName,
 		"type", checkerType,
 		"interval", chkSch.Interval.String(),
+		"cronSchedule", chkSch.CronSchedule != nil,
+		"initialDelay", chkSch.InitialDelay.String(),
+		"jitterPercent", chkSch.JitterPercent,
 		"timeout", chkSch.Timeout.String())
+	// hungRun is closed when a run that was reported as hung finally returns. It is nil while no hung run is in flight.
+	var hungRun <-chan struct{}
+	// next is the unjittered time of the next run. Jitter is applied to each run separately so that it does not accumulate.
+	next := chkSch.firstRun(time.Now())
 	for {
+		timer := time.NewTimer(time.Until(next.Add(chkSch.jitter(next))))
 		select {
-		case <-ticker.C:
-			func() {
-				runCtx, cancel := context.WithTimeout(ctx, chkSch.Timeout)
-				defer cancel()
-				chkSch.Checker.Run(runCtx)
-			}()
-			klog.V(3).InfoS("Ran scheduled check",
-				"name", checkerName,
-				"type", checkerType)
+		case <-timer.C:
+			next = chkSch.nextRunAfter(next, time.Now())
+			if hungRun != nil {
+				select {
+				case <-hungRun:
+					hungRun = nil
+				default:
+					// Never start a run while the previous one is still in flight.
+					klog.V(3).InfoS("Skipped scheduled check, previous run is still in flight", "name", checkerName, "type", checkerType)
+					continue
+				}
+			}
+			hungRun = r.runChecker(ctx, chkSch)
+			if hungRun == nil {
+				klog.V(3).InfoS("Ran scheduled check",
+					"name", checkerName,
+					"type", checkerType)
+			}
 		case <-ctx.Done():
+			timer.Stop()
+			// The checker is released from retirement, if it was removed, once no run of it is in flight anymore.
+			if hungRun != nil {
+				go func() {
+					<-hungRun
+					checker.Release(chkSch.Checker)
+				}()
+			} else {
+				checker.Release(chkSch.Checker)
+			}
 			klog.InfoS("Stopped checker scheduler", "name", checkerName, "type", checkerType)
 			return ctx.Err()
 		}
 	}
 }
+
+// firstRun returns the time of the first run of a checker started at now.
+func (s CheckerSchedule) firstRun(now time.Time) time.Time {
+	if s.InitialDelay > 0 {
+		return now.Add(s.InitialDelay)
+	}
+	return s.nextRun(now)
+}
+
+// nextRun returns the time of the run following a run at t.
+func (s CheckerSchedule) nextRun(t time.Time) time.Time {
+	if s.CronSchedule != nil {
+		return s.CronSchedule.Next(t)
+	}
+	return t.Add(s.Interval)
+}
+
+// nextRunAfter returns the earliest run following a run at t that is not before now. Like a ticker, runs that were missed because a run
+// took too long are dropped rather than run back to back.
+func (s CheckerSchedule) nextRunAfter(t, now time.Time) time.Time {
+	next := s.nextRun(t)
+	for next.Before(now) {
+		next = s.nextRun(next)
+	}
+	return next
+}
+
+// jitter returns a random delay of up to JitterPercent of the time between the run at t and the run following it.
+func (s CheckerSchedule) jitter(t time.Time) time.Duration {
+	if s.JitterPercent <= 0 {
+		return 0
+	}
+	maxJitter := time.Duration(float64(s.nextRun(t).Sub(t)) * float64(s.JitterPercent) / 100)
+	if maxJitter <= 0 {
+		return 0
+	}
+	return rand.N(maxJitter)
+}
+
+// runChecker runs the checker once and waits for it to return. If the run exceeds its timeout by more than r.hungGracePeriod, e.g. because
+// the checker ignores its context, the checker is reported as hung and runChecker returns without waiting any longer. In that case the
+// returned channel is closed once the run eventually returns. Otherwise runChecker returns nil. Cancelling ctx cancels the run, but
+// runChecker still waits for it to return, so that a stopped checker has no run in flight unless it is hung.
+func (r *Scheduler) runChecker(ctx context.Context, chkSch CheckerSchedule) <-chan struct{} {
+	checkerName := chkSch.Checker.Name()
+	checkerType := string(chkSch.Checker.Type())
+	runCtx, cancel := context.WithTimeout(ctx, chkSch.Timeout)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer cancel()
+		checker.RecordRunStart(chkSch.Checker, time.Now())
+		chkSch.Checker.Run(runCtx)
+		checker.RecordRunEnd(chkSch.Checker, time.Now())
+	}()
+
+	hungTimer := time.NewTimer(chkSch.Timeout + r.hungGracePeriod)
+	defer hungTimer.Stop()
+	select {
+	case <-done:
+		return nil
+	case <-hungTimer.C:
+		metrics.CheckerHungCounter.WithLabelValues(checkerType, checkerName).Inc()
+		checker.RecordResult(chkSch.Checker, &checker.Result{
+			Status: checker.StatusUnknown,
+			Detail: checker.Detail{
+				Code:    ErrCodeCheckerHung,
+				Message: fmt.Sprintf("checker run did not return within %s after its timeout of %s", r.hungGracePeriod, chkSch.Timeout),
+			},
+		}, nil)
+		klog.ErrorS(nil, "Checker run is hung", "name", checkerName, "type", checkerType, "timeout", chkSch.Timeout.String(),
+			"gracePeriod", r.hungGracePeriod.String())
+		return done
+	}
+}
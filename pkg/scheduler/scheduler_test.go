@@ -7,8 +7,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/Azure/cluster-health-monitor/pkg/checker"
 	"github.com/Azure/cluster-health-monitor/pkg/config"
+	"github.com/Azure/cluster-health-monitor/pkg/metrics"
+	"github.com/Azure/cluster-health-monitor/pkg/status"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/robfig/cron/v3"
 )
 
 type fakeChecker struct {
@@ -44,3 +50,249 @@ func TestScheduler_Run_Periodic(t *testing.T) {
 	_ = scheduler.Start(ctx)
 	g.Expect(fakeChk.runCount).To(BeNumerically(">=", 2))
 }
+
+func TestScheduler_AddRemoveChecker(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	keptChk := &fakeChecker{name: "kept"}
+	removedChk := &fakeChecker{name: "removed"}
+	scheduler := NewScheduler([]CheckerSchedule{
+		{Interval: 10 * time.Millisecond, Timeout: time.Second, Checker: keptChk},
+	})
+
+	// Checkers added before Start are started together with the initial ones.
+	g.Expect(scheduler.AddChecker(CheckerSchedule{Interval: 10 * time.Millisecond, Timeout: time.Second, Checker: removedChk})).To(Succeed())
+	g.Expect(scheduler.AddChecker(CheckerSchedule{Interval: 10 * time.Millisecond, Timeout: time.Second, Checker: removedChk})).ToNot(Succeed())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = scheduler.Start(ctx)
+	}()
+
+	g.Eventually(func() int32 { return atomic.LoadInt32(&removedChk.runCount) }).Should(BeNumerically(">=", 1))
+	g.Expect(scheduler.CheckerNames()).To(ConsistOf("kept", "removed"))
+
+	// Removing a checker stops it without affecting the others.
+	g.Expect(scheduler.RemoveChecker("removed")).To(BeTrue())
+	g.Expect(scheduler.RemoveChecker("removed")).To(BeFalse())
+	removedRuns := atomic.LoadInt32(&removedChk.runCount)
+	keptRuns := atomic.LoadInt32(&keptChk.runCount)
+	g.Consistently(func() int32 { return atomic.LoadInt32(&removedChk.runCount) }, 50*time.Millisecond).Should(Equal(removedRuns))
+	g.Expect(atomic.LoadInt32(&keptChk.runCount)).To(BeNumerically(">", keptRuns))
+
+	// Checkers added after Start are started immediately.
+	addedChk := &fakeChecker{name: "added"}
+	g.Expect(scheduler.AddChecker(CheckerSchedule{Interval: 10 * time.Millisecond, Timeout: time.Second, Checker: addedChk})).To(Succeed())
+	g.Expect(scheduler.AddChecker(CheckerSchedule{Interval: 10 * time.Millisecond, Timeout: time.Second, Checker: addedChk})).ToNot(Succeed())
+	g.Eventually(func() int32 { return atomic.LoadInt32(&addedChk.runCount) }).Should(BeNumerically(">=", 1))
+	g.Expect(scheduler.CheckerNames()).To(ConsistOf("kept", "added"))
+
+	cancel()
+	g.Eventually(done).Should(BeClosed())
+}
+
+// hungChecker ignores its context and blocks for delay on every run. It tracks how many runs are in flight at the same time.
+type hungChecker struct {
+	fakeChecker
+	inFlight    int32
+	maxInFlight int32
+}
+
+func (h *hungChecker) Run(ctx context.Context) {
+	inFlight := atomic.AddInt32(&h.inFlight, 1)
+	defer atomic.AddInt32(&h.inFlight, -1)
+	for {
+		maxInFlight := atomic.LoadInt32(&h.maxInFlight)
+		if inFlight <= maxInFlight || atomic.CompareAndSwapInt32(&h.maxInFlight, maxInFlight, inFlight) {
+			break
+		}
+	}
+	h.fakeChecker.Run(ctx)
+}
+
+func TestScheduler_HungChecker(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	hungCount := func() float64 {
+		return testutil.ToFloat64(metrics.CheckerHungCounter.WithLabelValues("fake", "hung"))
+	}
+	initialHungCount := hungCount()
+
+	hungChk := &hungChecker{fakeChecker: fakeChecker{name: "hung", delay: 300 * time.Millisecond}}
+	scheduler := NewScheduler([]CheckerSchedule{
+		{Interval: 10 * time.Millisecond, Timeout: 10 * time.Millisecond, Checker: hungChk},
+	})
+	scheduler.hungGracePeriod = 20 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = scheduler.Start(ctx)
+	}()
+
+	// The hung run is reported as Unknown with a dedicated error code.
+	g.Eventually(hungCount).Should(Equal(initialHungCount + 1))
+	st, ok := status.DefaultStore.Get("hung")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(st.LastResult).ToNot(BeNil())
+	g.Expect(st.LastResult.Status).To(Equal(metrics.UnknownStatus))
+	g.Expect(st.LastResult.Code).To(Equal(ErrCodeCheckerHung))
+
+	// No new run starts while the hung run is still in flight, but scheduling resumes once it returns.
+	g.Expect(atomic.LoadInt32(&hungChk.runCount)).To(Equal(int32(1)))
+	g.Eventually(func() int32 { return atomic.LoadInt32(&hungChk.runCount) }, time.Second).Should(BeNumerically(">=", 2))
+	g.Expect(atomic.LoadInt32(&hungChk.maxInFlight)).To(Equal(int32(1)))
+
+	cancel()
+	g.Eventually(done).Should(BeClosed())
+}
+
+// recordingChecker records a healthy result when each run returns, like the real checkers do.
+type recordingChecker struct {
+	fakeChecker
+}
+
+func (r *recordingChecker) Run(ctx context.Context) {
+	r.fakeChecker.Run(ctx)
+	checker.RecordResult(r, checker.Healthy(), nil)
+}
+
+func TestScheduler_RemoveHungChecker(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	hungCount := func() float64 {
+		return testutil.ToFloat64(metrics.CheckerHungCounter.WithLabelValues("fake", "removed-hung"))
+	}
+	initialHungCount := hungCount()
+
+	hungChk := &recordingChecker{fakeChecker: fakeChecker{name: "removed-hung", delay: 300 * time.Millisecond}}
+	scheduler := NewScheduler([]CheckerSchedule{
+		{Interval: 10 * time.Millisecond, Timeout: 10 * time.Millisecond, Checker: hungChk},
+	})
+	scheduler.hungGracePeriod = 20 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = scheduler.Start(ctx)
+	}()
+
+	g.Eventually(hungCount).Should(Equal(initialHungCount + 1))
+	_, ok := status.DefaultStore.Get("removed-hung")
+	g.Expect(ok).To(BeTrue())
+
+	// Removing the checker does not wait for the hung run, and the result it records when it returns is dropped.
+	g.Expect(scheduler.RemoveChecker("removed-hung")).To(BeTrue())
+	// A checker of the same name cannot be added again before the hung run has returned.
+	readded := CheckerSchedule{Interval: time.Hour, Timeout: time.Second, Checker: &fakeChecker{name: "removed-hung"}}
+	g.Expect(scheduler.AddChecker(readded)).To(MatchError(ContainSubstring("hung run has not returned yet")))
+	g.Consistently(func() bool {
+		_, ok := status.DefaultStore.Get("removed-hung")
+		return ok
+	}, 500*time.Millisecond).Should(BeFalse())
+	g.Expect(metrics.CheckerLastStatusGauge.DeletePartialMatch(prometheus.Labels{"checker_name": "removed-hung"})).To(BeZero())
+
+	// It can be added again once the hung run has returned.
+	g.Eventually(func() error { return scheduler.AddChecker(readded) }, time.Second).Should(Succeed())
+
+	cancel()
+	g.Eventually(done).Should(BeClosed())
+}
+
+func TestCheckerSchedule_NextRun(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2026, 1, 1, 10, 30, 0, 0, time.UTC)
+	hourly, err := cron.ParseStandard("CRON_TZ=UTC 0 * * * *")
+	NewWithT(t).Expect(err).ToNot(HaveOccurred())
+
+	testCases := []struct {
+		name          string
+		chkSch        CheckerSchedule
+		expectedFirst time.Time
+		// expectedNext is the run following the first run when it only returns at firstReturn.
+		firstReturn  time.Time
+		expectedNext time.Time
+	}{
+		{
+			name:          "interval",
+			chkSch:        CheckerSchedule{Interval: time.Minute},
+			expectedFirst: start.Add(time.Minute),
+			firstReturn:   start.Add(time.Minute + time.Second),
+			expectedNext:  start.Add(2 * time.Minute),
+		},
+		{
+			name:          "interval with initial delay",
+			chkSch:        CheckerSchedule{Interval: time.Minute, InitialDelay: 5 * time.Second},
+			expectedFirst: start.Add(5 * time.Second),
+			firstReturn:   start.Add(6 * time.Second),
+			expectedNext:  start.Add(65 * time.Second),
+		},
+		{
+			name:          "interval drops missed runs",
+			chkSch:        CheckerSchedule{Interval: time.Minute},
+			expectedFirst: start.Add(time.Minute),
+			firstReturn:   start.Add(3*time.Minute + time.Second),
+			expectedNext:  start.Add(4 * time.Minute),
+		},
+		{
+			name:          "cron schedule",
+			chkSch:        CheckerSchedule{Interval: time.Minute, CronSchedule: hourly},
+			expectedFirst: time.Date(2026, 1, 1, 11, 0, 0, 0, time.UTC),
+			firstReturn:   time.Date(2026, 1, 1, 11, 0, 30, 0, time.UTC),
+			expectedNext:  time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "cron schedule with initial delay",
+			chkSch:        CheckerSchedule{CronSchedule: hourly, InitialDelay: time.Minute},
+			expectedFirst: start.Add(time.Minute),
+			firstReturn:   start.Add(2 * time.Minute),
+			expectedNext:  time.Date(2026, 1, 1, 11, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			first := tc.chkSch.firstRun(start)
+			g.Expect(first).To(Equal(tc.expectedFirst))
+			g.Expect(tc.chkSch.nextRunAfter(first, tc.firstReturn)).To(Equal(tc.expectedNext))
+		})
+	}
+}
+
+func TestCheckerSchedule_Jitter(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	now := time.Now()
+
+	g.Expect(CheckerSchedule{Interval: time.Minute}.jitter(now)).To(BeZero())
+	chkSch := CheckerSchedule{Interval: time.Minute, JitterPercent: 10}
+	for range 100 {
+		g.Expect(chkSch.jitter(now)).To(And(BeNumerically(">=", 0), BeNumerically("<", 6*time.Second)))
+	}
+}
+
+func TestScheduler_InitialDelay(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	fakeChk := &fakeChecker{name: "delayed"}
+	scheduler := NewScheduler([]CheckerSchedule{
+		{Interval: time.Hour, InitialDelay: 10 * time.Millisecond, Timeout: time.Second, Checker: fakeChk},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = scheduler.Start(ctx)
+	}()
+
+	// The first run happens after the initial delay rather than after the much longer interval.
+	g.Eventually(func() int32 { return atomic.LoadInt32(&fakeChk.runCount) }).Should(Equal(int32(1)))
+	g.Consistently(func() int32 { return atomic.LoadInt32(&fakeChk.runCount) }, 50*time.Millisecond).Should(Equal(int32(1)))
+
+	cancel()
+	g.Eventually(done).Should(BeClosed())
+}
@@ -0,0 +1,163 @@
+package status
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+	chmclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	chmv1alpha1 "github.com/Azure/cluster-health-monitor/apis/chm/v1alpha1"
+)
+
+const (
+	// Reasons of the Healthy condition of the ClusterHealthStatus resource.
+	ReasonAllCheckersHealthy = "AllCheckersHealthy"
+	ReasonCheckersUnhealthy  = "CheckersUnhealthy"
+	ReasonCheckersUnknown    = "CheckersUnknown"
+	ReasonNoCheckers         = "NoCheckers"
+)
+
+// Publisher periodically writes the status of all checkers in a Store to a ClusterHealthStatus resource, so that the health of the
+// cluster can be read through the Kubernetes API.
+type Publisher struct {
+	client   chmclient.Client
+	store    *Store
+	name     string
+	interval time.Duration
+}
+
+// NewPublisher creates a Publisher that writes the status of the checkers in store to the ClusterHealthStatus resource with the given
+// name every interval.
+func NewPublisher(client chmclient.Client, store *Store, name string, interval time.Duration) *Publisher {
+	return &Publisher{
+		client:   client,
+		store:    store,
+		name:     name,
+		interval: interval,
+	}
+}
+
+// Run publishes the status right away and then every interval until the context is cancelled. Failures are logged and retried on the
+// next interval.
+func (p *Publisher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		if err := p.Publish(ctx); err != nil {
+			klog.ErrorS(err, "Failed to publish cluster health status", "name", p.name)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Publish writes the current status of all checkers to the ClusterHealthStatus resource, creating the resource if it does not exist.
+func (p *Publisher) Publish(ctx context.Context) error {
+	chs := &chmv1alpha1.ClusterHealthStatus{}
+	err := p.client.Get(ctx, chmclient.ObjectKey{Name: p.name}, chs)
+	if apierrors.IsNotFound(err) {
+		chs = &chmv1alpha1.ClusterHealthStatus{ObjectMeta: metav1.ObjectMeta{Name: p.name}}
+		if err := p.client.Create(ctx, chs); err != nil {
+			return fmt.Errorf("failed to create ClusterHealthStatus %s: %w", p.name, err)
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to get ClusterHealthStatus %s: %w", p.name, err)
+	}
+
+	statuses := p.store.List()
+	chs.Status.Results = make([]chmv1alpha1.CheckerResult, 0, len(statuses))
+	for _, st := range statuses {
+		chs.Status.Results = append(chs.Status.Results, checkerResult(st))
+	}
+	condition := healthyCondition(chs.Status.Results)
+	condition.ObservedGeneration = chs.Generation
+	meta.SetStatusCondition(&chs.Status.Conditions, condition)
+
+	if err := p.client.Status().Update(ctx, chs); err != nil {
+		return fmt.Errorf("failed to update status of ClusterHealthStatus %s: %w", p.name, err)
+	}
+	klog.V(3).InfoS("Published cluster health status", "name", p.name, "numCheckers", len(statuses), "healthy", condition.Status)
+	return nil
+}
+
+// checkerResult converts the status of a checker to its result in the ClusterHealthStatus resource. The status of the result is the
+// effective status of the checker, while the message and error code come from the last result of the checker itself, so that a result of
+// one of its pods, nameservers or placement groups recorded after it does not leak into the checker's result.
+func checkerResult(st CheckerStatus) chmv1alpha1.CheckerResult {
+	status := st.EffectiveStatus
+	if status == "" && st.LastCheckerResult != nil {
+		status = st.LastCheckerResult.Status
+	}
+	result := chmv1alpha1.CheckerResult{
+		Name:   st.Name,
+		Type:   st.Type,
+		Status: convertStatus(status),
+	}
+	if st.LastCheckerResult != nil {
+		result.Message = st.LastCheckerResult.Message
+		if st.LastCheckerResult.Status != string(chmv1alpha1.CheckStatusHealthy) {
+			result.ErrorCode = st.LastCheckerResult.Code
+		}
+	}
+	if st.LastRunStart != nil {
+		lastRunTime := metav1.NewTime(*st.LastRunStart)
+		result.LastRunTime = &lastRunTime
+	}
+	return result
+}
+
+// convertStatus converts a checker status to a CheckStatus. Statuses other than healthy and unhealthy are unknown.
+func convertStatus(status string) chmv1alpha1.CheckStatus {
+	switch chmv1alpha1.CheckStatus(status) {
+	case chmv1alpha1.CheckStatusHealthy:
+		return chmv1alpha1.CheckStatusHealthy
+	case chmv1alpha1.CheckStatusUnhealthy:
+		return chmv1alpha1.CheckStatusUnhealthy
+	default:
+		return chmv1alpha1.CheckStatusUnknown
+	}
+}
+
+// healthyCondition aggregates the checker results into the Healthy condition. The cluster is unhealthy if any checker is unhealthy, and
+// its health is unknown if any checker is unknown or there are no checkers.
+func healthyCondition(results []chmv1alpha1.CheckerResult) metav1.Condition {
+	var unhealthy, unknown []string
+	for _, result := range results {
+		switch result.Status {
+		case chmv1alpha1.CheckStatusUnhealthy:
+			unhealthy = append(unhealthy, result.Name)
+		case chmv1alpha1.CheckStatusUnknown:
+			unknown = append(unknown, result.Name)
+		}
+	}
+
+	condition := metav1.Condition{Type: string(chmv1alpha1.ClusterHealthConditionHealthy)}
+	switch {
+	case len(unhealthy) > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonCheckersUnhealthy
+		condition.Message = "Unhealthy checkers: " + strings.Join(unhealthy, ", ")
+	case len(unknown) > 0:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = ReasonCheckersUnknown
+		condition.Message = "Checkers with unknown status: " + strings.Join(unknown, ", ")
+	case len(results) == 0:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = ReasonNoCheckers
+		condition.Message = "No checkers have reported a result"
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = ReasonAllCheckersHealthy
+		condition.Message = "All checkers are healthy"
+	}
+	return condition
+}
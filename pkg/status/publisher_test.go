@@ -0,0 +1,193 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+	"k8s.io/kube-openapi/pkg/validation/strfmt"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+	chmclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	chmv1alpha1 "github.com/Azure/cluster-health-monitor/apis/chm/v1alpha1"
+)
+
+func newFakeClient(g *WithT) chmclient.Client {
+	scheme := runtime.NewScheme()
+	g.Expect(chmv1alpha1.AddToScheme(scheme)).To(Succeed())
+	return fake.NewClientBuilder().
+		WithScheme(scheme).
+		WithStatusSubresource(&chmv1alpha1.ClusterHealthStatus{}).
+		Build()
+}
+
+func TestPublisher_Publish(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	ctx := context.Background()
+	client := newFakeClient(g)
+	store := NewStore(3)
+	publisher := NewPublisher(client, store, "cluster", time.Minute)
+
+	getHealthy := func() (*chmv1alpha1.ClusterHealthStatus, *metav1.Condition) {
+		chs := &chmv1alpha1.ClusterHealthStatus{}
+		g.Expect(client.Get(ctx, chmclient.ObjectKey{Name: "cluster"}, chs)).To(Succeed())
+		return chs, meta.FindStatusCondition(chs.Status.Conditions, string(chmv1alpha1.ClusterHealthConditionHealthy))
+	}
+
+	// The resource is created on the first publish, with an unknown status until checkers report.
+	g.Expect(publisher.Publish(ctx)).To(Succeed())
+	chs, condition := getHealthy()
+	g.Expect(chs.Status.Results).To(BeEmpty())
+	g.Expect(condition).ToNot(BeNil())
+	g.Expect(condition.Status).To(Equal(metav1.ConditionUnknown))
+	g.Expect(condition.Reason).To(Equal(ReasonNoCheckers))
+
+	start := time.Now().Truncate(time.Second)
+	store.RecordRunStart("DNS", "DNS", start)
+	store.RecordResult("DNS", "DNS", Result{Status: "Healthy", Code: "Healthy"})
+	store.SetEffectiveStatus("DNS", "DNS", "Healthy")
+	store.RecordResult("APIServer", "APIServer", Result{Status: "Healthy", Code: "Healthy"})
+	store.SetEffectiveStatus("APIServer", "APIServer", "Healthy")
+	g.Expect(publisher.Publish(ctx)).To(Succeed())
+	chs, condition = getHealthy()
+	g.Expect(chs.Status.Results).To(HaveLen(2))
+	g.Expect(chs.Status.Results[0].Name).To(Equal("APIServer"))
+	g.Expect(chs.Status.Results[0].LastRunTime).To(BeNil())
+	g.Expect(chs.Status.Results[1].Name).To(Equal("DNS"))
+	g.Expect(chs.Status.Results[1].ErrorCode).To(BeEmpty())
+	g.Expect(chs.Status.Results[1].LastRunTime.Time).To(BeTemporally("==", start))
+	g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+	g.Expect(condition.Reason).To(Equal(ReasonAllCheckersHealthy))
+
+	// Removed checkers are dropped and an unhealthy checker makes the cluster unhealthy.
+	store.Delete("APIServer")
+	store.RecordResult("DNS", "DNS", Result{Status: "Unhealthy", Code: "ServiceTimeout", Message: "timed out"})
+	store.SetEffectiveStatus("DNS", "DNS", "Unhealthy")
+	g.Expect(publisher.Publish(ctx)).To(Succeed())
+	chs, condition = getHealthy()
+	g.Expect(chs.Status.Results).To(ConsistOf(HaveField("Name", "DNS")))
+	g.Expect(chs.Status.Results[0].Status).To(Equal(chmv1alpha1.CheckStatusUnhealthy))
+	g.Expect(chs.Status.Results[0].ErrorCode).To(Equal("ServiceTimeout"))
+	g.Expect(chs.Status.Results[0].Message).To(Equal("timed out"))
+	g.Expect(chs.Status.Results[0].Type).To(Equal("DNS"))
+	g.Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+	g.Expect(condition.Reason).To(Equal(ReasonCheckersUnhealthy))
+	g.Expect(condition.Message).To(ContainSubstring("DNS"))
+
+	// A result of a nameserver recorded after the result of the checker itself does not leak into the checker's result.
+	store.RecordResult("DNS", "DNS", Result{Status: "Healthy", Code: "Healthy", Message: "all nameservers answered"})
+	store.SetEffectiveStatus("DNS", "DNS", "Healthy")
+	store.RecordResult("DNS", "DNS", Result{Status: "Unhealthy", Code: "NameserverTimeout", Message: "query timed out", Nameserver: "10.0.0.10:53"})
+	g.Expect(publisher.Publish(ctx)).To(Succeed())
+	chs, condition = getHealthy()
+	g.Expect(chs.Status.Results).To(HaveLen(1))
+	g.Expect(chs.Status.Results[0].Status).To(Equal(chmv1alpha1.CheckStatusHealthy))
+	g.Expect(chs.Status.Results[0].ErrorCode).To(BeEmpty())
+	g.Expect(chs.Status.Results[0].Message).To(Equal("all nameservers answered"))
+	g.Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+}
+
+// crdSchema returns the OpenAPI schema of the ClusterHealthStatus CRD of the manifests.
+func crdSchema(g *WithT) *spec.Schema {
+	data, err := os.ReadFile("../../manifests/base/cluster-health-monitor/crd.yaml")
+	g.Expect(err).ToNot(HaveOccurred())
+	var crd struct {
+		Spec struct {
+			Versions []struct {
+				Schema struct {
+					OpenAPIV3Schema map[string]any `yaml:"openAPIV3Schema"`
+				} `yaml:"schema"`
+			} `yaml:"versions"`
+		} `yaml:"spec"`
+	}
+	g.Expect(yaml.Unmarshal(data, &crd)).To(Succeed())
+	g.Expect(crd.Spec.Versions).To(HaveLen(1))
+	schemaJSON, err := json.Marshal(crd.Spec.Versions[0].Schema.OpenAPIV3Schema)
+	g.Expect(err).ToNot(HaveOccurred())
+	schema := &spec.Schema{}
+	g.Expect(json.Unmarshal(schemaJSON, schema)).To(Succeed())
+	return schema
+}
+
+func TestPublisher_Publish_CRDSchema(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	ctx := context.Background()
+	client := newFakeClient(g)
+	store := NewStore(3)
+	publisher := NewPublisher(client, store, "cluster", time.Minute)
+
+	// Checker names are not restricted by the configuration, so lowercase and hyphenated names must be accepted by the CRD.
+	store.RecordRunStart("dns-internal", "DNS", time.Now())
+	store.RecordResult("dns-internal", "DNS", Result{Status: "Unhealthy", Code: "ServiceTimeout", Message: "timed out"})
+	store.SetEffectiveStatus("dns-internal", "DNS", "Unhealthy")
+	store.RecordResult("PodStartup", "PodStartup", Result{Status: "Healthy", Code: "Healthy"})
+	store.SetEffectiveStatus("PodStartup", "PodStartup", "Healthy")
+	g.Expect(publisher.Publish(ctx)).To(Succeed())
+
+	chs := &chmv1alpha1.ClusterHealthStatus{}
+	g.Expect(client.Get(ctx, chmclient.ObjectKey{Name: "cluster"}, chs)).To(Succeed())
+	g.Expect(chs.Status.Results).To(ConsistOf(HaveField("Name", "dns-internal"), HaveField("Name", "PodStartup")))
+	object, err := runtime.DefaultUnstructuredConverter.ToUnstructured(chs)
+	g.Expect(err).ToNot(HaveOccurred())
+	result := validate.NewSchemaValidator(crdSchema(g), nil, "", strfmt.Default).Validate(object)
+	g.Expect(result.AsError()).ToNot(HaveOccurred())
+}
+
+func TestCheckerResult(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name           string
+		status         CheckerStatus
+		expectedStatus chmv1alpha1.CheckStatus
+		expectedCode   string
+	}{
+		{
+			name:           "No result yet",
+			status:         CheckerStatus{Name: "DNS"},
+			expectedStatus: chmv1alpha1.CheckStatusUnknown,
+		},
+		{
+			name: "Effective status takes precedence over the last result",
+			status: CheckerStatus{
+				Name:              "DNS",
+				EffectiveStatus:   "Healthy",
+				LastCheckerResult: &Result{Status: "Unhealthy", Code: "ServiceTimeout"},
+			},
+			expectedStatus: chmv1alpha1.CheckStatusHealthy,
+			expectedCode:   "ServiceTimeout",
+		},
+		{
+			name:           "Last result without effective status",
+			status:         CheckerStatus{Name: "DNS", LastCheckerResult: &Result{Status: "Unknown", Code: "CheckerHung"}},
+			expectedStatus: chmv1alpha1.CheckStatusUnknown,
+			expectedCode:   "CheckerHung",
+		},
+		{
+			name:           "Skipped result",
+			status:         CheckerStatus{Name: "DNS", LastCheckerResult: &Result{Status: "Skipped"}},
+			expectedStatus: chmv1alpha1.CheckStatusUnknown,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			g := NewWithT(t)
+			result := checkerResult(tc.status)
+			g.Expect(result.Status).To(Equal(tc.expectedStatus))
+			g.Expect(result.ErrorCode).To(Equal(tc.expectedCode))
+		})
+	}
+}
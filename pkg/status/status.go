@@ -0,0 +1,184 @@
+// Package status keeps the latest results of each checker in memory and serves them as JSON.
+package status
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+)
+
+// defaultHistorySize is the number of recent results kept for each checker.
+const defaultHistorySize = 10
+
+// DefaultStore is the store the checkers and the scheduler record into and the metrics server serves from.
+var DefaultStore = NewStore(defaultHistorySize)
+
+// Result is a single recorded checker result.
+type Result struct {
+	// Status is the status of the result, e.g. Healthy, Unhealthy or Unknown.
+	Status string `json:"status"`
+	// Code is the error code of the result if it is not healthy.
+	Code string `json:"code,omitempty"`
+	// Message is a human-readable message about the result.
+	Message string `json:"message,omitempty"`
+	// Pod is the name of the pod associated with the result, if applicable.
+	Pod string `json:"pod,omitempty"`
+	// Nameserver is the address of the DNS nameserver associated with the result, if applicable.
+	Nameserver string `json:"nameserver,omitempty"`
+	// Group is the placement group associated with the result, if applicable, e.g. the node pool or zone of a synthetic pod.
+	Group string `json:"group,omitempty"`
+	// Time is when the result was recorded.
+	Time time.Time `json:"time"`
+}
+
+// CheckerStatus is the latest known state of a checker.
+type CheckerStatus struct {
+	// Name is the name of the checker.
+	Name string `json:"name"`
+	// Type is the type of the checker.
+	Type string `json:"type"`
+	// EffectiveStatus is the status of the checker after applying its failure and success thresholds.
+	EffectiveStatus string `json:"effectiveStatus,omitempty"`
+	// LastResult is the most recently recorded result.
+	LastResult *Result `json:"lastResult,omitempty"`
+	// LastCheckerResult is the most recently recorded result of the checker itself, as opposed to a result of one of its pods,
+	// nameservers or placement groups.
+	LastCheckerResult *Result `json:"lastCheckerResult,omitempty"`
+	// LastRunStart is when the most recent run started.
+	LastRunStart *time.Time `json:"lastRunStart,omitempty"`
+	// LastRunEnd is when the most recent completed run ended.
+	LastRunEnd *time.Time `json:"lastRunEnd,omitempty"`
+	// RecentResults holds the most recent results, oldest first.
+	RecentResults []Result `json:"recentResults"`
+}
+
+// Store holds the status of every checker. It is safe for concurrent use.
+type Store struct {
+	mu          sync.RWMutex
+	historySize int
+	checkers    map[string]*CheckerStatus
+}
+
+// NewStore creates a Store that keeps historySize recent results per checker.
+func NewStore(historySize int) *Store {
+	return &Store{
+		historySize: historySize,
+		checkers:    make(map[string]*CheckerStatus),
+	}
+}
+
+// RecordRunStart records that a run of the checker started at t.
+func (s *Store) RecordRunStart(name, checkerType string, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entryLocked(name, checkerType).LastRunStart = &t
+}
+
+// RecordRunEnd records that a run of the checker ended at t.
+func (s *Store) RecordRunEnd(name, checkerType string, t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entryLocked(name, checkerType).LastRunEnd = &t
+}
+
+// RecordResult records a result of the checker. Only the most recent results are kept.
+func (s *Store) RecordResult(name, checkerType string, result Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry := s.entryLocked(name, checkerType)
+	entry.LastResult = &result
+	if result.Pod == "" && result.Nameserver == "" && result.Group == "" {
+		checkerResult := result
+		entry.LastCheckerResult = &checkerResult
+	}
+	entry.RecentResults = append(entry.RecentResults, result)
+	if len(entry.RecentResults) > s.historySize {
+		entry.RecentResults = entry.RecentResults[len(entry.RecentResults)-s.historySize:]
+	}
+}
+
+// SetEffectiveStatus records the effective status of the checker.
+func (s *Store) SetEffectiveStatus(name, checkerType, effectiveStatus string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entryLocked(name, checkerType).EffectiveStatus = effectiveStatus
+}
+
+// Delete removes the status of the checker, e.g. after it was removed from the configuration.
+func (s *Store) Delete(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.checkers, name)
+}
+
+// Get returns a copy of the status of the checker and whether it is known.
+func (s *Store) Get(name string) (CheckerStatus, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	entry, ok := s.checkers[name]
+	if !ok {
+		return CheckerStatus{}, false
+	}
+	return copyStatus(entry), true
+}
+
+// List returns a copy of the status of all checkers, sorted by name.
+func (s *Store) List() []CheckerStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	statuses := make([]CheckerStatus, 0, len(s.checkers))
+	for _, entry := range s.checkers {
+		statuses = append(statuses, copyStatus(entry))
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// ServeList is an HTTP handler that writes the status of all checkers as JSON.
+func (s *Store) ServeList(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, s.List())
+}
+
+// ServeChecker is an HTTP handler that writes the status of the checker named by the "name" path value as JSON.
+func (s *Store) ServeChecker(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	st, ok := s.Get(name)
+	if !ok {
+		http.Error(w, "checker not found", http.StatusNotFound)
+		return
+	}
+	writeJSON(w, st)
+}
+
+// entryLocked returns the status entry of the checker, creating it if needed. The caller must hold s.mu.
+func (s *Store) entryLocked(name, checkerType string) *CheckerStatus {
+	entry, ok := s.checkers[name]
+	if !ok {
+		entry = &CheckerStatus{
+			Name:          name,
+			RecentResults: []Result{},
+		}
+		s.checkers[name] = entry
+	}
+	entry.Type = checkerType
+	return entry
+}
+
+func copyStatus(entry *CheckerStatus) CheckerStatus {
+	st := *entry
+	st.RecentResults = append([]Result{}, entry.RecentResults...)
+	return st
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		klog.ErrorS(err, "Failed to write status response")
+	}
+}
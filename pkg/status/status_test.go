@@ -0,0 +1,124 @@
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestStore_RecordResult(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	store := NewStore(3)
+
+	for i := range 5 {
+		store.RecordResult("chk", "fake", Result{Status: "Healthy", Message: fmt.Sprintf("run %d", i)})
+	}
+
+	st, ok := store.Get("chk")
+	g.Expect(ok).To(BeTrue())
+	g.Expect(st.Type).To(Equal("fake"))
+	g.Expect(st.LastResult).ToNot(BeNil())
+	g.Expect(st.LastResult.Message).To(Equal("run 4"))
+	g.Expect(st.RecentResults).To(HaveLen(3))
+	g.Expect(st.RecentResults[0].Message).To(Equal("run 2"))
+	g.Expect(st.RecentResults[2].Message).To(Equal("run 4"))
+
+	// The returned status is a copy and is not affected by later results.
+	store.RecordResult("chk", "fake", Result{Status: "Unhealthy", Message: "run 5"})
+	g.Expect(st.RecentResults[2].Message).To(Equal("run 4"))
+
+	// A result of a pod is the last result, but not the last result of the checker itself.
+	store.RecordResult("chk", "fake", Result{Status: "Unhealthy", Message: "run 6", Pod: "pod-1"})
+	st, _ = store.Get("chk")
+	g.Expect(st.LastResult.Message).To(Equal("run 6"))
+	g.Expect(st.LastCheckerResult).ToNot(BeNil())
+	g.Expect(st.LastCheckerResult.Message).To(Equal("run 5"))
+}
+
+func TestStore_RunTimesAndDelete(t *testing.T) {
+	t.Parallel()
+	g := NewWithT(t)
+	store := NewStore(3)
+	start := time.Now()
+	end := start.Add(time.Second)
+
+	store.RecordRunStart("b", "fake", start)
+	store.RecordRunEnd("b", "fake", end)
+	store.RecordRunStart("a", "fake", start)
+
+	statuses := store.List()
+	g.Expect(statuses).To(HaveLen(2))
+	g.Expect(statuses[0].Name).To(Equal("a"))
+	g.Expect(statuses[0].LastRunEnd).To(BeNil())
+	g.Expect(statuses[1].Name).To(Equal("b"))
+	g.Expect(statuses[1].LastRunStart).To(HaveValue(BeTemporally("==", start)))
+	g.Expect(statuses[1].LastRunEnd).To(HaveValue(BeTemporally("==", end)))
+
+	store.Delete("b")
+	_, ok := store.Get("b")
+	g.Expect(ok).To(BeFalse())
+	g.Expect(store.List()).To(HaveLen(1))
+}
+
+func TestStore_ServeHTTP(t *testing.T) {
+	t.Parallel()
+	store := NewStore(3)
+	store.RecordResult("chk", "fake", Result{Status: "Unhealthy", Code: "some_error"})
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /status", store.ServeList)
+	mux.HandleFunc("GET /status/{name}", store.ServeChecker)
+
+	testCases := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		validateBody   func(g *WithT, body []byte)
+	}{
+		{
+			name:           "List all checkers",
+			path:           "/status",
+			expectedStatus: http.StatusOK,
+			validateBody: func(g *WithT, body []byte) {
+				var statuses []CheckerStatus
+				g.Expect(json.Unmarshal(body, &statuses)).To(Succeed())
+				g.Expect(statuses).To(HaveLen(1))
+				g.Expect(statuses[0].Name).To(Equal("chk"))
+			},
+		},
+		{
+			name:           "Get known checker",
+			path:           "/status/chk",
+			expectedStatus: http.StatusOK,
+			validateBody: func(g *WithT, body []byte) {
+				var st CheckerStatus
+				g.Expect(json.Unmarshal(body, &st)).To(Succeed())
+				g.Expect(st.LastResult).ToNot(BeNil())
+				g.Expect(st.LastResult.Status).To(Equal("Unhealthy"))
+				g.Expect(st.LastResult.Code).To(Equal("some_error"))
+			},
+		},
+		{
+			name:           "Get unknown checker",
+			path:           "/status/missing",
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+			g.Expect(rec.Code).To(Equal(tc.expectedStatus))
+			if tc.validateBody != nil {
+				tc.validateBody(g, rec.Body.Bytes())
+			}
+		})
+	}
+}